@@ -4,8 +4,13 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/audit"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/config"
 	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/mcp"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/snapshot"
 )
 
 const (
@@ -14,9 +19,26 @@ const (
 
 func main() {
 	var (
-		apiToken = flag.String("token", "", "Obsidian API token (can also be set via OBSIDIAN_API_TOKEN env var)")
-		baseURL  = flag.String("url", defaultBaseURL, "Obsidian server base URL")
-		version  = flag.Bool("version", false, "Show version information")
+		apiToken     = flag.String("token", "", "Obsidian API token (can also be set via OBSIDIAN_API_TOKEN env var)")
+		baseURL      = flag.String("url", defaultBaseURL, "Obsidian server base URL")
+		version      = flag.Bool("version", false, "Show version information")
+		pollInterval = flag.Duration("poll-interval", 5*time.Second, "How often subscribed resources are checked for changes")
+		promptsDir   = flag.String("prompts-folder", "_mcp/prompts", "Vault folder containing user-defined prompts (empty to disable)")
+		transport    = flag.String("transport", "stdio", "MCP transport: 'stdio' or 'http'")
+		listenAddr   = flag.String("listen", "127.0.0.1:8080", "Address to listen on when using the http transport")
+		httpToken    = flag.String("http-token", "", "Bearer token HTTP clients must send (can also be set via MCP_HTTP_TOKEN env var)")
+		allowedHosts = flag.String("allowed-hosts", "", "Comma-separated host names HTTP clients may connect to besides loopback names and IP addresses")
+		idleTimeout  = flag.Duration("session-idle-timeout", 30*time.Minute, "How long an unused HTTP session is kept (0 to keep sessions until deleted)")
+		concurrency  = flag.Int("max-concurrency", 8, "Maximum number of requests handled in parallel")
+		indexCache   = flag.String("index-cache", "", "File the vault index is cached in between runs (empty to keep it in memory)")
+		configFile   = flag.String("config", "", "JSON configuration file with tool and path access rules")
+		readOnly     = flag.Bool("read-only", false, "Disable every tool that changes the vault or runs commands")
+		confirm      = flag.Bool("require-confirm", false, "Require a confirm argument on destructive tools")
+		dryRun       = flag.Bool("dry-run", false, "Preview every change as a diff instead of writing it")
+		auditLog     = flag.String("audit-log", "", "JSON-lines file every tool call is recorded in (overrides the config file)")
+		snapshotDir  = flag.String("snapshot-dir", "", "Folder files are snapshotted to before they are changed (empty to disable)")
+		maxSnapshots = flag.Int("snapshot-max-per-file", snapshot.DefaultMaxPerFile, "Number of snapshots kept for each file (0 for no limit)")
+		snapshotAge  = flag.Duration("snapshot-max-age", snapshot.DefaultMaxAge, "How long snapshots are kept (0 for no limit)")
 	)
 	flag.Parse()
 
@@ -36,15 +58,94 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *httpToken == "" {
+		*httpToken = os.Getenv("MCP_HTTP_TOKEN")
+	}
+	var hosts []string
+	for _, host := range strings.Split(*allowedHosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	cfg := &config.Config{}
+	if *configFile != "" {
+		var err error
+		if cfg, err = config.Load(*configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *auditLog == "" {
+		*auditLog = cfg.AuditLog
+	}
+	var auditLogger *audit.Logger
+	if *auditLog != "" {
+		var err error
+		if auditLogger, err = audit.Open(*auditLog); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer auditLogger.Close()
+	}
+	// exit closes the audit log first, as os.Exit skips deferred calls
+	exit := func(code int) {
+		if auditLogger != nil {
+			_ = auditLogger.Close()
+		}
+		os.Exit(code)
+	}
+
+	var snapshots *snapshot.Store
+	if *snapshotDir != "" {
+		var err error
+		retention := snapshot.Retention{MaxPerFile: *maxSnapshots, MaxAge: *snapshotAge}
+		if snapshots, err = snapshot.Open(*snapshotDir, retention); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+	}
+
 	// Create and start the MCP server
-	server := mcp.NewMCPServer(*apiToken, *baseURL)
+	server := mcp.NewMCPServer(*apiToken, *baseURL,
+		mcp.WithPollInterval(*pollInterval),
+		mcp.WithPromptsFolder(*promptsDir),
+		mcp.WithMaxConcurrency(*concurrency),
+		mcp.WithIndexCache(*indexCache),
+		mcp.WithToolPolicy(mcp.ToolPolicy{
+			ReadOnly:       cfg.ReadOnly || *readOnly,
+			RequireConfirm: cfg.RequireConfirm || *confirm,
+			Allow:          cfg.Tools.Allow,
+			Deny:           cfg.Tools.Deny,
+			DryRun:         *dryRun,
+		}),
+		mcp.WithPathRules(cfg.PathRules()),
+		mcp.WithAuditLog(auditLogger),
+		mcp.WithSnapshots(snapshots),
+		mcp.WithHTTPAuthToken(*httpToken),
+		mcp.WithAllowedHosts(hosts...),
+		mcp.WithSessionIdleTimeout(*idleTimeout),
+	)
 
 	fmt.Fprintf(os.Stderr, "Starting Obsidian MCP Server...\n")
 	fmt.Fprintf(os.Stderr, "Base URL: %s\n", *baseURL)
-	fmt.Fprintf(os.Stderr, "Listening on stdin/stdout for MCP requests\n")
 
-	if err := server.Run(); err != nil {
+	var err error
+	switch *transport {
+	case "stdio":
+		fmt.Fprintf(os.Stderr, "Listening on stdin/stdout for MCP requests\n")
+		err = server.Run()
+	case "http":
+		fmt.Fprintf(os.Stderr, "Listening on http://%s/mcp for MCP requests\n", *listenAddr)
+		err = server.RunHTTP(*listenAddr)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported transport %q, expected 'stdio' or 'http'\n", *transport)
+		exit(1)
+	}
+
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
-		os.Exit(1)
+		exit(1)
 	}
 }
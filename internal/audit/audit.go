@@ -0,0 +1,130 @@
+// Package audit writes a JSON-lines log of the tool calls made by clients
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outcomes of a tool call
+const (
+	OutcomeSuccess   = "success"
+	OutcomeError     = "error"
+	OutcomeCancelled = "cancelled"
+)
+
+// redactedArguments lists arguments whose values are replaced by their size
+// because they carry note content or text searched for in notes
+var redactedArguments = map[string]bool{
+	"content":     true,
+	"data":        true,
+	"text":        true,
+	"query":       true,
+	"pattern":     true,
+	"replacement": true,
+	"value":       true,
+}
+
+// secretArguments lists substrings of argument names whose values are never
+// logged
+var secretArguments = []string{"token", "password", "secret", "apikey"}
+
+// Entry records a single tool call
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Session is the ID of the HTTP session the call came from
+	Session string `json:"session,omitempty"`
+	// Client is the clientInfo sent by the client in initialize
+	Client    map[string]any `json:"client,omitempty"`
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+	// Path is the vault file the call targeted, if any
+	Path       string `json:"path,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+	Outcome    string `json:"outcome"`
+	Error      string `json:"error,omitempty"`
+	// HashBefore and HashAfter are the content hashes of the target of a
+	// mutating tool before and after the call, in the form "sha256:<hex>"
+	// also accepted as expectedHash. They are empty when the file does not
+	// exist.
+	HashBefore string `json:"hashBefore,omitempty"`
+	HashAfter  string `json:"hashAfter,omitempty"`
+	// Files holds the hashes of every file rewritten by a tool changing
+	// several files, such as replace_in_vault or rename_tag
+	Files []FileHashes `json:"files,omitempty"`
+}
+
+// FileHashes holds the content hashes of one file before and after a call
+type FileHashes struct {
+	Path       string `json:"path"`
+	HashBefore string `json:"hashBefore"`
+	HashAfter  string `json:"hashAfter"`
+}
+
+// Logger appends entries to an audit log file. It is safe for concurrent use.
+type Logger struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open opens an audit log for appending, creating it and its folder if needed
+func Open(filename string) (*Logger, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log folder: %w", err)
+	}
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &Logger{file: file}, nil
+}
+
+// Log appends an entry as a single line
+func (l *Logger) Log(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+// Close closes the log file
+func (l *Logger) Close() error {
+	return l.file.Close()
+}
+
+// Redact returns a copy of tool arguments safe to log: note content is
+// replaced by its size and secrets are removed
+func Redact(args map[string]any) map[string]any {
+	redacted := make(map[string]any, len(args))
+	for name, value := range args {
+		lower := strings.ToLower(name)
+		switch {
+		case slices.ContainsFunc(secretArguments, func(secret string) bool { return strings.Contains(lower, secret) }):
+			redacted[name] = "[redacted]"
+		case redactedArguments[name]:
+			if s, ok := value.(string); ok {
+				redacted[name] = fmt.Sprintf("[redacted %d bytes]", len(s))
+			} else if data, err := json.Marshal(value); err == nil {
+				redacted[name] = fmt.Sprintf("[redacted %d bytes of JSON]", len(data))
+			} else {
+				redacted[name] = "[redacted]"
+			}
+		default:
+			redacted[name] = value
+		}
+	}
+	return redacted
+}
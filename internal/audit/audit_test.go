@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoggerLog tests that entries are appended as JSON lines
+func TestLoggerLog(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	logger, err := Open(filename)
+	require.NoError(t, err)
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, logger.Log(Entry{Time: at, Tool: "delete_file", Arguments: map[string]any{"filename": "a.md"}, Path: "a.md", Outcome: OutcomeSuccess, HashBefore: "sha256:ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"}))
+	require.NoError(t, logger.Log(Entry{Time: at, Tool: "get_file_content", Arguments: map[string]any{}, Outcome: OutcomeError, Error: "boom"}))
+	require.NoError(t, logger.Close())
+
+	// Reopening appends to the existing log
+	logger, err = Open(filename)
+	require.NoError(t, err)
+	require.NoError(t, logger.Log(Entry{Time: at, Tool: "list_tags", Arguments: map[string]any{}, Outcome: OutcomeSuccess}))
+	require.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	assert.JSONEq(t, `{
+		"time": "2024-05-01T12:00:00Z",
+		"tool": "delete_file",
+		"arguments": {"filename": "a.md"},
+		"path": "a.md",
+		"durationMs": 0,
+		"outcome": "success",
+		"hashBefore": "sha256:ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"
+	}`, lines[0])
+
+	var entry Entry
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "boom", entry.Error)
+}
+
+// TestRedact tests that note content, search text and secrets are not logged
+func TestRedact(t *testing.T) {
+	args := map[string]any{
+		"filename":    "a.md",
+		"content":     "private thoughts",
+		"data":        "aGVsbG8=",
+		"text":        "Call the doctor",
+		"query":       "diagnosis",
+		"pattern":     "Alice",
+		"replacement": "Bob",
+		"value":       []any{"secret", "plans"},
+		"apiToken":    "abc",
+		"dryRun":      true,
+	}
+	assert.Equal(t, map[string]any{
+		"filename":    "a.md",
+		"content":     "[redacted 16 bytes]",
+		"data":        "[redacted 8 bytes]",
+		"text":        "[redacted 15 bytes]",
+		"query":       "[redacted 9 bytes]",
+		"pattern":     "[redacted 5 bytes]",
+		"replacement": "[redacted 3 bytes]",
+		"value":       "[redacted 18 bytes of JSON]",
+		"apiToken":    "[redacted]",
+		"dryRun":      true,
+	}, Redact(args))
+	assert.Equal(t, "private thoughts", args["content"])
+}
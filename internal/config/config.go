@@ -0,0 +1,79 @@
+// Package config loads the optional JSON configuration file of the server
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+)
+
+// Config holds the settings read from the configuration file. Command-line
+// flags can only tighten them.
+type Config struct {
+	// ReadOnly disables every tool that changes the vault or runs commands
+	ReadOnly bool `json:"readOnly"`
+	// RequireConfirm makes destructive tools fail unless called with
+	// "confirm": true
+	RequireConfirm bool  `json:"requireConfirm"`
+	Tools          Tools `json:"tools"`
+	Paths          Paths `json:"paths"`
+	// AuditLog is the JSON-lines file tool calls are recorded in
+	AuditLog string `json:"auditLog"`
+}
+
+// Tools selects the tools offered to clients. Entries are tool names or
+// path.Match patterns such as "delete_*".
+type Tools struct {
+	// Allow lists the only tools offered when it is not empty
+	Allow []string `json:"allow"`
+	// Deny lists tools that are never offered, even when allowed
+	Deny []string `json:"deny"`
+}
+
+// Paths selects the vault paths the server may access. Entries are globs
+// relative to the vault root, such as "Private/" or "**/*.secret.md".
+type Paths struct {
+	// Include lists the only paths accessible when it is not empty
+	Include []string `json:"include"`
+	// Exclude lists paths that are never accessible, even when included
+	Exclude []string `json:"exclude"`
+}
+
+// PathRules returns the path restrictions to apply to the Obsidian client
+func (c *Config) PathRules() obsidian.PathRules {
+	return obsidian.PathRules{Include: c.Paths.Include, Exclude: c.Paths.Exclude}
+}
+
+// Load reads a configuration file. Unknown fields are rejected so that typos
+// do not silently leave a guard disabled.
+func Load(filename string) (*Config, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config: %w", err)
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	var cfg Config
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", filename, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", filename, err)
+	}
+	return &cfg, nil
+}
+
+// validate checks that every tool and path pattern is well formed
+func (c *Config) validate() error {
+	for _, pattern := range append(append([]string{}, c.Tools.Allow...), c.Tools.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad tool pattern %q: %w", pattern, err)
+		}
+	}
+	return c.PathRules().Validate()
+}
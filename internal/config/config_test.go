@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfig writes a configuration file to a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+	return filename
+}
+
+// TestLoad tests reading a configuration file
+func TestLoad(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `{"readOnly": true, "tools": {"allow": ["get_*"], "deny": ["get_active_file"]}}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Config{ReadOnly: true, Tools: Tools{Allow: []string{"get_*"}, Deny: []string{"get_active_file"}}}, cfg)
+}
+
+// TestLoadInvalid tests that mistakes in the file are reported
+func TestLoadInvalid(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to open config")
+
+	_, err = Load(writeConfig(t, `{"tool": {"deny": ["delete_file"]}}`))
+	assert.ErrorContains(t, err, `unknown field "tool"`)
+
+	_, err = Load(writeConfig(t, `{"tools": {"deny": ["delete_["]}}`))
+	assert.ErrorContains(t, err, `bad tool pattern "delete_["`)
+}
+
+// TestLoadPaths tests reading path rules
+func TestLoadPaths(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `{"paths": {"exclude": ["Private/", "HR/"]}}`))
+	require.NoError(t, err)
+	assert.False(t, cfg.PathRules().Allowed("HR/review.md"))
+
+	_, err = Load(writeConfig(t, `{"paths": {"include": ["Notes/["]}}`))
+	assert.ErrorContains(t, err, `bad path pattern "Notes/["`)
+}
@@ -0,0 +1,228 @@
+// Package diff produces unified diffs of note content
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+// op is a line of an edit script: ' ' keeps, '-' deletes and '+' inserts it
+type op struct {
+	kind byte
+	line string
+}
+
+// Unified returns the unified diff turning old into new, or an empty string
+// when they are equal
+func Unified(oldName, newName, old, new string) string {
+	if old == new {
+		return ""
+	}
+	ops := diffLines(splitLines(old), splitLines(new))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(ops) {
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(h.oldStart, h.oldCount), hunkRange(h.newStart, h.newCount))
+		for _, o := range ops[h.start:h.end] {
+			b.WriteByte(o.kind)
+			b.WriteString(o.line)
+			if !strings.HasSuffix(o.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// splitLines splits text into lines that keep their line endings
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// maxEdits is the length of edit script diffLines searches for. Beyond it
+// the differing lines are shown as replaced wholesale, keeping the memory
+// of the search bounded by maxEdits².
+const maxEdits = 1000
+
+// diffLines computes a shortest edit script with the Myers algorithm, after
+// setting aside the lines both texts start and end with
+func diffLines(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []op
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{' ', line})
+	}
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if script, ok := myers(middleA, middleB); ok {
+		ops = append(ops, script...)
+	} else {
+		for _, line := range middleA {
+			ops = append(ops, op{'-', line})
+		}
+		for _, line := range middleB {
+			ops = append(ops, op{'+', line})
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{' ', line})
+	}
+	return ops
+}
+
+// myers computes a shortest edit script, or reports false when it is longer
+// than maxEdits
+func myers(a, b []string) ([]op, bool) {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	// trace[d] holds v[-d..d] as it was before step d, the only diagonals
+	// the backtrack reads for that step
+	var trace [][]int
+
+	// Find the length of the shortest edit script, remembering the furthest
+	// reaching paths of every step for the backtrack
+	found := n == 0 && m == 0
+	for d := 0; d <= n+m && !found; d++ {
+		if d > maxEdits {
+			return nil, false
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	// Walk the trace backwards, emitting the script in reverse
+	var ops []op
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[d+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, op{' ', a[x]})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, op{'+', b[y]})
+		} else {
+			x--
+			ops = append(ops, op{'-', a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, op{' ', a[x]})
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops, true
+}
+
+// hunk is a range of the edit script shown together
+type hunk struct {
+	start, end         int
+	oldStart, oldCount int
+	newStart, newCount int
+}
+
+// hunks groups the changes of an edit script with their context. Changes
+// separated by less than twice the context share a hunk.
+func hunks(ops []op) []hunk {
+	var result []hunk
+	oldLine, newLine := 0, 0
+	var current *hunk
+	lastChange := 0
+	for i, o := range ops {
+		if o.kind != ' ' {
+			if current == nil || i-lastChange > 2*contextLines {
+				if current != nil {
+					result = append(result, closeHunk(*current, ops, lastChange))
+				}
+				start := max(i-contextLines, 0)
+				current = &hunk{start: start, oldStart: oldLine - (i - start), newStart: newLine - (i - start)}
+			}
+			lastChange = i
+		}
+		if o.kind != '+' {
+			oldLine++
+		}
+		if o.kind != '-' {
+			newLine++
+		}
+	}
+	if current != nil {
+		result = append(result, closeHunk(*current, ops, lastChange))
+	}
+	return result
+}
+
+// closeHunk ends a hunk after the context following its last change and
+// counts its lines
+func closeHunk(h hunk, ops []op, lastChange int) hunk {
+	h.end = min(lastChange+contextLines+1, len(ops))
+	for _, o := range ops[h.start:h.end] {
+		if o.kind != '+' {
+			h.oldCount++
+		}
+		if o.kind != '-' {
+			h.newCount++
+		}
+	}
+	return h
+}
+
+// hunkRange formats the 1-based line range of a hunk. Empty ranges name the
+// line before them.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
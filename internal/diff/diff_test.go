@@ -0,0 +1,48 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUnified tests that diffs match the output of diff -u
+func TestUnified(t *testing.T) {
+	old := "# Title\none\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\n"
+	new := "# Title\none\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten"
+
+	assert.Equal(t, "--- old.md\n+++ new.md\n"+
+		"@@ -1,6 +1,6 @@\n # Title\n one\n-two\n+2\n three\n four\n five\n"+
+		"@@ -8,3 +8,4 @@\n seven\n eight\n nine\n+ten\n\\ No newline at end of file\n",
+		Unified("old.md", "new.md", old, new))
+
+	assert.Equal(t, "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+x\n+y\n", Unified("a", "b", "", "x\ny\n"))
+	assert.Equal(t, "--- a\n+++ b\n@@ -1 +0,0 @@\n-x\n", Unified("a", "b", "x\n", ""))
+	assert.Empty(t, Unified("a", "b", old, old))
+}
+
+// TestUnifiedMergesHunks tests that nearby changes share a hunk
+func TestUnifiedMergesHunks(t *testing.T) {
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n"
+	new := "1\nX\n3\n4\n5\n6\nY\n8\n"
+	assert.Equal(t, "--- a\n+++ b\n@@ -1,8 +1,8 @@\n 1\n-2\n+X\n 3\n 4\n 5\n 6\n-7\n+Y\n 8\n", Unified("a", "b", old, new))
+}
+
+// TestUnifiedLargeChange tests that changes longer than maxEdits fall back to
+// replacing the differing lines wholesale
+func TestUnifiedLargeChange(t *testing.T) {
+	var old, new strings.Builder
+	old.WriteString("keep\n")
+	new.WriteString("keep\n")
+	for i := range maxEdits {
+		fmt.Fprintf(&old, "old %d\n", i)
+		fmt.Fprintf(&new, "new %d\n", i)
+	}
+
+	diff := Unified("a", "b", old.String(), new.String())
+	assert.True(t, strings.HasPrefix(diff, fmt.Sprintf("--- a\n+++ b\n@@ -1,%d +1,%d @@\n keep\n-old 0\n-old 1\n", maxEdits+1, maxEdits+1)), diff[:80])
+	assert.Contains(t, diff, fmt.Sprintf("-old %d\n+new 0\n", maxEdits-1))
+	assert.Equal(t, 2*maxEdits, strings.Count(diff, "\n-")+strings.Count(diff, "\n+")-1)
+}
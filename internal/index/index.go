@@ -0,0 +1,193 @@
+// Package index keeps a local copy of the notes of an Obsidian vault so
+// vault-wide tools do not refetch every note on every call
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+)
+
+// cacheVersion is bumped whenever the layout of the cache file changes
+const cacheVersion = 1
+
+// mtimeQuery is a JsonLogic query returning the modification time of every
+// markdown note in a single request
+const mtimeQuery = `{"var": "stat.mtime"}`
+
+// Vault is a consistent view of the vault at the time of a refresh. It is
+// never modified once returned.
+type Vault struct {
+	// Files lists every vault file in sorted order
+	Files []string `json:"files"`
+	// Notes holds the NoteJson of every markdown note, keyed by path
+	Notes map[string]*obsidian.Note `json:"notes"`
+}
+
+// Contents returns the content of every note, keyed by path
+func (v *Vault) Contents() map[string]string {
+	contents := make(map[string]string, len(v.Notes))
+	for file, note := range v.Notes {
+		contents[file] = note.Content
+	}
+	return contents
+}
+
+// Index crawls the vault and refreshes its copy incrementally, refetching
+// only the notes whose modification time changed
+type Index struct {
+	client    *obsidian.Client
+	cachePath string
+
+	mu      sync.Mutex
+	vault   *Vault
+	started bool
+}
+
+// cacheFile is the on-disk layout of the index cache
+type cacheFile struct {
+	Version int `json:"version"`
+	Vault
+}
+
+// New creates an index of the vault behind client. When cachePath is not
+// empty the index is loaded from and saved to that file between runs.
+func New(client *obsidian.Client, cachePath string) *Index {
+	return &Index{
+		client:    client,
+		cachePath: cachePath,
+		vault:     &Vault{Notes: map[string]*obsidian.Note{}},
+	}
+}
+
+// Refresh brings the index up to date with the vault and returns it. New and
+// modified notes are fetched, deleted ones are dropped.
+func (ix *Index) Refresh(ctx context.Context) (*Vault, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if !ix.started {
+		ix.started = true
+		if cached, ok := ix.load(); ok {
+			ix.vault = cached
+		}
+	}
+
+	files, err := ix.client.ListAllVaultFiles(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Without modification times every note is refetched
+	mtimes, err := ix.modificationTimes(ctx)
+	if err != nil && ctx.Err() != nil {
+		return nil, err
+	}
+
+	var stale []string
+	notes := make(map[string]*obsidian.Note)
+	for _, file := range files {
+		if path.Ext(file) != ".md" {
+			continue
+		}
+		note, ok := ix.vault.Notes[file]
+		if mtime, known := mtimes[file]; ok && known && note.Stat.Mtime == mtime {
+			notes[file] = note
+			continue
+		}
+		stale = append(stale, file)
+	}
+
+	for i, file := range stale {
+		note, err := ix.client.GetNote(ctx, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to index %s: %w", file, err)
+		}
+		notes[file] = note
+		obsidian.ReportProgress(ctx, float64(i+1), float64(len(stale)), "Indexed "+file)
+	}
+
+	changed := len(stale) > 0 || len(notes) != len(ix.vault.Notes) || !slices.Equal(files, ix.vault.Files)
+	ix.vault = &Vault{Files: files, Notes: notes}
+	if changed {
+		if err := ix.save(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ix.vault, nil
+}
+
+// modificationTimes returns the modification time of every markdown note
+func (ix *Index) modificationTimes(ctx context.Context) (map[string]int64, error) {
+	results, err := ix.client.SearchVaultAdvanced(ctx, mtimeQuery, "jsonlogic")
+	if err != nil {
+		return nil, err
+	}
+
+	mtimes := make(map[string]int64, len(results))
+	for _, result := range results {
+		if mtime, ok := result.Result.(float64); ok {
+			mtimes[result.Filename] = int64(mtime)
+		}
+	}
+	return mtimes, nil
+}
+
+// load reads the cache file. A missing or unreadable cache is not an error,
+// the vault is simply crawled again.
+func (ix *Index) load() (*Vault, bool) {
+	if ix.cachePath == "" {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(ix.cachePath)
+	if err != nil {
+		return nil, false
+	}
+
+	var cache cacheFile
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Version != cacheVersion || cache.Notes == nil {
+		return nil, false
+	}
+	return &cache.Vault, true
+}
+
+// save writes the index to the cache file, replacing it atomically
+func (ix *Index) save() error {
+	if ix.cachePath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(cacheFile{Version: cacheVersion, Vault: *ix.vault})
+	if err != nil {
+		return fmt.Errorf("failed to encode index cache: %w", err)
+	}
+
+	dir := filepath.Dir(ix.cachePath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create index cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(ix.cachePath)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write index cache: %w", err)
+	}
+	_, err = tmp.Write(data)
+	err = errors.Join(err, tmp.Close())
+	if err == nil {
+		err = os.Rename(tmp.Name(), ix.cachePath)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write index cache: %w", err)
+	}
+	return nil
+}
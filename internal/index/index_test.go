@@ -0,0 +1,136 @@
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault serves notes with modification times and counts note fetches
+type fakeVault struct {
+	mu      sync.Mutex
+	notes   map[string]*obsidian.Note
+	fetches map[string]int
+	// noSearch makes the search endpoint fail
+	noSearch bool
+}
+
+func newFakeVault(t *testing.T, notes map[string]string) (*fakeVault, *obsidian.Client) {
+	t.Helper()
+	vault := &fakeVault{notes: make(map[string]*obsidian.Note), fetches: make(map[string]int)}
+	for file, content := range notes {
+		vault.set(file, content, 1000)
+	}
+	server := httptest.NewServer(vault)
+	t.Cleanup(server.Close)
+	return vault, obsidian.NewClient("test-token", server.URL)
+}
+
+func (v *fakeVault) set(file, content string, mtime int64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.notes[file] = &obsidian.Note{
+		Path:        file,
+		Content:     content,
+		Frontmatter: map[string]any{},
+		Tags:        []string{},
+		Stat:        obsidian.NoteStat{Mtime: mtime, Size: int64(len(content))},
+	}
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/search/":
+		if v.noSearch {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var results []obsidian.AdvancedSearchResult
+		for file, note := range v.notes {
+			results = append(results, obsidian.AdvancedSearchResult{Filename: file, Result: note.Stat.Mtime})
+		}
+		_ = json.NewEncoder(w).Encode(results)
+	case r.URL.Path == "/vault/":
+		files := []string{"image.png"}
+		for file := range v.notes {
+			files = append(files, file)
+		}
+		_ = json.NewEncoder(w).Encode(obsidian.FileList{Files: files})
+	default:
+		file := strings.TrimPrefix(r.URL.Path, "/vault/")
+		note, ok := v.notes[file]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		v.fetches[file]++
+		_ = json.NewEncoder(w).Encode(note)
+	}
+}
+
+// TestRefreshIncremental tests that only new and modified notes are refetched
+func TestRefreshIncremental(t *testing.T) {
+	vault, client := newFakeVault(t, map[string]string{"A.md": "a", "B.md": "b"})
+	ix := New(client, "")
+
+	v, err := ix.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A.md", "B.md", "image.png"}, v.Files)
+	assert.Equal(t, map[string]string{"A.md": "a", "B.md": "b"}, v.Contents())
+
+	vault.set("A.md", "a2", 2000)
+	vault.set("C.md", "c", 1000)
+
+	v, err = ix.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A.md": "a2", "B.md": "b", "C.md": "c"}, v.Contents())
+	assert.Equal(t, map[string]int{"A.md": 2, "B.md": 1, "C.md": 1}, vault.fetches)
+
+	delete(vault.notes, "B.md")
+	v, err = ix.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.NotContains(t, v.Notes, "B.md")
+	assert.Equal(t, []string{"A.md", "C.md", "image.png"}, v.Files)
+}
+
+// TestRefreshWithoutModificationTimes tests that every note is refetched when
+// the modification times cannot be queried
+func TestRefreshWithoutModificationTimes(t *testing.T) {
+	vault, client := newFakeVault(t, map[string]string{"A.md": "a"})
+	vault.noSearch = true
+	ix := New(client, "")
+
+	for range 2 {
+		_, err := ix.Refresh(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, vault.fetches["A.md"])
+}
+
+// TestRefreshCache tests that the index is persisted between runs
+func TestRefreshCache(t *testing.T) {
+	vault, client := newFakeVault(t, map[string]string{"A.md": "a", "B.md": "b"})
+	cachePath := filepath.Join(t.TempDir(), "cache", "index.json")
+
+	_, err := New(client, cachePath).Refresh(context.Background())
+	require.NoError(t, err)
+	assert.FileExists(t, cachePath)
+
+	vault.set("B.md", "b2", 2000)
+	v, err := New(client, cachePath).Refresh(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A.md": "a", "B.md": "b2"}, v.Contents())
+	assert.Equal(t, map[string]int{"A.md": 1, "B.md": 2}, vault.fetches)
+}
@@ -0,0 +1,122 @@
+// Package links builds the link graph of an Obsidian vault
+package links
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/markdown"
+)
+
+// Edge is a link from one note to another vault file
+type Edge struct {
+	// Source is the note containing the link
+	Source string `json:"source"`
+	// Target is the file the link resolves to, empty for broken links
+	Target string `json:"target,omitempty"`
+	// Path is the link target as written in the note
+	Path    string `json:"path"`
+	Heading string `json:"heading,omitempty"`
+	Block   string `json:"block,omitempty"`
+	Alias   string `json:"alias,omitempty"`
+	Embed   bool   `json:"embed,omitempty"`
+	// Line is the 1-based line of the link in the source note
+	Line int `json:"line"`
+	// Raw is the link as written in the note
+	Raw string `json:"raw"`
+}
+
+// Graph holds the links between the files of a vault
+type Graph struct {
+	notes    []string
+	outgoing map[string][]Edge
+	incoming map[string][]Edge
+	broken   []Edge
+}
+
+// Build creates the link graph of a vault from its file list and the content
+// of its markdown notes, keyed by path. Links to headings or blocks of the
+// same note are not part of the graph.
+func Build(files []string, notes map[string]string) *Graph {
+	resolver := markdown.NewLinkResolver(files)
+	g := &Graph{
+		outgoing: make(map[string][]Edge),
+		incoming: make(map[string][]Edge),
+	}
+
+	for _, file := range files {
+		content, ok := notes[file]
+		if !ok {
+			continue
+		}
+		g.notes = append(g.notes, file)
+
+		for _, link := range markdown.ParseLinks(content) {
+			if link.Path == "" {
+				continue
+			}
+			edge := Edge{
+				Source:  file,
+				Path:    link.Path,
+				Heading: link.Heading,
+				Block:   link.Block,
+				Alias:   link.Alias,
+				Embed:   link.Embed,
+				Line:    link.Line,
+				Raw:     link.Raw,
+			}
+			if target, ok := resolver.Resolve(link.Path, file); ok {
+				edge.Target = target
+				g.incoming[target] = append(g.incoming[target], edge)
+			} else {
+				g.broken = append(g.broken, edge)
+			}
+			g.outgoing[file] = append(g.outgoing[file], edge)
+		}
+	}
+
+	slices.Sort(g.notes)
+	return g
+}
+
+// Outgoing returns the links of a note in order of appearance
+func (g *Graph) Outgoing(file string) []Edge {
+	return nonNil(g.outgoing[strings.TrimPrefix(file, "/")])
+}
+
+// Backlinks returns the links pointing at a file from other notes
+func (g *Graph) Backlinks(file string) []Edge {
+	file = strings.TrimPrefix(file, "/")
+	var backlinks []Edge
+	for _, edge := range g.incoming[file] {
+		if edge.Source != file {
+			backlinks = append(backlinks, edge)
+		}
+	}
+	return nonNil(backlinks)
+}
+
+// Orphans returns the markdown notes no other note links to
+func (g *Graph) Orphans() []string {
+	orphans := []string{}
+	for _, note := range g.notes {
+		if path.Ext(note) == ".md" && len(g.Backlinks(note)) == 0 {
+			orphans = append(orphans, note)
+		}
+	}
+	return orphans
+}
+
+// Broken returns the links that do not resolve to any vault file
+func (g *Graph) Broken() []Edge {
+	return nonNil(g.broken)
+}
+
+// nonNil returns an empty slice instead of nil so results encode as [] in JSON
+func nonNil(edges []Edge) []Edge {
+	if edges == nil {
+		return []Edge{}
+	}
+	return edges
+}
@@ -0,0 +1,75 @@
+package links
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGraph builds a small vault graph
+func testGraph() *Graph {
+	files := []string{
+		"Index.md",
+		"Projects/Plan.md",
+		"Projects/Notes.md",
+		"Lonely.md",
+		"assets/diagram.png",
+	}
+	return Build(files, map[string]string{
+		"Index.md":          "[[Plan]] and [Notes](Projects/Notes.md#Todo)\n![[diagram.png]]\n[[Missing note]]",
+		"Projects/Plan.md":  "Back to [[Index|home]], see [[#Goals]] and [[Plan#^step1]]",
+		"Projects/Notes.md": "Part of [[Projects/Plan#Goals]]",
+		"Lonely.md":         "Links to [[Nowhere]]",
+	})
+}
+
+// TestGraphBacklinks tests finding links pointing at a file
+func TestGraphBacklinks(t *testing.T) {
+	g := testGraph()
+
+	backlinks := g.Backlinks("Projects/Plan.md")
+	require.Len(t, backlinks, 2)
+	assert.Equal(t, Edge{Source: "Index.md", Target: "Projects/Plan.md", Path: "Plan", Line: 1, Raw: "[[Plan]]"}, backlinks[0])
+	assert.Equal(t, "Projects/Notes.md", backlinks[1].Source)
+	assert.Equal(t, "Goals", backlinks[1].Heading)
+
+	backlinks = g.Backlinks("/assets/diagram.png")
+	require.Len(t, backlinks, 1)
+	assert.True(t, backlinks[0].Embed)
+
+	assert.Empty(t, g.Backlinks("Lonely.md"))
+	assert.NotNil(t, g.Backlinks("Lonely.md"))
+}
+
+// TestGraphOutgoing tests listing the links of a note
+func TestGraphOutgoing(t *testing.T) {
+	g := testGraph()
+
+	outgoing := g.Outgoing("Index.md")
+	require.Len(t, outgoing, 4)
+	assert.Equal(t, "Projects/Notes.md", outgoing[1].Target)
+	assert.Equal(t, "Todo", outgoing[1].Heading)
+	assert.Equal(t, "assets/diagram.png", outgoing[2].Target)
+	assert.Equal(t, "", outgoing[3].Target)
+
+	// Same-note heading links are not part of the graph
+	outgoing = g.Outgoing("Projects/Plan.md")
+	require.Len(t, outgoing, 2)
+	assert.Equal(t, "Index.md", outgoing[0].Target)
+	assert.Equal(t, "home", outgoing[0].Alias)
+	assert.Equal(t, "step1", outgoing[1].Block)
+}
+
+// TestGraphOrphansAndBroken tests finding unlinked notes and broken links
+func TestGraphOrphansAndBroken(t *testing.T) {
+	g := testGraph()
+
+	assert.Equal(t, []string{"Lonely.md"}, g.Orphans())
+
+	broken := g.Broken()
+	require.Len(t, broken, 2)
+	assert.Equal(t, "Missing note", broken[0].Path)
+	assert.Equal(t, 3, broken[0].Line)
+	assert.Equal(t, "Lonely.md", broken[1].Source)
+}
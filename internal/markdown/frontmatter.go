@@ -0,0 +1,139 @@
+// Package markdown provides helpers for working with Obsidian markdown notes
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontmatterDelimiter opens and closes a YAML frontmatter block
+const frontmatterDelimiter = "---"
+
+// SplitFrontmatter separates a leading YAML frontmatter block from the rest of
+// a note. The returned frontmatter excludes the delimiter lines. Notes without
+// frontmatter are returned unchanged as the body.
+func SplitFrontmatter(content string) (frontmatter, body string) {
+	first, rest, ok := strings.Cut(content, "\n")
+	if !ok || strings.TrimRight(first, " \t\r") != frontmatterDelimiter {
+		return "", content
+	}
+
+	offset := 0
+	for {
+		line, remainder, found := strings.Cut(rest[offset:], "\n")
+		if strings.TrimRight(line, " \t\r") == frontmatterDelimiter {
+			frontmatter = rest[:offset]
+			if !found {
+				return frontmatter, ""
+			}
+			return frontmatter, remainder
+		}
+		if !found {
+			return "", content
+		}
+		offset = len(rest) - len(remainder)
+	}
+}
+
+// SetFrontmatterField sets a frontmatter property of a note to a value decoded
+// from JSON, adding the property, or the frontmatter itself, when missing.
+// The order of the other properties and the YAML comments are preserved.
+func SetFrontmatterField(content, field string, value any) (string, error) {
+	mapping, body, err := parseFrontmatter(content)
+	if err != nil {
+		return "", err
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return "", fmt.Errorf("failed to encode value of %s: %w", field, err)
+	}
+
+	if i := fieldIndex(mapping, field); i >= 0 {
+		old := mapping.Content[i+1]
+		node.LineComment = old.LineComment
+		node.FootComment = old.FootComment
+		mapping.Content[i+1] = &node
+	} else {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: field}
+		mapping.Content = append(mapping.Content, key, &node)
+	}
+
+	return formatFrontmatter(mapping, body)
+}
+
+// DeleteFrontmatterField removes a frontmatter property of a note and reports
+// whether it was present. The frontmatter is removed once it is empty.
+func DeleteFrontmatterField(content, field string) (string, bool, error) {
+	mapping, body, err := parseFrontmatter(content)
+	if err != nil {
+		return "", false, err
+	}
+
+	i := fieldIndex(mapping, field)
+	if i < 0 {
+		return content, false, nil
+	}
+	mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+
+	updated, err := formatFrontmatter(mapping, body)
+	return updated, true, err
+}
+
+// parseFrontmatter parses the frontmatter of a note into a YAML mapping node,
+// which is empty when the note has no frontmatter
+func parseFrontmatter(content string) (*yaml.Node, string, error) {
+	frontmatter, body := SplitFrontmatter(content)
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if strings.TrimSpace(frontmatter) == "" {
+		return mapping, body, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatter), &doc); err != nil {
+		return nil, "", fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return mapping, body, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, "", fmt.Errorf("invalid frontmatter: expected a mapping of properties")
+	}
+	// Comments above the first property are attached to the document
+	doc.Content[0].HeadComment = strings.TrimSpace(doc.HeadComment + "\n" + doc.Content[0].HeadComment)
+	doc.Content[0].FootComment = strings.TrimSpace(doc.Content[0].FootComment + "\n" + doc.FootComment)
+	return doc.Content[0], body, nil
+}
+
+// fieldIndex returns the index of the key node of a property in a mapping
+// node, or -1 when the property is not set
+func fieldIndex(mapping *yaml.Node, field string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == field {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatFrontmatter renders a frontmatter mapping followed by the note body
+func formatFrontmatter(mapping *yaml.Node, body string) (string, error) {
+	if len(mapping.Content) == 0 && mapping.HeadComment == "" && mapping.FootComment == "" {
+		return body, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(mapping); err != nil {
+		return "", fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+
+	return frontmatterDelimiter + "\n" + buf.String() + frontmatterDelimiter + "\n" + body, nil
+}
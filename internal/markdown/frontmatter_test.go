@@ -0,0 +1,131 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSplitFrontmatter tests separating frontmatter from note bodies
+func TestSplitFrontmatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		frontmatter string
+		body        string
+	}{
+		{
+			name:        "with frontmatter",
+			content:     "---\ntitle: Test\ntags: [a]\n---\n# Heading\n",
+			frontmatter: "title: Test\ntags: [a]\n",
+			body:        "# Heading\n",
+		},
+		{
+			name:        "empty frontmatter",
+			content:     "---\n---\nBody",
+			frontmatter: "",
+			body:        "Body",
+		},
+		{
+			name:        "crlf line endings",
+			content:     "---\r\ntitle: Test\r\n---\r\nBody",
+			frontmatter: "title: Test\r\n",
+			body:        "Body",
+		},
+		{
+			name:        "frontmatter only",
+			content:     "---\ntitle: Test\n---",
+			frontmatter: "title: Test\n",
+			body:        "",
+		},
+		{
+			name:        "no frontmatter",
+			content:     "# Heading\n---\n",
+			frontmatter: "",
+			body:        "# Heading\n---\n",
+		},
+		{
+			name:        "unterminated frontmatter",
+			content:     "---\ntitle: Test\n",
+			frontmatter: "",
+			body:        "---\ntitle: Test\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frontmatter, body := SplitFrontmatter(tt.content)
+			assert.Equal(t, tt.frontmatter, frontmatter)
+			assert.Equal(t, tt.body, body)
+		})
+	}
+}
+
+// TestSetFrontmatterField tests setting typed frontmatter properties
+func TestSetFrontmatterField(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		field    string
+		value    any
+		expected string
+	}{
+		{
+			name:     "replace keeps order and comments",
+			content:  "---\n# Note properties\ntitle: Old # the title\nstatus: draft\n---\nBody\n",
+			field:    "title",
+			value:    "New",
+			expected: "---\n# Note properties\ntitle: New # the title\nstatus: draft\n---\nBody\n",
+		},
+		{
+			name:     "add list",
+			content:  "---\ntitle: Note\n---\nBody",
+			field:    "aliases",
+			value:    []any{"One", "Two"},
+			expected: "---\ntitle: Note\naliases:\n  - One\n  - Two\n---\nBody",
+		},
+		{
+			name:     "number",
+			content:  "---\nrating: 1\n---\n",
+			field:    "rating",
+			value:    float64(4.5),
+			expected: "---\nrating: 4.5\n---\n",
+		},
+		{
+			name:     "create frontmatter",
+			content:  "# Heading\n",
+			field:    "done",
+			value:    true,
+			expected: "---\ndone: true\n---\n# Heading\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := SetFrontmatterField(tt.content, tt.field, tt.value)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, updated)
+		})
+	}
+
+	_, err := SetFrontmatterField("---\n- a list\n---\n", "title", "x")
+	assert.Error(t, err)
+}
+
+// TestDeleteFrontmatterField tests removing frontmatter properties
+func TestDeleteFrontmatterField(t *testing.T) {
+	updated, deleted, err := DeleteFrontmatterField("---\ntitle: Note\ntags: [a, b] # flow\n---\nBody", "title")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Equal(t, "---\ntags: [a, b] # flow\n---\nBody", updated)
+
+	updated, deleted, err = DeleteFrontmatterField(updated, "missing")
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+	assert.Equal(t, "---\ntags: [a, b] # flow\n---\nBody", updated)
+
+	updated, deleted, err = DeleteFrontmatterField(updated, "tags")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Equal(t, "Body", updated)
+}
@@ -0,0 +1,332 @@
+package markdown
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// wikilinkPattern matches [[target]] and ![[target]] links
+	wikilinkPattern = regexp.MustCompile(`(!?)\[\[([^\[\]\n]+?)\]\]`)
+	// markdownLinkPattern matches [text](destination) and ![text](destination)
+	// links with an optional title
+	markdownLinkPattern = regexp.MustCompile(`(!?)\[([^\[\]\n]*)\]\((<[^<>\n]+>|[^()\s<>]+)(\s+"[^"\n]*")?\)`)
+	// urlSchemePattern matches the scheme of an absolute URL
+	urlSchemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+)
+
+// Link is an internal link found in a note, either a wikilink or a markdown
+// link to another file in the vault
+type Link struct {
+	// Raw is the link as written in the note
+	Raw string
+	// Start and End are the byte offsets of Raw in the note
+	Start, End int
+	// Line is the 1-based line the link starts on
+	Line int
+	// Embed reports whether the link embeds its target (![[...]] or ![...](...))
+	Embed bool
+	// Wikilink reports whether the link uses the [[...]] syntax
+	Wikilink bool
+	// Path is the linked file as written, without heading or block reference.
+	// It is empty for links to a heading or block of the same note.
+	Path string
+	// Heading is the referenced heading, if any
+	Heading string
+	// Block is the referenced block ID, if any
+	Block string
+	// Alias is the display text of the link, if any
+	Alias string
+
+	// destination is the raw destination of a markdown link
+	destination string
+	// escapedPipe reports whether the alias separator is escaped as \| because
+	// the wikilink is inside a table
+	escapedPipe bool
+}
+
+// ParseLinks returns the internal links of a note in order of appearance.
+// Links inside code blocks and inline code are ignored, as are markdown
+// links to external URLs.
+func ParseLinks(content string) []Link {
+	code := codeRanges(content)
+
+	var links []Link
+	for _, m := range wikilinkPattern.FindAllStringSubmatchIndex(content, -1) {
+		if inRanges(code, m[0]) {
+			continue
+		}
+		link := Link{
+			Raw:      content[m[0]:m[1]],
+			Start:    m[0],
+			End:      m[1],
+			Embed:    m[3] > m[2],
+			Wikilink: true,
+		}
+
+		inner := content[m[4]:m[5]]
+		if target, alias, ok := strings.Cut(inner, "|"); ok {
+			// Inside tables the pipe is escaped as \|
+			inner, link.escapedPipe = strings.CutSuffix(target, `\`)
+			link.Alias = alias
+		}
+		link.Path, link.Heading, link.Block = splitSubpath(inner)
+		links = append(links, link)
+	}
+
+	for _, m := range markdownLinkPattern.FindAllStringSubmatchIndex(content, -1) {
+		if inRanges(code, m[0]) {
+			continue
+		}
+		destination := content[m[6]:m[7]]
+		target := strings.TrimSuffix(strings.TrimPrefix(destination, "<"), ">")
+		if urlSchemePattern.MatchString(target) {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(target); err == nil {
+			target = unescaped
+		}
+
+		link := Link{
+			Raw:         content[m[0]:m[1]],
+			Start:       m[0],
+			End:         m[1],
+			Embed:       m[3] > m[2],
+			Alias:       content[m[4]:m[5]],
+			destination: destination,
+		}
+		link.Path, link.Heading, link.Block = splitSubpath(target)
+		links = append(links, link)
+	}
+
+	slices.SortFunc(links, func(a, b Link) int { return a.Start - b.Start })
+	for i := range links {
+		links[i].Line = strings.Count(content[:links[i].Start], "\n") + 1
+	}
+	return links
+}
+
+// splitSubpath separates a link target into the file path and the heading or
+// block it references. Both [[note#^block]] and [[note^block]] are accepted.
+func splitSubpath(target string) (file, heading, block string) {
+	file, subpath, ok := strings.Cut(target, "#")
+	if !ok {
+		file, block, _ = strings.Cut(target, "^")
+		return strings.TrimSpace(file), "", block
+	}
+	if rest, ok := strings.CutPrefix(subpath, "^"); ok {
+		return strings.TrimSpace(file), "", rest
+	}
+	return strings.TrimSpace(file), subpath, ""
+}
+
+// WithPath returns the link as it would be written pointing to another file,
+// keeping its embed marker, heading or block reference and alias
+func (l Link) WithPath(linkPath string) string {
+	subpath := ""
+	switch {
+	case l.Block != "":
+		subpath = "#^" + l.Block
+	case l.Heading != "":
+		subpath = "#" + l.Heading
+	}
+
+	if l.Wikilink {
+		raw := "[[" + linkPath + subpath
+		if l.escapedPipe {
+			raw += `\|` + l.Alias
+		} else if l.Alias != "" {
+			raw += "|" + l.Alias
+		}
+		raw += "]]"
+		if l.Embed {
+			raw = "!" + raw
+		}
+		return raw
+	}
+
+	var destination string
+	if strings.HasPrefix(l.destination, "<") {
+		destination = "<" + linkPath + subpath + ">"
+	} else {
+		destination = strings.ReplaceAll(linkPath, " ", "%20") + strings.ReplaceAll(subpath, " ", "%20")
+	}
+	return strings.Replace(l.Raw, "("+l.destination, "("+destination, 1)
+}
+
+// ReplaceLinks rewrites the links of a note. The replace function returns the
+// new text for a link and whether it should be replaced.
+func ReplaceLinks(content string, replace func(link Link) (string, bool)) string {
+	var b strings.Builder
+	last := 0
+	for _, link := range ParseLinks(content) {
+		raw, ok := replace(link)
+		if !ok || link.Start < last {
+			continue
+		}
+		b.WriteString(content[last:link.Start])
+		b.WriteString(raw)
+		last = link.End
+	}
+	if last == 0 {
+		return content
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// codeRanges returns the byte ranges of fenced code blocks and inline code
+// spans, in which links are not interpreted
+func codeRanges(content string) [][2]int {
+	var ranges [][2]int
+	var fence string
+	fenceStart := 0
+	textStart := 0
+
+	offset := 0
+	for offset < len(content) {
+		end := strings.IndexByte(content[offset:], '\n')
+		if end < 0 {
+			end = len(content)
+		} else {
+			end += offset + 1
+		}
+		line := strings.TrimLeft(content[offset:end], " ")
+
+		if fence == "" {
+			if marker := fenceMarker(line); marker != "" {
+				ranges = append(ranges, inlineCodeRanges(content, textStart, offset)...)
+				fence = marker
+				fenceStart = offset
+			}
+		} else if strings.HasPrefix(line, fence) && strings.TrimSpace(strings.TrimLeft(line, fence[:1])) == "" {
+			ranges = append(ranges, [2]int{fenceStart, end})
+			fence = ""
+			textStart = end
+		}
+		offset = end
+	}
+
+	if fence != "" {
+		return append(ranges, [2]int{fenceStart, len(content)})
+	}
+	return append(ranges, inlineCodeRanges(content, textStart, len(content))...)
+}
+
+// fenceMarker returns the backtick or tilde run opening a fenced code block
+func fenceMarker(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+// inlineCodeRanges returns the inline code spans between start and end
+func inlineCodeRanges(content string, start, end int) [][2]int {
+	var ranges [][2]int
+	for i := start; i < end; {
+		if content[i] != '`' {
+			i++
+			continue
+		}
+		n := 0
+		for i+n < end && content[i+n] == '`' {
+			n++
+		}
+		closing := -1
+		for j := i + n; j < end; {
+			if content[j] != '`' {
+				j++
+				continue
+			}
+			m := 0
+			for j+m < end && content[j+m] == '`' {
+				m++
+			}
+			if m == n {
+				closing = j + m
+				break
+			}
+			j += m
+		}
+		if closing < 0 {
+			i += n
+			continue
+		}
+		ranges = append(ranges, [2]int{i, closing})
+		i = closing
+	}
+	return ranges
+}
+
+// LinkResolver resolves link paths to vault files the way Obsidian does
+type LinkResolver struct {
+	files  []string
+	byPath map[string]string
+}
+
+// NewLinkResolver creates a resolver for the given vault files
+func NewLinkResolver(files []string) *LinkResolver {
+	r := &LinkResolver{
+		files:  files,
+		byPath: make(map[string]string, len(files)),
+	}
+	for _, file := range files {
+		r.byPath[strings.ToLower(file)] = file
+	}
+	return r
+}
+
+// Resolve returns the vault file a link path written in the source note
+// points to. Paths are matched case-insensitively, first against the vault
+// root, then relative to the source note and finally as the shortest vault
+// path ending in the link path. Links without a path refer to the source.
+func (r *LinkResolver) Resolve(linkPath, source string) (string, bool) {
+	if linkPath == "" {
+		return source, true
+	}
+
+	linkPath = strings.TrimPrefix(linkPath, "/")
+	if strings.HasPrefix(linkPath, "./") || strings.HasPrefix(linkPath, "../") {
+		linkPath = path.Join(path.Dir(source), linkPath)
+	}
+
+	candidates := []string{linkPath}
+	if path.Ext(linkPath) != ".md" {
+		candidates = []string{linkPath + ".md", linkPath}
+	}
+
+	for _, candidate := range candidates {
+		if file, ok := r.byPath[strings.ToLower(candidate)]; ok {
+			return file, true
+		}
+	}
+	for _, candidate := range candidates {
+		if file, ok := r.byPath[strings.ToLower(path.Join(path.Dir(source), candidate))]; ok {
+			return file, true
+		}
+	}
+
+	for _, candidate := range candidates {
+		suffix := "/" + strings.ToLower(candidate)
+		best := ""
+		for _, file := range r.files {
+			if !strings.HasSuffix(strings.ToLower(file), suffix) {
+				continue
+			}
+			if best == "" || len(file) < len(best) || (len(file) == len(best) && file < best) {
+				best = file
+			}
+		}
+		if best != "" {
+			return best, true
+		}
+	}
+	return "", false
+}
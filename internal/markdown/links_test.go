@@ -0,0 +1,125 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseLinks tests extracting wikilinks and markdown links from a note
+func TestParseLinks(t *testing.T) {
+	content := "# Links\n" +
+		"See [[Note]] and [[Folder/Other|the other one]].\n" +
+		"![[image.png]] [[Note#Section]] [[Note#^abc123]] [[Note^def]]\n" +
+		"[Markdown](Folder/My%20Note.md#Intro) ![pic](<assets/a b.png>) [web](https://example.com)\n" +
+		"| [[Table\\|alias]] |\n" +
+		"`[[inline code]]`\n" +
+		"```\n[[fenced]]\n```\n" +
+		"[[#Local heading]]\n"
+
+	links := ParseLinks(content)
+	require.Len(t, links, 10)
+
+	assert.Equal(t, Link{Raw: "[[Note]]", Start: 12, End: 20, Line: 2, Wikilink: true, Path: "Note"}, links[0])
+
+	assert.Equal(t, "Folder/Other", links[1].Path)
+	assert.Equal(t, "the other one", links[1].Alias)
+
+	assert.True(t, links[2].Embed)
+	assert.Equal(t, "image.png", links[2].Path)
+	assert.Equal(t, 3, links[2].Line)
+
+	assert.Equal(t, "Section", links[3].Heading)
+	assert.Equal(t, "abc123", links[4].Block)
+	assert.Equal(t, "Note", links[5].Path)
+	assert.Equal(t, "def", links[5].Block)
+
+	assert.False(t, links[6].Wikilink)
+	assert.Equal(t, "Folder/My Note.md", links[6].Path)
+	assert.Equal(t, "Intro", links[6].Heading)
+	assert.Equal(t, "Markdown", links[6].Alias)
+
+	assert.True(t, links[7].Embed)
+	assert.Equal(t, "assets/a b.png", links[7].Path)
+
+	assert.Equal(t, "Table", links[8].Path)
+	assert.Equal(t, "alias", links[8].Alias)
+	assert.True(t, links[8].escapedPipe)
+
+	assert.Equal(t, "", links[9].Path)
+	assert.Equal(t, "Local heading", links[9].Heading)
+	assert.Equal(t, 10, links[9].Line)
+}
+
+// TestLinkWithPath tests rewriting a link to point at another file
+func TestLinkWithPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		path     string
+		expected string
+	}{
+		{"wikilink", "[[Old]]", "New", "[[New]]"},
+		{"wikilink alias and heading", "[[Old#Part|label]]", "dir/New", "[[dir/New#Part|label]]"},
+		{"embed with block", "![[Old#^id]]", "New", "![[New#^id]]"},
+		{"short block", "[[Old^id]]", "New", "[[New#^id]]"},
+		{"table alias", "[[Old\\|label]]", "New", "[[New\\|label]]"},
+		{"markdown link", `[text](Old.md "title")`, "dir/New Name.md", `[text](dir/New%20Name.md "title")`},
+		{"angle brackets", "![img](<old pic.png>)", "new pic.png", "![img](<new pic.png>)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links := ParseLinks(tt.content)
+			require.Len(t, links, 1)
+			assert.Equal(t, tt.expected, links[0].WithPath(tt.path))
+		})
+	}
+}
+
+// TestReplaceLinks tests rewriting selected links in a note
+func TestReplaceLinks(t *testing.T) {
+	content := "[[A]] and [[B]] and `[[A]]`"
+	result := ReplaceLinks(content, func(link Link) (string, bool) {
+		if link.Path != "A" {
+			return "", false
+		}
+		return link.WithPath("C"), true
+	})
+	assert.Equal(t, "[[C]] and [[B]] and `[[A]]`", result)
+}
+
+// TestLinkResolver tests resolving link paths to vault files
+func TestLinkResolver(t *testing.T) {
+	resolver := NewLinkResolver([]string{
+		"Note.md",
+		"Projects/Note.md",
+		"Projects/Plan.md",
+		"Archive/Old/Plan.md",
+		"assets/image.png",
+	})
+
+	tests := []struct {
+		linkPath string
+		source   string
+		expected string
+		found    bool
+	}{
+		{"Note", "Daily/2024.md", "Note.md", true},
+		{"projects/note", "Daily/2024.md", "Projects/Note.md", true},
+		{"Plan", "Daily/2024.md", "Projects/Plan.md", true},
+		{"Plan", "Archive/Old/Index.md", "Archive/Old/Plan.md", true},
+		{"Old/Plan.md", "Index.md", "Archive/Old/Plan.md", true},
+		{"image.png", "Note.md", "assets/image.png", true},
+		{"../Note.md", "Projects/Plan.md", "Note.md", true},
+		{"", "Projects/Plan.md", "Projects/Plan.md", true},
+		{"Missing", "Note.md", "", false},
+	}
+
+	for _, tt := range tests {
+		file, ok := resolver.Resolve(tt.linkPath, tt.source)
+		assert.Equal(t, tt.found, ok, tt.linkPath)
+		assert.Equal(t, tt.expected, file, tt.linkPath)
+	}
+}
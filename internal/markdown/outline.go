@@ -0,0 +1,137 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// headingPattern matches an ATX heading, capturing its markers and text
+	headingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	// blockReferencePattern matches a block ID at the end of a line or on a
+	// line of its own
+	blockReferencePattern = regexp.MustCompile(`(?:^|\s)\^([A-Za-z0-9-]+)\s*$`)
+)
+
+// Outline is the heading structure of a note
+type Outline struct {
+	// Lines is the number of lines in the note
+	Lines int `json:"lines"`
+	// Blocks lists the block IDs before the first heading
+	Blocks   []Block    `json:"blocks,omitempty"`
+	Headings []*Section `json:"headings"`
+}
+
+// Section is a heading and the lines below it up to the next heading of the
+// same or a higher level
+type Section struct {
+	Heading string `json:"heading"`
+	Level   int    `json:"level"`
+	// StartLine is the 1-based line of the heading
+	StartLine int `json:"startLine"`
+	// EndLine is the last line of the section, including its subsections
+	EndLine int `json:"endLine"`
+	// Blocks lists the block IDs in the section before its first subsection
+	Blocks   []Block    `json:"blocks,omitempty"`
+	Children []*Section `json:"children,omitempty"`
+}
+
+// Block is a block ID and the 1-based line it is declared on
+type Block struct {
+	ID   string `json:"id"`
+	Line int    `json:"line"`
+}
+
+// ParseOutline returns the heading tree of a note. Headings in the
+// frontmatter and in code blocks are ignored.
+func ParseOutline(content string) *Outline {
+	_, body := SplitFrontmatter(content)
+	bodyStart := len(content) - len(body)
+	code := codeRanges(content)
+
+	lines := strings.SplitAfter(content, "\n")
+	if strings.HasSuffix(content, "\n") || content == "" {
+		lines = lines[:len(lines)-1]
+	}
+	outline := &Outline{Lines: len(lines), Headings: []*Section{}}
+
+	// stack holds the open sections from the outermost to the innermost
+	var stack []*Section
+	offset := 0
+	for i, line := range lines {
+		start := offset
+		offset += len(line)
+		if start < bodyStart || inRanges(code, start) {
+			continue
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			section := &Section{Heading: m[2], Level: len(m[1]), StartLine: i + 1}
+			for len(stack) > 0 && stack[len(stack)-1].Level >= section.Level {
+				stack[len(stack)-1].EndLine = i
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) == 0 {
+				outline.Headings = append(outline.Headings, section)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, section)
+			}
+			stack = append(stack, section)
+			continue
+		}
+
+		if m := blockReferencePattern.FindStringSubmatch(line); m != nil {
+			block := Block{ID: m[1], Line: i + 1}
+			if len(stack) == 0 {
+				outline.Blocks = append(outline.Blocks, block)
+			} else if section := stack[len(stack)-1]; len(section.Children) == 0 {
+				section.Blocks = append(section.Blocks, block)
+			}
+		}
+	}
+	for _, section := range stack {
+		section.EndLine = len(lines)
+	}
+
+	return outline
+}
+
+// FindSection returns the section at a heading path, such as
+// ["Projects", "Alpha"] for the Alpha heading below Projects. Like the PATCH
+// Target header, the path starts at a top-level heading of the note.
+func (o *Outline) FindSection(path []string) (*Section, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("heading path cannot be empty")
+	}
+
+	sections := o.Headings
+	var found *Section
+	for depth, heading := range path {
+		found = nil
+		for _, section := range sections {
+			if section.Heading == strings.TrimSpace(heading) {
+				found = section
+				break
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("heading not found: %s", strings.Join(path[:depth+1], " > "))
+		}
+		sections = found.Children
+	}
+	return found, nil
+}
+
+// Lines returns the 1-based inclusive range of lines of a note
+func Lines(content string, start, end int) string {
+	lines := strings.SplitAfter(content, "\n")
+	start = max(start, 1)
+	end = min(end, len(lines))
+	if start > end {
+		return ""
+	}
+	return strings.Join(lines[start-1:end], "")
+}
@@ -0,0 +1,64 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outlineNote is a note with nested headings, block IDs and decoys
+const outlineNote = "---\ntitle: Note\n---\n" +
+	"Intro ^intro\n" +
+	"# Projects\n" +
+	"Overview ^overview\n" +
+	"## Alpha ##\n" +
+	"- [ ] task ^task-1\n" +
+	"```\n# not a heading\n```\n" +
+	"## Beta\n" +
+	"#tag is not a heading\n" +
+	"# Archive\n" +
+	"Old stuff\n"
+
+// TestParseOutline tests building the heading tree of a note
+func TestParseOutline(t *testing.T) {
+	outline := ParseOutline(outlineNote)
+
+	assert.Equal(t, 15, outline.Lines)
+	assert.Equal(t, []Block{{ID: "intro", Line: 4}}, outline.Blocks)
+	require.Len(t, outline.Headings, 2)
+
+	projects := outline.Headings[0]
+	assert.Equal(t, "Projects", projects.Heading)
+	assert.Equal(t, 1, projects.Level)
+	assert.Equal(t, 5, projects.StartLine)
+	assert.Equal(t, 13, projects.EndLine)
+	assert.Equal(t, []Block{{ID: "overview", Line: 6}}, projects.Blocks)
+
+	require.Len(t, projects.Children, 2)
+	assert.Equal(t, &Section{
+		Heading:   "Alpha",
+		Level:     2,
+		StartLine: 7,
+		EndLine:   11,
+		Blocks:    []Block{{ID: "task-1", Line: 8}},
+	}, projects.Children[0])
+	assert.Equal(t, 12, projects.Children[1].StartLine)
+	assert.Equal(t, 13, projects.Children[1].EndLine)
+
+	assert.Equal(t, &Section{Heading: "Archive", Level: 1, StartLine: 14, EndLine: 15}, outline.Headings[1])
+}
+
+// TestFindSection tests resolving heading paths
+func TestFindSection(t *testing.T) {
+	outline := ParseOutline(outlineNote)
+
+	section, err := outline.FindSection([]string{"Projects", "Beta"})
+	require.NoError(t, err)
+	assert.Equal(t, "## Beta\n#tag is not a heading\n", Lines(outlineNote, section.StartLine, section.EndLine))
+
+	_, err = outline.FindSection([]string{"Beta"})
+	assert.EqualError(t, err, "heading not found: Beta")
+	_, err = outline.FindSection([]string{"Projects", "Gamma"})
+	assert.EqualError(t, err, "heading not found: Projects > Gamma")
+}
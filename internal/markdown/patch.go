@@ -0,0 +1,161 @@
+package markdown
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ApplyPatch computes the result of a PATCH request of the Local REST API
+// locally, so a change can be previewed without writing it. operation is
+// "append", "prepend" or "replace" and targetType is "heading", "block" or
+// "frontmatter". Headings are addressed by their path joined with delimiter;
+// blocks are the lines carrying the block ID. Frontmatter values are decoded
+// from JSON when contentType is application/json and used as text otherwise.
+func ApplyPatch(content, operation, targetType, target, patch, contentType, delimiter string) (string, error) {
+	switch operation {
+	case "append", "prepend", "replace":
+	default:
+		return "", fmt.Errorf("unsupported operation: %s", operation)
+	}
+
+	switch targetType {
+	case "heading":
+		return patchHeading(content, operation, target, patch, delimiter)
+	case "block":
+		return patchBlock(content, operation, strings.TrimPrefix(target, "^"), patch)
+	case "frontmatter":
+		return patchFrontmatter(content, operation, target, patch, contentType)
+	default:
+		return "", fmt.Errorf("unsupported target type: %s", targetType)
+	}
+}
+
+// patchHeading patches the section below a heading. Appended content goes
+// after the last non-blank line of the section, including its subsections.
+func patchHeading(content, operation, target, patch, delimiter string) (string, error) {
+	if delimiter == "" {
+		delimiter = "::"
+	}
+	section, err := ParseOutline(content).FindSection(strings.Split(target, delimiter))
+	if err != nil {
+		return "", err
+	}
+
+	switch operation {
+	case "prepend":
+		return spliceLines(content, section.StartLine+1, section.StartLine, patch), nil
+	case "replace":
+		return spliceLines(content, section.StartLine+1, section.EndLine, patch), nil
+	}
+	last := section.EndLine
+	for last > section.StartLine && strings.TrimSpace(Lines(content, last, last)) == "" {
+		last--
+	}
+	return spliceLines(content, last+1, last, patch), nil
+}
+
+// patchBlock patches the line carrying a block ID. Replacing keeps the ID
+// so that references to the block stay valid.
+func patchBlock(content, operation, id, patch string) (string, error) {
+	code := codeRanges(content)
+	offset := 0
+	for i, line := range strings.SplitAfter(content, "\n") {
+		start := offset
+		offset += len(line)
+		m := blockReferencePattern.FindStringSubmatchIndex(strings.TrimRight(line, "\r\n"))
+		if m == nil || inRanges(code, start) || line[m[2]:m[3]] != id {
+			continue
+		}
+
+		n := i + 1
+		switch operation {
+		case "prepend":
+			return spliceLines(content, n, n-1, patch), nil
+		case "append":
+			return spliceLines(content, n+1, n, patch), nil
+		}
+		marker := strings.TrimRight(line[m[0]:], "\r\n")
+		return spliceLines(content, n, n, strings.TrimRight(patch, "\n")+" "+strings.TrimSpace(marker)+"\n"), nil
+	}
+	return "", fmt.Errorf("block not found: ^%s", id)
+}
+
+// TrimBlockID removes the block ID from the end of a line, giving the text to
+// send when replacing the block, which keeps its ID
+func TrimBlockID(line string) string {
+	text := strings.TrimRight(line, "\r\n")
+	if m := blockReferencePattern.FindStringIndex(text); m != nil {
+		text = text[:m[0]]
+	}
+	return text + line[len(strings.TrimRight(line, "\r\n")):]
+}
+
+// patchFrontmatter patches a frontmatter property. Appending or prepending
+// adds items to lists and joins text; missing properties are set.
+func patchFrontmatter(content, operation, field, patch, contentType string) (string, error) {
+	if field == "" {
+		return "", fmt.Errorf("field cannot be empty")
+	}
+
+	var value any = patch
+	if strings.HasPrefix(contentType, "application/json") {
+		if err := json.Unmarshal([]byte(patch), &value); err != nil {
+			return "", fmt.Errorf("invalid JSON value for %s: %w", field, err)
+		}
+	}
+
+	frontmatter, _ := SplitFrontmatter(content)
+	properties := map[string]any{}
+	if err := yaml.Unmarshal([]byte(frontmatter), &properties); err != nil {
+		return "", fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+
+	if existing, ok := properties[field]; ok && operation != "replace" {
+		switch old := existing.(type) {
+		case []any:
+			items, isList := value.([]any)
+			if !isList {
+				items = []any{value}
+			}
+			if operation == "append" {
+				value = append(old, items...)
+			} else {
+				value = append(items, old...)
+			}
+		case string:
+			text, isText := value.(string)
+			if !isText {
+				return "", fmt.Errorf("cannot %s a non-text value to text property %s", operation, field)
+			}
+			if operation == "append" {
+				value = old + text
+			} else {
+				value = text + old
+			}
+		default:
+			return "", fmt.Errorf("cannot %s to property %s of type %T", operation, field, existing)
+		}
+	}
+
+	return SetFrontmatterField(content, field, value)
+}
+
+// spliceLines replaces the 1-based inclusive line range from..to with text.
+// A range with to = from-1 inserts text before line from. Line breaks are
+// added so that the text stays on lines of its own.
+func spliceLines(content string, from, to int, text string) string {
+	lines := strings.SplitAfter(content, "\n")
+	prefix := strings.Join(lines[:from-1], "")
+	suffix := strings.Join(lines[min(to, len(lines)):], "")
+
+	if prefix != "" && !strings.HasSuffix(prefix, "\n") {
+		prefix += "\n"
+	}
+	if text != "" && suffix != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return prefix + text + suffix
+}
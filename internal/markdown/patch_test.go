@@ -0,0 +1,82 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// patchNote is a note with frontmatter, nested headings and a block ID
+const patchNote = "---\ntags: [a]\ntitle: Note\n---\n" +
+	"# Projects\n" +
+	"Overview ^overview\n" +
+	"## Alpha\n" +
+	"alpha\n" +
+	"\n" +
+	"# Archive\n" +
+	"old"
+
+// TestApplyPatchHeading tests emulating heading patches
+func TestApplyPatchHeading(t *testing.T) {
+	patched, err := ApplyPatch(patchNote, "append", "heading", "Projects", "new", "text/markdown", "::")
+	require.NoError(t, err)
+	assert.Contains(t, patched, "## Alpha\nalpha\nnew\n\n# Archive\n")
+
+	patched, err = ApplyPatch(patchNote, "prepend", "heading", "Projects::Alpha", "first\n", "text/markdown", "::")
+	require.NoError(t, err)
+	assert.Contains(t, patched, "## Alpha\nfirst\nalpha\n")
+
+	patched, err = ApplyPatch(patchNote, "replace", "heading", "Projects", "gone\n", "text/markdown", "::")
+	require.NoError(t, err)
+	assert.Contains(t, patched, "# Projects\ngone\n# Archive\n")
+
+	patched, err = ApplyPatch(patchNote, "append", "heading", "Archive", "more", "text/markdown", "::")
+	require.NoError(t, err)
+	assert.Contains(t, patched, "# Archive\nold\nmore")
+
+	_, err = ApplyPatch(patchNote, "append", "heading", "Alpha", "x", "text/markdown", "::")
+	assert.EqualError(t, err, "heading not found: Alpha")
+}
+
+// TestApplyPatchBlock tests emulating block patches
+func TestApplyPatchBlock(t *testing.T) {
+	patched, err := ApplyPatch(patchNote, "append", "block", "overview", "after", "text/markdown", "::")
+	require.NoError(t, err)
+	assert.Contains(t, patched, "Overview ^overview\nafter\n## Alpha")
+
+	patched, err = ApplyPatch(patchNote, "replace", "block", "^overview", "Summary\n", "text/markdown", "::")
+	require.NoError(t, err)
+	assert.Contains(t, patched, "# Projects\nSummary ^overview\n## Alpha")
+
+	_, err = ApplyPatch(patchNote, "append", "block", "missing", "x", "text/markdown", "::")
+	assert.EqualError(t, err, "block not found: ^missing")
+}
+
+// TestApplyPatchFrontmatter tests emulating frontmatter patches
+func TestApplyPatchFrontmatter(t *testing.T) {
+	patched, err := ApplyPatch(patchNote, "append", "frontmatter", "tags", `["b"]`, "application/json", "::")
+	require.NoError(t, err)
+	assert.Contains(t, patched, "tags:\n  - a\n  - b\n")
+
+	patched, err = ApplyPatch(patchNote, "prepend", "frontmatter", "title", "My ", "text/markdown", "::")
+	require.NoError(t, err)
+	assert.Contains(t, patched, "title: My Note\n")
+
+	patched, err = ApplyPatch(patchNote, "replace", "frontmatter", "status", `"done"`, "application/json", "::")
+	require.NoError(t, err)
+	assert.Contains(t, patched, "status: done\n")
+
+	_, err = ApplyPatch(patchNote, "append", "frontmatter", "title", "1", "application/json", "::")
+	assert.EqualError(t, err, "cannot append a non-text value to text property title")
+
+	_, err = ApplyPatch(patchNote, "insert", "frontmatter", "title", "x", "text/markdown", "::")
+	assert.EqualError(t, err, "unsupported operation: insert")
+}
+
+// TestTrimBlockID tests removing the block ID from a line
+func TestTrimBlockID(t *testing.T) {
+	assert.Equal(t, "- [x] Task\n", TrimBlockID("- [x] Task ^task-1\n"))
+	assert.Equal(t, "- [x] Task", TrimBlockID("- [x] Task ^task-1"))
+	assert.Equal(t, "Costs ^5 less\n", TrimBlockID("Costs ^5 less\n"))
+}
@@ -0,0 +1,67 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ReplaceOptions control how ReplaceText rewrites a note
+type ReplaceOptions struct {
+	// Literal inserts the replacement verbatim instead of expanding $1 and
+	// ${name} references to capture groups
+	Literal bool
+	// SkipCode leaves fenced code blocks and inline code spans untouched
+	SkipCode bool
+	// SkipFrontmatter leaves the YAML frontmatter untouched
+	SkipFrontmatter bool
+}
+
+// ReplaceText replaces the matches of pattern in a note with replacement and
+// returns the new content with the number of replacements made. Matches
+// overlapping a skipped part of the note are left as they are.
+func ReplaceText(content string, pattern *regexp.Regexp, replacement string, options ReplaceOptions) (string, int) {
+	var skipped [][2]int
+	if options.SkipFrontmatter {
+		if _, body := SplitFrontmatter(content); len(body) < len(content) {
+			skipped = append(skipped, [2]int{0, len(content) - len(body)})
+		}
+	}
+	if options.SkipCode {
+		skipped = append(skipped, codeRanges(content)...)
+	}
+
+	var b strings.Builder
+	last, count := 0, 0
+	for _, m := range pattern.FindAllStringSubmatchIndex(content, -1) {
+		if overlapsRanges(skipped, m[0], m[1]) {
+			continue
+		}
+		b.WriteString(content[last:m[0]])
+		if options.Literal {
+			b.WriteString(replacement)
+		} else {
+			b.Write(pattern.ExpandString(nil, replacement, content, m))
+		}
+		last = m[1]
+		count++
+	}
+	if count == 0 {
+		return content, 0
+	}
+	b.WriteString(content[last:])
+	return b.String(), count
+}
+
+// overlapsRanges reports whether the byte range start..end shares a byte
+// with one of the ranges, or for an empty range whether it lies inside one
+func overlapsRanges(ranges [][2]int, start, end int) bool {
+	if start == end {
+		return inRanges(ranges, start)
+	}
+	for _, r := range ranges {
+		if start < r[1] && end > r[0] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package markdown
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReplaceText tests replacing matches with and without skipping code and
+// frontmatter
+func TestReplaceText(t *testing.T) {
+	content := "---\nstatus: draft\n---\ndraft one, `draft` two\n```\ndraft\n```\nlast draft"
+
+	tests := []struct {
+		name        string
+		pattern     string
+		replacement string
+		options     ReplaceOptions
+		expected    string
+		count       int
+	}{
+		{
+			name:        "everywhere",
+			pattern:     `draft`,
+			replacement: "final",
+			expected:    "---\nstatus: final\n---\nfinal one, `final` two\n```\nfinal\n```\nlast final",
+			count:       5,
+		},
+		{
+			name:        "skip code and frontmatter",
+			pattern:     `draft`,
+			replacement: "final",
+			options:     ReplaceOptions{SkipCode: true, SkipFrontmatter: true},
+			expected:    "---\nstatus: draft\n---\nfinal one, `draft` two\n```\ndraft\n```\nlast final",
+			count:       2,
+		},
+		{
+			name:        "capture groups",
+			pattern:     `(\w+) (draft)`,
+			replacement: "${2}-$1",
+			options:     ReplaceOptions{SkipCode: true},
+			expected:    "---\nstatus: draft\n---\ndraft one, `draft` two\n```\ndraft\n```\ndraft-last",
+			count:       1,
+		},
+		{
+			name:        "literal replacement",
+			pattern:     regexp.QuoteMeta("last draft"),
+			replacement: "$1 costs $5",
+			options:     ReplaceOptions{Literal: true},
+			expected:    "---\nstatus: draft\n---\ndraft one, `draft` two\n```\ndraft\n```\n$1 costs $5",
+			count:       1,
+		},
+		{
+			name:        "no match",
+			pattern:     `missing`,
+			replacement: "x",
+			expected:    content,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, count := ReplaceText(content, regexp.MustCompile(tt.pattern), tt.replacement, tt.options)
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, tt.count, count)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package markdown
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// tagPattern matches an inline #tag, including nested tags such as
+	// #project/alpha
+	tagPattern = regexp.MustCompile(`#([\p{L}\p{N}\p{M}_/-]+)`)
+	// tagNamePattern matches a complete tag name without its leading #
+	tagNamePattern = regexp.MustCompile(`^[\p{L}\p{N}\p{M}_/-]+$`)
+	// tagSeparatorPattern splits a frontmatter tags string into tags
+	tagSeparatorPattern = regexp.MustCompile(`[^,\s]+`)
+)
+
+// Tag is an inline tag found in the body of a note
+type Tag struct {
+	// Name is the tag without its leading #
+	Name string
+	// Start and End are the byte offsets of the tag, including the #
+	Start, End int
+	// Line is the 1-based line of the tag
+	Line int
+}
+
+// ParseTags returns the inline tags of a note in order of appearance. Tags in
+// the frontmatter, code blocks and inline code are ignored, as are numeric
+// tags and # signs not preceded by whitespace, such as heading links.
+func ParseTags(content string) []Tag {
+	_, body := SplitFrontmatter(content)
+	bodyStart := len(content) - len(body)
+	code := codeRanges(content)
+
+	var tags []Tag
+	for _, m := range tagPattern.FindAllStringSubmatchIndex(content[bodyStart:], -1) {
+		start, end := bodyStart+m[0], bodyStart+m[1]
+		name := content[bodyStart+m[2] : bodyStart+m[3]]
+		if inRanges(code, start) || !IsValidTag(name) {
+			continue
+		}
+		if before, _ := utf8.DecodeLastRuneInString(content[:start]); start > 0 && !unicode.IsSpace(before) {
+			continue
+		}
+		tags = append(tags, Tag{
+			Name:  name,
+			Start: start,
+			End:   end,
+			Line:  strings.Count(content[:start], "\n") + 1,
+		})
+	}
+	return tags
+}
+
+// IsValidTag reports whether name, without its leading #, is a tag Obsidian
+// recognizes. Tags cannot be purely numeric.
+func IsValidTag(name string) bool {
+	return tagNamePattern.MatchString(name) && strings.ContainsFunc(name, func(r rune) bool { return !unicode.IsDigit(r) })
+}
+
+// ReplaceTags rewrites the tags of a note, both inline tags and the tags
+// listed in the frontmatter tags property. The replace function receives a tag
+// without its leading # and returns its new name and whether it should be
+// replaced. The rest of the note, including the formatting and comments of
+// the frontmatter, is left untouched.
+func ReplaceTags(content string, replace func(name string) (string, bool)) string {
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+
+	for _, tag := range ParseTags(content) {
+		if name, ok := replace(tag.Name); ok {
+			edits = append(edits, edit{tag.Start, tag.End, "#" + name})
+		}
+	}
+
+	for _, span := range frontmatterTagSpans(content) {
+		value := content[span[0]:span[1]]
+		updated := tagSeparatorPattern.ReplaceAllStringFunc(value, func(tag string) string {
+			hash := ""
+			if strings.HasPrefix(tag, "#") {
+				hash, tag = "#", tag[1:]
+			}
+			if name, ok := replace(tag); ok {
+				return hash + name
+			}
+			return hash + tag
+		})
+		if updated != value {
+			edits = append(edits, edit{span[0], span[1], updated})
+		}
+	}
+
+	if len(edits) == 0 {
+		return content
+	}
+	slices.SortFunc(edits, func(a, b edit) int { return a.start - b.start })
+
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		b.WriteString(content[last:e.start])
+		b.WriteString(e.text)
+		last = e.end
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// frontmatterTagSpans returns the byte ranges of the values of the frontmatter
+// tags property, one per list item. Values that span several lines or contain
+// escapes are skipped rather than risk corrupting the YAML.
+func frontmatterTagSpans(content string) [][2]int {
+	frontmatter, body := SplitFrontmatter(content)
+	if frontmatter == "" && body == content {
+		return nil
+	}
+	start := strings.IndexByte(content, '\n') + 1
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatter), &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var values []*yaml.Node
+	mapping := doc.Content[0].Content
+	for i := 0; i+1 < len(mapping); i += 2 {
+		if key := strings.ToLower(mapping[i].Value); key != "tags" && key != "tag" {
+			continue
+		}
+		switch value := mapping[i+1]; value.Kind {
+		case yaml.ScalarNode:
+			values = append(values, value)
+		case yaml.SequenceNode:
+			for _, item := range value.Content {
+				if item.Kind == yaml.ScalarNode {
+					values = append(values, item)
+				}
+			}
+		}
+	}
+
+	lines := strings.SplitAfter(frontmatter, "\n")
+	var spans [][2]int
+	for _, value := range values {
+		if value.Value == "" || value.Line < 1 || value.Line > len(lines) {
+			continue
+		}
+		offset := start
+		for _, line := range lines[:value.Line-1] {
+			offset += len(line)
+		}
+
+		// Columns count characters, not bytes
+		line := lines[value.Line-1]
+		column := 0
+		for range value.Column - 1 {
+			if column >= len(line) {
+				break
+			}
+			_, size := utf8.DecodeRuneInString(line[column:])
+			column += size
+		}
+		if value.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+			column++
+		}
+		if column > len(line) || !strings.HasPrefix(line[column:], value.Value) {
+			continue
+		}
+		spans = append(spans, [2]int{offset + column, offset + column + len(value.Value)})
+	}
+	return spans
+}
+
+// inRanges reports whether offset falls inside one of the byte ranges
+func inRanges(ranges [][2]int, offset int) bool {
+	for _, r := range ranges {
+		if offset >= r[0] && offset < r[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTags tests extracting inline tags from a note
+func TestParseTags(t *testing.T) {
+	content := "---\ntags: [skipped]\n---\n" +
+		"#project/alpha and #todo, not a#b or #123\n" +
+		"# Heading [[Note#Section]] `#code`\n" +
+		"```\n#fenced\n```\n" +
+		"(#paren) #é-tag"
+
+	tags := ParseTags(content)
+	require.Len(t, tags, 3)
+	assert.Equal(t, Tag{Name: "project/alpha", Start: 24, End: 38, Line: 4}, tags[0])
+	assert.Equal(t, "todo", tags[1].Name)
+	assert.Equal(t, "é-tag", tags[2].Name)
+	assert.Equal(t, 9, tags[2].Line)
+}
+
+// TestIsValidTag tests recognizing valid tag names
+func TestIsValidTag(t *testing.T) {
+	assert.True(t, IsValidTag("project/alpha"))
+	assert.True(t, IsValidTag("2024-review"))
+	assert.False(t, IsValidTag("2024"))
+	assert.False(t, IsValidTag("has space"))
+	assert.False(t, IsValidTag(""))
+}
+
+// TestReplaceTags tests rewriting inline and frontmatter tags
+func TestReplaceTags(t *testing.T) {
+	rename := func(name string) (string, bool) {
+		if name == "old" || strings.HasPrefix(name, "old/") {
+			return "new" + strings.TrimPrefix(name, "old"), true
+		}
+		return "", false
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "inline",
+			content:  "#old and #old/child but not #older or `#old`",
+			expected: "#new and #new/child but not #older or `#old`",
+		},
+		{
+			name:     "frontmatter flow list",
+			content:  "---\ntitle: Note # keep\ntags: [old, \"#old/child\", other]\n---\n#old",
+			expected: "---\ntitle: Note # keep\ntags: [new, \"#new/child\", other]\n---\n#new",
+		},
+		{
+			name:     "frontmatter block list",
+			content:  "---\ntags:\n  - other\n  - old # comment\n  - 'old/child'\n---\nBody",
+			expected: "---\ntags:\n  - other\n  - new # comment\n  - 'new/child'\n---\nBody",
+		},
+		{
+			name:     "frontmatter string",
+			content:  "---\ntag: old, other old/child\n---\n",
+			expected: "---\ntag: new, other new/child\n---\n",
+		},
+		{
+			name:     "unrelated property",
+			content:  "---\naliases: [old]\n---\nold",
+			expected: "---\naliases: [old]\n---\nold",
+		},
+		{
+			name:     "multibyte characters before the value",
+			content:  "---\ntags: [\"été\", old]\n---\n",
+			expected: "---\ntags: [\"été\", new]\n---\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ReplaceTags(tt.content, rename))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// taskPattern matches a checkbox list item such as "- [ ] task" or
+	// "1. [x] task", capturing the text before the status, the status and the
+	// task text
+	taskPattern = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+\[)(.)\]\s+(.*?)\s*$`)
+	// tasksDuePattern matches a due date in the Tasks plugin emoji format
+	tasksDuePattern = regexp.MustCompile(`📅\s*(\d{4}-\d{2}-\d{2})`)
+	// dataviewDuePattern matches a due date written as a Dataview inline field
+	dataviewDuePattern = regexp.MustCompile(`[\[(]due::\s*(\d{4}-\d{2}-\d{2})\s*[\])]`)
+	// blockIDPattern matches a block ID at the end of a line
+	blockIDPattern = regexp.MustCompile(`\s\^([A-Za-z0-9-]+)$`)
+)
+
+// Task statuses derived from the checkbox character
+const (
+	TaskOpen       = "open"
+	TaskDone       = "done"
+	TaskInProgress = "in_progress"
+	TaskCancelled  = "cancelled"
+	TaskOther      = "other"
+)
+
+// Task is a checkbox list item of a note
+type Task struct {
+	// Line is the 1-based line of the task
+	Line int `json:"line"`
+	// Status is open, done, in_progress, cancelled or other
+	Status string `json:"status"`
+	// Symbol is the character between the brackets
+	Symbol string `json:"symbol"`
+	// Text is the task without its list marker and checkbox
+	Text string `json:"text"`
+	// Due is the due date in YYYY-MM-DD form, from a 📅 emoji or a
+	// [due:: ] inline field
+	Due     string   `json:"due,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	BlockID string   `json:"blockId,omitempty"`
+}
+
+// ParseTasks returns the tasks of a note in order of appearance. Checkboxes
+// in the frontmatter and in code blocks are ignored.
+func ParseTasks(content string) []Task {
+	_, body := SplitFrontmatter(content)
+	bodyStart := len(content) - len(body)
+	code := codeRanges(content)
+
+	var tasks []Task
+	offset := 0
+	for i, line := range strings.SplitAfter(content, "\n") {
+		start := offset
+		offset += len(line)
+		if start < bodyStart || inRanges(code, start) {
+			continue
+		}
+		if task, ok := parseTask(strings.TrimRight(line, "\r\n")); ok {
+			task.Line = i + 1
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+// parseTask parses a single line as a task
+func parseTask(line string) (Task, bool) {
+	m := taskPattern.FindStringSubmatch(line)
+	if m == nil {
+		return Task{}, false
+	}
+
+	task := Task{Symbol: m[2], Status: taskStatus(m[2]), Text: m[3]}
+	if due := tasksDuePattern.FindStringSubmatch(task.Text); due != nil {
+		task.Due = due[1]
+	} else if due := dataviewDuePattern.FindStringSubmatch(task.Text); due != nil {
+		task.Due = due[1]
+	}
+	if block := blockIDPattern.FindStringSubmatch(task.Text); block != nil {
+		task.BlockID = block[1]
+	}
+	for _, tag := range ParseTags(task.Text) {
+		task.Tags = append(task.Tags, tag.Name)
+	}
+	return task, true
+}
+
+// taskStatus maps a checkbox character to a status
+func taskStatus(symbol string) string {
+	switch symbol {
+	case " ":
+		return TaskOpen
+	case "x", "X":
+		return TaskDone
+	case "/":
+		return TaskInProgress
+	case "-":
+		return TaskCancelled
+	default:
+		return TaskOther
+	}
+}
+
+// SetTaskStatus replaces the checkbox character of the task on a 1-based line,
+// leaving the rest of the note untouched
+func SetTaskStatus(content string, line int, symbol string) (string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	if line < 1 || line > len(lines) {
+		return "", fmt.Errorf("line %d is out of range", line)
+	}
+	m := taskPattern.FindStringSubmatchIndex(strings.TrimRight(lines[line-1], "\r\n"))
+	if m == nil {
+		return "", fmt.Errorf("line %d is not a task", line)
+	}
+	lines[line-1] = lines[line-1][:m[4]] + symbol + lines[line-1][m[5]:]
+	return strings.Join(lines, ""), nil
+}
+
+// FormatTask returns an open task list item with an optional due date in the
+// Tasks plugin emoji format
+func FormatTask(text, due string) string {
+	task := "- [ ] " + strings.TrimSpace(text)
+	if due != "" {
+		task += " 📅 " + due
+	}
+	return task
+}
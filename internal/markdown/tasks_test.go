@@ -0,0 +1,61 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTasks tests extracting checkbox tasks from a note
+func TestParseTasks(t *testing.T) {
+	content := "---\nchecklist: - [ ] not a task\n---\n" +
+		"- [ ] Write report #work 📅 2024-05-01 ^report\n" +
+		"  * [x] Done item [due:: 2024-04-01]\n" +
+		"1. [/] Numbered in progress\n" +
+		"- [-] Cancelled\n" +
+		"- plain item\n" +
+		"```\n- [ ] in code\n```\n" +
+		"+ [?] Question\r\n"
+
+	tasks := ParseTasks(content)
+	require.Len(t, tasks, 5)
+
+	assert.Equal(t, Task{
+		Line:    4,
+		Status:  TaskOpen,
+		Symbol:  " ",
+		Text:    "Write report #work 📅 2024-05-01 ^report",
+		Due:     "2024-05-01",
+		Tags:    []string{"work"},
+		BlockID: "report",
+	}, tasks[0])
+
+	assert.Equal(t, TaskDone, tasks[1].Status)
+	assert.Equal(t, "2024-04-01", tasks[1].Due)
+	assert.Equal(t, TaskInProgress, tasks[2].Status)
+	assert.Equal(t, TaskCancelled, tasks[3].Status)
+	assert.Equal(t, TaskOther, tasks[4].Status)
+	assert.Equal(t, "Question", tasks[4].Text)
+	assert.Equal(t, 12, tasks[4].Line)
+}
+
+// TestSetTaskStatus tests toggling the checkbox of a task
+func TestSetTaskStatus(t *testing.T) {
+	content := "# Tasks\n- [ ] First\n  - [ ] Nested ^id\nText"
+
+	updated, err := SetTaskStatus(content, 3, "x")
+	require.NoError(t, err)
+	assert.Equal(t, "# Tasks\n- [ ] First\n  - [x] Nested ^id\nText", updated)
+
+	_, err = SetTaskStatus(content, 4, "x")
+	assert.EqualError(t, err, "line 4 is not a task")
+	_, err = SetTaskStatus(content, 9, "x")
+	assert.EqualError(t, err, "line 9 is out of range")
+}
+
+// TestFormatTask tests building a new task line
+func TestFormatTask(t *testing.T) {
+	assert.Equal(t, "- [ ] Call Bob", FormatTask(" Call Bob ", ""))
+	assert.Equal(t, "- [ ] Call Bob 📅 2024-05-01", FormatTask("Call Bob", "2024-05-01"))
+}
@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/audit"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+)
+
+// WithAuditLog records every tool call in an audit log
+func WithAuditLog(logger *audit.Logger) Option {
+	return func(s *MCPServer) {
+		s.audit = logger
+	}
+}
+
+// auditedExecuteTool executes a tool and records the call in the audit log.
+// The content of the file a mutating tool targets is hashed before and
+// after the call; tools changing several files record the hashes of each
+// file they rewrite.
+func (s *MCPServer) auditedExecuteTool(ctx context.Context, name string, params map[string]any) (*toolResult, error) {
+	if s.audit == nil {
+		return s.executeTool(ctx, name, params)
+	}
+
+	sess := s.sessionFor(ctx)
+	entry := audit.Entry{
+		Time:      time.Now().UTC(),
+		Session:   sess.id,
+		Client:    sess.getClientInfo(),
+		Tool:      name,
+		Arguments: audit.Redact(params),
+		Path:      targetPath(params),
+	}
+
+	hashed := mutatingTools[name] && s.policy.check(name, params) == nil
+	var changes obsidian.ChangeLog
+	if hashed {
+		if path, hash, ok := s.hashTarget(ctx, name, params, false); ok {
+			entry.Path, entry.HashBefore = path, hash
+		} else {
+			ctx = obsidian.WithChangeLog(ctx, &changes)
+		}
+	}
+
+	start := time.Now()
+	result, err := s.executeTool(ctx, name, params)
+	entry.DurationMs = time.Since(start).Milliseconds()
+
+	switch {
+	case ctx.Err() != nil:
+		entry.Outcome = audit.OutcomeCancelled
+	case err != nil:
+		entry.Outcome = audit.OutcomeError
+		entry.Error = err.Error()
+	default:
+		entry.Outcome = audit.OutcomeSuccess
+	}
+
+	if hashed && err == nil {
+		if _, hash, ok := s.hashTarget(ctx, name, params, true); ok {
+			entry.HashAfter = hash
+		}
+	}
+	for _, change := range changes.Changes() {
+		entry.Files = append(entry.Files, audit.FileHashes(change))
+	}
+
+	if logErr := s.audit.Log(entry); logErr != nil {
+		fmt.Fprintf(s.stderr, "Failed to write audit log: %v\n", logErr)
+	}
+	return result, err
+}
+
+// targetPath returns the vault path named by the arguments of a tool call
+func targetPath(params map[string]any) string {
+	for _, key := range []string{"filename", "source", "path"} {
+		if path, ok := params[key].(string); ok && path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// hashTarget hashes the content of the single file a mutating tool changes.
+// For move_file the source is read before the call and the destination
+// after it. The hash is empty when the file does not exist; ok is false when
+// the tool has no single target or it cannot be read.
+func (s *MCPServer) hashTarget(ctx context.Context, name string, params map[string]any, after bool) (path, hash string, ok bool) {
+	var read func() (*obsidian.Note, error)
+	switch {
+	case name == "move_file":
+		key := "source"
+		if after {
+			key = "destination"
+		}
+		path, _ = params[key].(string)
+	case strings.HasSuffix(name, "_periodic_note"):
+		period, _ := params["period"].(string)
+		date, _ := params["date"].(string)
+		read = func() (*obsidian.Note, error) { return s.obsidianClient.GetPeriodicNoteJSON(ctx, period, date) }
+	case strings.HasSuffix(name, "_active_file"):
+		read = func() (*obsidian.Note, error) { return s.obsidianClient.GetActiveFileJSON(ctx) }
+	default:
+		path, _ = params["filename"].(string)
+	}
+
+	if read == nil {
+		if path == "" {
+			return "", "", false
+		}
+		read = func() (*obsidian.Note, error) {
+			content, err := s.obsidianClient.GetFileContent(ctx, path)
+			return &obsidian.Note{Path: path, Content: content}, err
+		}
+	}
+
+	note, err := read()
+	var apiErr *obsidian.APIError
+	if errors.As(err, &apiErr) && apiErr.NotFound() {
+		return path, "", true
+	}
+	if err != nil {
+		return "", "", false
+	}
+	return note.Path, obsidian.ContentHash(note.Content), true
+}
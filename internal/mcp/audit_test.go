@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/audit"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleToolsCallAuditLog tests that tool calls are recorded with the
+// client, outcome and content hashes
+func TestHandleToolsCallAuditLog(t *testing.T) {
+	files := map[string]string{"notes/a.md": "hello"}
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/vault/")
+		content, ok := files[name]
+		switch {
+		case !ok:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errorCode": 40400, "message": "File not found"}`))
+		case r.Method == "POST":
+			body, _ := io.ReadAll(r.Body)
+			files[name] = content + string(body)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			_, _ = w.Write([]byte(content))
+		}
+	}))
+	defer vault.Close()
+
+	filename := filepath.Join(t.TempDir(), "audit.jsonl")
+	logger, err := audit.Open(filename)
+	require.NoError(t, err)
+	defer logger.Close()
+
+	server := NewMCPServer("test-token", vault.URL, WithAuditLog(logger))
+	ctx := context.Background()
+	server.handleRequest(ctx, &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params:  map[string]any{"clientInfo": map[string]any{"name": "nightly-agent", "version": "2.0"}},
+	})
+	call := func(name string, args map[string]any) {
+		t.Helper()
+		response := server.handleRequest(ctx, &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      2,
+			Method:  "tools/call",
+			Params:  map[string]any{"name": name, "arguments": args},
+		})
+		require.NotNil(t, response)
+	}
+	call("append_to_file", map[string]any{"filename": "notes/a.md", "content": " world"})
+	call("get_file_content", map[string]any{"filename": "missing.md"})
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var entry audit.Entry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, map[string]any{"name": "nightly-agent", "version": "2.0"}, entry.Client)
+	assert.Equal(t, "append_to_file", entry.Tool)
+	assert.Equal(t, map[string]any{"filename": "notes/a.md", "content": "[redacted 6 bytes]"}, entry.Arguments)
+	assert.Equal(t, "notes/a.md", entry.Path)
+	assert.Equal(t, audit.OutcomeSuccess, entry.Outcome)
+	assert.Equal(t, obsidian.ContentHash("hello"), entry.HashBefore)
+	assert.Equal(t, obsidian.ContentHash("hello world"), entry.HashAfter)
+
+	entry = audit.Entry{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "get_file_content", entry.Tool)
+	assert.Equal(t, "missing.md", entry.Path)
+	assert.Equal(t, audit.OutcomeError, entry.Outcome)
+	assert.Contains(t, entry.Error, "File not found")
+	assert.Empty(t, entry.HashBefore)
+}
+
+// TestAuditLogFileHashes tests that tools changing several notes record the
+// hashes of each note and keep the search text out of the log
+func TestAuditLogFileHashes(t *testing.T) {
+	notes := map[string]string{"a.md": "Alice wrote", "b.md": "Alice read", "c.md": "Bob"}
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/vault/")
+		switch {
+		case r.URL.Path == "/search/simple/":
+			var results []obsidian.SearchResult
+			for file, content := range notes {
+				if strings.Contains(content, r.URL.Query().Get("query")) {
+					results = append(results, obsidian.SearchResult{Filename: file})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(results)
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			notes[name] = string(body)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			_ = json.NewEncoder(w).Encode(obsidian.Note{Path: name, Content: notes[name], Stat: obsidian.NoteStat{Mtime: int64(len(notes[name]))}})
+		}
+	}))
+	defer vault.Close()
+
+	filename := filepath.Join(t.TempDir(), "audit.jsonl")
+	logger, err := audit.Open(filename)
+	require.NoError(t, err)
+	defer logger.Close()
+
+	server := NewMCPServer("test-token", vault.URL, WithAuditLog(logger))
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  map[string]any{"name": "replace_in_vault", "arguments": map[string]any{"pattern": "Alice", "replacement": "Carol"}},
+	})
+	require.NotNil(t, response)
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	var entry audit.Entry
+	require.NoError(t, json.Unmarshal(data, &entry))
+	assert.Equal(t, audit.OutcomeSuccess, entry.Outcome)
+	assert.Equal(t, "[redacted 5 bytes]", entry.Arguments["pattern"])
+	assert.NotContains(t, string(data), "Carol")
+	assert.ElementsMatch(t, []audit.FileHashes{
+		{Path: "a.md", HashBefore: obsidian.ContentHash("Alice wrote"), HashAfter: obsidian.ContentHash("Carol wrote")},
+		{Path: "b.md", HashBefore: obsidian.ContentHash("Alice read"), HashAfter: obsidian.ContentHash("Carol read")},
+	}, entry.Files)
+}
@@ -0,0 +1,419 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// httpEndpoint is the path the Streamable HTTP transport is served on
+	httpEndpoint = "/mcp"
+	// sessionHeader carries the session ID between client and server
+	sessionHeader = "Mcp-Session-Id"
+	// maxRequestBodySize limits the size of a single POSTed message
+	maxRequestBodySize = 10 << 20
+	// eventBacklog is the number of server-initiated messages buffered per
+	// session while no event stream is reading them
+	eventBacklog = 64
+	// defaultSessionIdleTimeout is how long a session may go unused before
+	// it is closed
+	defaultSessionIdleTimeout = 30 * time.Minute
+)
+
+// WithHTTPAuthToken makes the HTTP transport require an Authorization header
+// carrying the token as a bearer token. An empty token disables the check.
+func WithHTTPAuthToken(token string) Option {
+	return func(s *MCPServer) {
+		s.httpToken = token
+	}
+}
+
+// WithAllowedHosts adds host names HTTP requests may be addressed to.
+// Loopback names and IP addresses are always allowed.
+func WithAllowedHosts(hosts ...string) Option {
+	return func(s *MCPServer) {
+		s.allowedHosts = append(s.allowedHosts, hosts...)
+	}
+}
+
+// WithSessionIdleTimeout sets how long an HTTP session may go without
+// requests before it is closed. Zero keeps sessions until they are deleted.
+func WithSessionIdleTimeout(timeout time.Duration) Option {
+	return func(s *MCPServer) {
+		s.sessionIdleTimeout = timeout
+	}
+}
+
+// httpTransport serves the MCP Streamable HTTP transport. Clients POST
+// JSON-RPC messages and receive server-initiated messages over a GET event
+// stream, with each client identified by the Mcp-Session-Id header.
+type httpTransport struct {
+	server *MCPServer
+	// now returns the current time, for expiring idle sessions
+	now func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+// httpSession is a session whose server-initiated messages are delivered
+// over an SSE stream
+type httpSession struct {
+	*session
+	events chan []byte
+	done   chan struct{}
+
+	// lastUsed is when the session last received a request and streams is
+	// the number of open event streams, both guarded by the transport mutex
+	lastUsed time.Time
+	streams  int
+}
+
+// newHTTPTransport creates the Streamable HTTP transport of a server
+func newHTTPTransport(s *MCPServer) *httpTransport {
+	return &httpTransport{
+		server:   s,
+		now:      time.Now,
+		sessions: make(map[string]*httpSession),
+	}
+}
+
+// HTTPHandler returns an http.Handler serving MCP over Streamable HTTP
+func (s *MCPServer) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(httpEndpoint, newHTTPTransport(s))
+	return mux
+}
+
+// RunHTTP starts the MCP server on the given address using the Streamable
+// HTTP transport
+func (s *MCPServer) RunHTTP(addr string) error {
+	if host, _, err := net.SplitHostPort(addr); err == nil && host != "" {
+		s.allowedHosts = append(s.allowedHosts, host)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.pollSubscriptions(ctx)
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.HTTPHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return httpServer.ListenAndServe()
+}
+
+// ServeHTTP dispatches requests to the MCP endpoint by method
+func (t *httpTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !t.validHost(r) {
+		http.Error(w, "Forbidden: invalid host", http.StatusForbidden)
+		return
+	}
+	if !validOrigin(r) {
+		http.Error(w, "Forbidden: invalid origin", http.StatusForbidden)
+		return
+	}
+	if !t.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		t.handlePost(w, r)
+	case http.MethodGet:
+		t.handleGet(w, r)
+	case http.MethodDelete:
+		t.handleDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// validHost guards against DNS rebinding by only accepting requests
+// addressed to a loopback name, an IP address or an allowed host name. A
+// rebound name reaches the server with the attacker's host name, which none
+// of these match.
+func (t *httpTransport) validHost(r *http.Request) bool {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	if strings.EqualFold(host, "localhost") || net.ParseIP(host) != nil {
+		return true
+	}
+	return slices.ContainsFunc(t.server.allowedHosts, func(allowed string) bool {
+		return strings.EqualFold(host, allowed)
+	})
+}
+
+// validOrigin rejects cross-origin browser requests, whose Origin does not
+// match the host they were sent to
+func validOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return u.Host == r.Host
+}
+
+// authorized reports whether the request carries the bearer token the
+// transport requires, if any
+func (t *httpTransport) authorized(r *http.Request) bool {
+	if t.server.httpToken == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(t.server.httpToken)) == 1
+}
+
+// handlePost processes one JSON-RPC message or a batch of them
+func (t *httpTransport) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	batch := len(body) > 0 && body[0] == '['
+	var requests []MCPRequest
+	if batch {
+		err = json.Unmarshal(body, &requests)
+	} else {
+		requests = make([]MCPRequest, 1)
+		err = json.Unmarshal(body, &requests[0])
+	}
+	if err != nil {
+		t.writeJSON(w, http.StatusBadRequest, &MCPResponse{
+			JSONRPC: "2.0",
+			Error: &MCPError{
+				Code:    -32700,
+				Message: "Parse error",
+				Data:    err.Error(),
+			},
+		})
+		return
+	}
+
+	var sess *httpSession
+	if !batch && requests[0].Method == "initialize" {
+		sess, err = t.newSession()
+		if err != nil {
+			http.Error(w, "Failed to create session", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set(sessionHeader, sess.id)
+	} else if sess = t.sessionFromRequest(w, r); sess == nil {
+		return
+	}
+
+	ctx := withSession(r.Context(), sess.session)
+	var responses []*MCPResponse
+	for i := range requests {
+		request := &requests[i]
+		// Responses from the client carry no method and need no handling
+		if request.Method == "" {
+			continue
+		}
+		// Notifications and cancelled requests produce no response
+		if response := t.server.handleRequest(ctx, request); response != nil {
+			responses = append(responses, response)
+		}
+	}
+
+	switch {
+	case len(responses) == 0:
+		w.WriteHeader(http.StatusAccepted)
+	case batch:
+		t.writeJSON(w, http.StatusOK, responses)
+	default:
+		t.writeJSON(w, http.StatusOK, responses[0])
+	}
+}
+
+// handleGet opens an SSE stream for server-initiated messages
+func (t *httpTransport) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		http.Error(w, "Not acceptable: client must accept text/event-stream", http.StatusNotAcceptable)
+		return
+	}
+
+	sess := t.sessionFromRequest(w, r)
+	if sess == nil {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	t.mu.Lock()
+	sess.streams++
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		sess.streams--
+		sess.lastUsed = t.now()
+		t.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sess.done:
+			return
+		case data := <-sess.events:
+			if _, err := fmt.Fprintf(w, "event: message\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// handleDelete terminates a session
+func (t *httpTransport) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(sessionHeader)
+	if id == "" {
+		http.Error(w, "Bad request: missing "+sessionHeader+" header", http.StatusBadRequest)
+		return
+	}
+
+	t.mu.Lock()
+	found := t.closeSession(id)
+	t.mu.Unlock()
+	if !found {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// closeSession unregisters a session and ends its event streams. It reports
+// whether the session existed. The caller must hold t.mu.
+func (t *httpTransport) closeSession(id string) bool {
+	sess, ok := t.sessions[id]
+	if !ok {
+		return false
+	}
+	delete(t.sessions, id)
+	t.server.removeSession(id)
+	close(sess.done)
+	return true
+}
+
+// expireSessions closes the sessions that have been idle for longer than
+// the idle timeout. The caller must hold t.mu.
+func (t *httpTransport) expireSessions() {
+	timeout := t.server.sessionIdleTimeout
+	if timeout <= 0 {
+		return
+	}
+	now := t.now()
+	for id, sess := range t.sessions {
+		if sess.streams == 0 && now.Sub(sess.lastUsed) > timeout {
+			t.closeSession(id)
+		}
+	}
+}
+
+// newSession creates and registers a session with a random ID
+func (t *httpTransport) newSession() (*httpSession, error) {
+	var raw [16]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return nil, err
+	}
+
+	sess := &httpSession{
+		events:   make(chan []byte, eventBacklog),
+		done:     make(chan struct{}),
+		lastUsed: t.now(),
+	}
+	sess.session = newSession(hex.EncodeToString(raw[:]), func(data []byte) error {
+		select {
+		case sess.events <- data:
+			return nil
+		default:
+			return errors.New("event stream backlog is full")
+		}
+	})
+
+	t.mu.Lock()
+	t.expireSessions()
+	t.sessions[sess.id] = sess
+	t.mu.Unlock()
+	t.server.addSession(sess.session)
+
+	return sess, nil
+}
+
+// sessionFromRequest looks up the session named by the request headers and
+// marks it as used. It writes an error response and returns nil when the
+// session is missing, unknown or expired.
+func (t *httpTransport) sessionFromRequest(w http.ResponseWriter, r *http.Request) *httpSession {
+	id := r.Header.Get(sessionHeader)
+	if id == "" {
+		http.Error(w, "Bad request: missing "+sessionHeader+" header", http.StatusBadRequest)
+		return nil
+	}
+
+	t.mu.Lock()
+	t.expireSessions()
+	sess, ok := t.sessions[id]
+	if ok {
+		sess.lastUsed = t.now()
+	}
+	t.mu.Unlock()
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return nil
+	}
+	return sess
+}
+
+// writeJSON writes a JSON response body
+func (t *httpTransport) writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		fmt.Fprintf(t.server.stderr, "Failed to write HTTP response: %v\n", err)
+	}
+}
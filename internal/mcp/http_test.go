@@ -0,0 +1,287 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postMCP posts a JSON-RPC payload to the MCP endpoint
+func postMCP(t *testing.T, baseURL, sessionID, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, baseURL+httpEndpoint, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID != "" {
+		req.Header.Set(sessionHeader, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+// initializeHTTPSession initializes a new session and returns its ID
+func initializeHTTPSession(t *testing.T, baseURL string) string {
+	t.Helper()
+	resp := postMCP(t, baseURL, "", `{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	sessionID := resp.Header.Get(sessionHeader)
+	require.NotEmpty(t, sessionID)
+	return sessionID
+}
+
+// TestHTTPInitialize tests that initialize creates a session
+func TestHTTPInitialize(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123")
+	ts := httptest.NewServer(server.HTTPHandler())
+	defer ts.Close()
+
+	resp := postMCP(t, ts.URL, "", `{"jsonrpc": "2.0", "id": "init", "method": "initialize", "params": {}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.NotEmpty(t, resp.Header.Get(sessionHeader))
+
+	var response map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, "init", response["id"])
+	assert.Equal(t, "2024-11-05", response["result"].(map[string]any)["protocolVersion"])
+}
+
+// TestHTTPSessionRequired tests that requests need a known session
+func TestHTTPSessionRequired(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123")
+	ts := httptest.NewServer(server.HTTPHandler())
+	defer ts.Close()
+
+	resp := postMCP(t, ts.URL, "", `{"jsonrpc": "2.0", "id": 1, "method": "tools/list"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = postMCP(t, ts.URL, "unknown", `{"jsonrpc": "2.0", "id": 1, "method": "tools/list"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// TestHTTPRequestsAndNotifications tests single, batched and notification messages
+func TestHTTPRequestsAndNotifications(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123")
+	ts := httptest.NewServer(server.HTTPHandler())
+	defer ts.Close()
+
+	sessionID := initializeHTTPSession(t, ts.URL)
+
+	resp := postMCP(t, ts.URL, sessionID, `{"jsonrpc": "2.0", "method": "notifications/initialized"}`)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	resp = postMCP(t, ts.URL, sessionID, `{"jsonrpc": "2.0", "id": 2, "method": "ping"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var single map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&single))
+	assert.Equal(t, float64(2), single["id"])
+
+	resp = postMCP(t, ts.URL, sessionID, `[{"jsonrpc": "2.0", "id": 3, "method": "ping"}, {"jsonrpc": "2.0", "id": 4, "method": "tools/list"}]`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var batch []map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&batch))
+	require.Len(t, batch, 2)
+	assert.Equal(t, float64(3), batch[0]["id"])
+	assert.Contains(t, batch[1]["result"], "tools")
+
+	resp = postMCP(t, ts.URL, sessionID, `{not json`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// TestHTTPDeleteSession tests terminating a session
+func TestHTTPDeleteSession(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123")
+	ts := httptest.NewServer(server.HTTPHandler())
+	defer ts.Close()
+
+	sessionID := initializeHTTPSession(t, ts.URL)
+	assert.Len(t, server.allSessions(), 2)
+
+	req, err := http.NewRequest(http.MethodDelete, ts.URL+httpEndpoint, nil)
+	require.NoError(t, err)
+	req.Header.Set(sessionHeader, sessionID)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Len(t, server.allSessions(), 1)
+
+	resp = postMCP(t, ts.URL, sessionID, `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// TestHTTPInvalidOrigin tests that cross-origin browser requests are rejected
+func TestHTTPInvalidOrigin(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123")
+	ts := httptest.NewServer(server.HTTPHandler())
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+httpEndpoint, strings.NewReader(`{"jsonrpc": "2.0", "id": 1, "method": "initialize"}`))
+	require.NoError(t, err)
+	req.Header.Set("Origin", "http://evil.example.com")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+// TestHTTPInvalidHost tests that requests for host names that are not
+// allowed, as sent after DNS rebinding, are rejected
+func TestHTTPInvalidHost(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123", WithAllowedHosts("obsidian.lan"))
+	ts := httptest.NewServer(server.HTTPHandler())
+	defer ts.Close()
+
+	status := func(host string) int {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+httpEndpoint, strings.NewReader(`{"jsonrpc": "2.0", "id": 1, "method": "initialize"}`))
+		require.NoError(t, err)
+		req.Host = host
+		req.Header.Set("Origin", "http://"+host)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusForbidden, status("evil.example:8080"))
+	assert.Equal(t, http.StatusOK, status("localhost:8080"))
+	assert.Equal(t, http.StatusOK, status("192.168.1.20:8080"))
+	assert.Equal(t, http.StatusOK, status("[::1]:8080"))
+	assert.Equal(t, http.StatusOK, status("Obsidian.LAN:8080"))
+}
+
+// TestHTTPAuthToken tests that a configured bearer token is required
+func TestHTTPAuthToken(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123", WithHTTPAuthToken("s3cret"))
+	ts := httptest.NewServer(server.HTTPHandler())
+	defer ts.Close()
+
+	resp := postMCP(t, ts.URL, "", `{"jsonrpc": "2.0", "id": 1, "method": "initialize"}`)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "Bearer", resp.Header.Get("WWW-Authenticate"))
+
+	for token, expected := range map[string]int{"wrong": http.StatusUnauthorized, "s3cret": http.StatusOK} {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+httpEndpoint, strings.NewReader(`{"jsonrpc": "2.0", "id": 1, "method": "initialize"}`))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Equal(t, expected, resp.StatusCode, token)
+	}
+}
+
+// TestHTTPConcurrentDelete tests that deleting a session twice at once
+// closes it only once
+func TestHTTPConcurrentDelete(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123")
+	ts := httptest.NewServer(server.HTTPHandler())
+	defer ts.Close()
+
+	sessionID := initializeHTTPSession(t, ts.URL)
+	var wg sync.WaitGroup
+	var deleted atomic.Int32
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequest(http.MethodDelete, ts.URL+httpEndpoint, nil)
+			if err != nil {
+				return
+			}
+			req.Header.Set(sessionHeader, sessionID)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusNoContent {
+				deleted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), deleted.Load())
+}
+
+// TestHTTPSessionIdleTimeout tests that unused sessions expire
+func TestHTTPSessionIdleTimeout(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123", WithSessionIdleTimeout(time.Minute))
+	transport := newHTTPTransport(server)
+	now := time.Now()
+	transport.now = func() time.Time { return now }
+	ts := httptest.NewServer(transport)
+	defer ts.Close()
+
+	idle := initializeHTTPSession(t, ts.URL)
+	active := initializeHTTPSession(t, ts.URL)
+	now = now.Add(45 * time.Second)
+	assert.Equal(t, http.StatusOK, postMCP(t, ts.URL, active, `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`).StatusCode)
+
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, http.StatusNotFound, postMCP(t, ts.URL, idle, `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`).StatusCode)
+	assert.Equal(t, http.StatusOK, postMCP(t, ts.URL, active, `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`).StatusCode)
+	assert.Len(t, server.allSessions(), 2)
+}
+
+// TestHTTPEventStream tests delivery of resource notifications over SSE
+func TestHTTPEventStream(t *testing.T) {
+	var mtime atomic.Int64
+	mtime.Store(1000)
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.olrapi.note+json")
+		_, _ = fmt.Fprintf(w, `{"content": "", "path": "note.md", "stat": {"mtime": %d}}`, mtime.Load())
+	}))
+	defer vault.Close()
+
+	server := NewMCPServer("test-token", vault.URL)
+	ts := httptest.NewServer(server.HTTPHandler())
+	defer ts.Close()
+
+	sessionID := initializeHTTPSession(t, ts.URL)
+	resp := postMCP(t, ts.URL, sessionID, `{"jsonrpc": "2.0", "id": 2, "method": "resources/subscribe", "params": {"uri": "obsidian://vault/note.md"}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+httpEndpoint, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(sessionHeader, sessionID)
+	stream, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+	assert.Equal(t, "text/event-stream", stream.Header.Get("Content-Type"))
+
+	mtime.Store(2000)
+	server.checkSubscriptions(context.Background())
+
+	reader := bufio.NewReader(stream.Body)
+	var data string
+	for data == "" {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if payload, ok := strings.CutPrefix(line, "data: "); ok {
+			data = strings.TrimSpace(payload)
+		}
+	}
+
+	var notification map[string]any
+	require.NoError(t, json.Unmarshal([]byte(data), &notification))
+	assert.Equal(t, "notifications/resources/updated", notification["method"])
+	assert.Equal(t, map[string]any{"uri": "obsidian://vault/note.md"}, notification["params"])
+}
@@ -0,0 +1,16 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/links"
+)
+
+// linkGraph builds the link graph of the vault from the refreshed index
+func (s *MCPServer) linkGraph(ctx context.Context) (*links.Graph, error) {
+	vault, err := s.index.Refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return links.Build(vault.Files, vault.Contents()), nil
+}
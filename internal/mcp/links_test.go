@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/links"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLinkTools tests the link graph tools against a crawled vault
+func TestLinkTools(t *testing.T) {
+	notes := map[string]string{
+		"Home.md":  "[[Topic]] ![[image.png]] [[Ghost]]",
+		"Topic.md": "Back to [Home](Home.md)",
+		"Stray.md": "No links",
+	}
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/":
+			_, _ = w.Write([]byte(`{"files": ["Home.md", "Topic.md", "Stray.md", "image.png"]}`))
+		case "/search/":
+			_, _ = w.Write([]byte(`[{"filename": "Home.md", "result": 1}, {"filename": "Topic.md", "result": 1}, {"filename": "Stray.md", "result": 1}]`))
+		default:
+			content, ok := notes[strings.TrimPrefix(r.URL.Path, "/vault/")]
+			if !ok {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(obsidian.Note{Path: strings.TrimPrefix(r.URL.Path, "/vault/"), Content: content, Stat: obsidian.NoteStat{Mtime: 1}})
+		}
+	}))
+	defer vault.Close()
+	server := NewMCPServer("test-token", vault.URL)
+
+	call := func(name string, args map[string]any) map[string]any {
+		t.Helper()
+		response := server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      name,
+			Method:  "tools/call",
+			Params:  map[string]any{"name": name, "arguments": args},
+		})
+		require.NotNil(t, response)
+		require.Nil(t, response.Error)
+		result := response.Result.(map[string]any)
+		require.NotContains(t, result, "isError")
+		return result["structuredContent"].(map[string]any)
+	}
+
+	backlinks := call("get_backlinks", map[string]any{"filename": "Topic.md"})["backlinks"].([]links.Edge)
+	require.Len(t, backlinks, 1)
+	assert.Equal(t, "Home.md", backlinks[0].Source)
+
+	outgoing := call("get_outgoing_links", map[string]any{"filename": "Home.md"})["links"].([]links.Edge)
+	require.Len(t, outgoing, 3)
+	assert.Equal(t, "image.png", outgoing[1].Target)
+
+	assert.Equal(t, []string{"Stray.md"}, call("find_orphan_notes", nil)["orphans"])
+
+	broken := call("find_broken_links", nil)["brokenLinks"].([]links.Edge)
+	require.Len(t, broken, 1)
+	assert.Equal(t, "Ghost", broken[0].Path)
+}
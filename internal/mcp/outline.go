@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/markdown"
+)
+
+// noteOutline is the result of get_note_outline
+type noteOutline struct {
+	Path string `json:"path"`
+	*markdown.Outline
+}
+
+// noteSection reads the section of a note below a heading path delimited like
+// the PATCH Target header. The section is returned as markdown text, without
+// the heading line unless includeHeading is set, with its line range as
+// structured content.
+func (s *MCPServer) noteSection(ctx context.Context, filename, headingPath, delimiter string, includeSubsections, includeHeading bool) (*toolResult, error) {
+	content, err := s.obsidianClient.GetFileContent(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	section, err := markdown.ParseOutline(content).FindSection(strings.Split(headingPath, delimiter))
+	if err != nil {
+		return nil, err
+	}
+	start, end := section.StartLine, section.EndLine
+	if !includeHeading {
+		start++
+	}
+	if !includeSubsections && len(section.Children) > 0 {
+		end = section.Children[0].StartLine - 1
+	}
+
+	text := markdown.Lines(content, start, end)
+	return &toolResult{
+		text: text,
+		structured: map[string]any{
+			"path":      filename,
+			"heading":   section.Heading,
+			"startLine": start,
+			"endLine":   end,
+			"content":   text,
+		},
+	}, nil
+}
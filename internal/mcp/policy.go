@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+)
+
+// mutatingTools lists the tools that change the vault or run commands. They
+// are disabled in read-only mode.
+var mutatingTools = map[string]bool{
+	"create_or_update_file":    true,
+	"append_to_file":           true,
+	"patch_file_content":       true,
+	"delete_file":              true,
+	"upload_attachment":        true,
+	"move_file":                true,
+	"restore_snapshot":         true,
+	"undo_last_change":         true,
+	"set_frontmatter_field":    true,
+	"delete_frontmatter_field": true,
+	"bulk_update_frontmatter":  true,
+	"rename_tag":               true,
+	"merge_tags":               true,
+	"replace_in_vault":         true,
+	"complete_task":            true,
+	"add_task":                 true,
+	"execute_command":          true,
+	"update_periodic_note":     true,
+	"append_to_periodic_note":  true,
+	"patch_periodic_note":      true,
+	"delete_periodic_note":     true,
+	"update_active_file":       true,
+	"append_to_active_file":    true,
+	"patch_active_file":        true,
+	"delete_active_file":       true,
+}
+
+// destructiveTools lists the tools that delete, move or overwrite notes,
+// rewrite many notes at once or run arbitrary commands. They require a
+// confirm argument when the policy asks for it.
+var destructiveTools = map[string]bool{
+	"create_or_update_file":   true,
+	"delete_file":             true,
+	"upload_attachment":       true,
+	"move_file":               true,
+	"bulk_update_frontmatter": true,
+	"rename_tag":              true,
+	"merge_tags":              true,
+	"replace_in_vault":        true,
+	"restore_snapshot":        true,
+	"undo_last_change":        true,
+	"execute_command":         true,
+	"update_periodic_note":    true,
+	"delete_periodic_note":    true,
+	"update_active_file":      true,
+	"delete_active_file":      true,
+}
+
+// previewTools lists the mutating tools with a dryRun argument. They are the
+// only mutating tools enabled in dry-run mode.
+var previewTools = map[string]bool{
+	"create_or_update_file":   true,
+	"append_to_file":          true,
+	"patch_file_content":      true,
+	"delete_file":             true,
+	"move_file":               true,
+	"bulk_update_frontmatter": true,
+	"rename_tag":              true,
+	"merge_tags":              true,
+	"replace_in_vault":        true,
+}
+
+// ToolPolicy restricts the tools offered to clients. The zero value enables
+// every tool.
+type ToolPolicy struct {
+	// ReadOnly disables every tool that changes the vault or runs commands
+	ReadOnly bool
+	// RequireConfirm makes destructive tools fail unless called with
+	// "confirm": true
+	RequireConfirm bool
+	// Allow lists the only tools enabled when it is not empty. Entries are
+	// tool names or path.Match patterns.
+	Allow []string
+	// Deny lists tools that are disabled even when allowed
+	Deny []string
+	// DryRun forces dryRun on every tool that supports it and disables the
+	// other mutating tools, so nothing is ever written
+	DryRun bool
+}
+
+// WithToolPolicy restricts the tools offered to clients
+func WithToolPolicy(policy ToolPolicy) Option {
+	return func(s *MCPServer) {
+		s.policy = policy
+	}
+}
+
+// Enabled reports whether the tool may be listed and called
+func (p ToolPolicy) Enabled(name string) bool {
+	if p.ReadOnly && mutatingTools[name] {
+		return false
+	}
+	if p.DryRun && mutatingTools[name] && !previewTools[name] {
+		return false
+	}
+	if len(p.Allow) > 0 && !matchesAny(p.Allow, name) {
+		return false
+	}
+	return !matchesAny(p.Deny, name)
+}
+
+// needsConfirm reports whether calls to the tool must carry "confirm": true
+func (p ToolPolicy) needsConfirm(name string) bool {
+	return p.RequireConfirm && destructiveTools[name]
+}
+
+// check refuses calls to disabled tools and unconfirmed destructive calls.
+// Previews of tools with a dryRun argument need no confirmation.
+func (p ToolPolicy) check(name string, params map[string]any) error {
+	if !p.Enabled(name) {
+		return fmt.Errorf("tool %s is disabled by the server configuration", name)
+	}
+	confirm, _ := params["confirm"].(bool)
+	dryRun, _ := params["dryRun"].(bool)
+	preview := previewTools[name] && (dryRun || p.DryRun)
+	if p.needsConfirm(name) && !confirm && !preview {
+		return fmt.Errorf("%s is destructive and must be called with \"confirm\": true; ask the user before retrying", name)
+	}
+	return nil
+}
+
+// apply drops the disabled tools and adds the confirm argument to the input
+// schema of destructive tools when it is required
+func (p ToolPolicy) apply(tools []ToolInfo) []ToolInfo {
+	enabled := make([]ToolInfo, 0, len(tools))
+	for _, tool := range tools {
+		if !p.Enabled(tool.Name) {
+			continue
+		}
+		if schema, ok := tool.InputSchema.(map[string]any); ok && p.needsConfirm(tool.Name) {
+			tool.InputSchema = withConfirm(schema, previewTools[tool.Name])
+		}
+		enabled = append(enabled, tool)
+	}
+	return enabled
+}
+
+// withConfirm returns a copy of an input schema with a confirm argument. It
+// is required unless the tool can preview the change with dryRun instead.
+func withConfirm(schema map[string]any, preview bool) map[string]any {
+	schema = maps.Clone(schema)
+	properties, _ := schema["properties"].(map[string]any)
+	properties = maps.Clone(properties)
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	description := "Must be true to confirm this destructive operation"
+	if preview {
+		description += " unless dryRun is set"
+	}
+	properties["confirm"] = map[string]any{
+		"type":        "boolean",
+		"description": description,
+	}
+	schema["properties"] = properties
+
+	if !preview {
+		required, _ := schema["required"].([]string)
+		schema["required"] = append(slices.Clone(required), "confirm")
+	}
+	return schema
+}
+
+// matchesAny reports whether the name matches one of the patterns
+func matchesAny(patterns []string, name string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		matched, _ := path.Match(pattern, name)
+		return matched
+	})
+}
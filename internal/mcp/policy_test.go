@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listToolNames returns the names of the tools a server offers
+func listToolNames(t *testing.T, server *MCPServer) map[string]ToolInfo {
+	t.Helper()
+	response := server.handleRequest(context.Background(), &MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	require.NotNil(t, response)
+	tools := map[string]ToolInfo{}
+	for _, tool := range response.Result.(map[string]any)["tools"].([]ToolInfo) {
+		tools[tool.Name] = tool
+	}
+	return tools
+}
+
+// TestPolicyToolsExist tests that the policy only names real tools
+func TestPolicyToolsExist(t *testing.T) {
+	tools := listToolNames(t, NewMCPServer("test-token", "http://localhost:27123"))
+	for name := range mutatingTools {
+		assert.Contains(t, tools, name)
+	}
+	for name := range destructiveTools {
+		assert.Contains(t, tools, name)
+		assert.True(t, mutatingTools[name], name)
+	}
+}
+
+// TestToolPolicyEnabled tests read-only mode and allow/deny patterns
+func TestToolPolicyEnabled(t *testing.T) {
+	assert.True(t, ToolPolicy{}.Enabled("delete_file"))
+
+	readOnly := ToolPolicy{ReadOnly: true}
+	assert.False(t, readOnly.Enabled("delete_file"))
+	assert.False(t, readOnly.Enabled("execute_command"))
+	assert.True(t, readOnly.Enabled("get_file_content"))
+
+	policy := ToolPolicy{Allow: []string{"get_*", "search_*", "delete_file"}, Deny: []string{"get_active_file", "delete_*"}}
+	assert.True(t, policy.Enabled("get_file_content"))
+	assert.True(t, policy.Enabled("search_vault_simple"))
+	assert.False(t, policy.Enabled("get_active_file"))
+	assert.False(t, policy.Enabled("delete_file"))
+	assert.False(t, policy.Enabled("list_tags"))
+}
+
+// TestHandleToolsListPolicy tests that disabled tools are hidden and
+// destructive tools declare the confirm argument
+func TestHandleToolsListPolicy(t *testing.T) {
+	tools := listToolNames(t, NewMCPServer("test-token", "http://localhost:27123", WithToolPolicy(ToolPolicy{ReadOnly: true})))
+	assert.Contains(t, tools, "get_file_content")
+	assert.Contains(t, tools, "open_file")
+	for name := range mutatingTools {
+		assert.NotContains(t, tools, name)
+	}
+
+	tools = listToolNames(t, NewMCPServer("test-token", "http://localhost:27123", WithToolPolicy(ToolPolicy{RequireConfirm: true})))
+	schema := tools["execute_command"].InputSchema.(map[string]any)
+	assert.Contains(t, schema["properties"], "confirm")
+	assert.Equal(t, []string{"commandId", "confirm"}, schema["required"])
+
+	// Tools that can preview their changes only need confirm without dryRun
+	schema = tools["delete_file"].InputSchema.(map[string]any)
+	assert.Contains(t, schema["properties"], "confirm")
+	assert.Equal(t, []string{"filename"}, schema["required"])
+	schema = tools["rename_tag"].InputSchema.(map[string]any)
+	assert.Contains(t, schema["properties"], "confirm")
+	assert.Equal(t, []string{"tag", "newTag"}, schema["required"])
+	assert.Contains(t, tools["undo_last_change"].InputSchema.(map[string]any)["required"], "confirm")
+	assert.NotContains(t, tools["append_to_file"].InputSchema.(map[string]any)["properties"], "confirm")
+
+	// The shared schemas are left untouched
+	tools = listToolNames(t, NewMCPServer("test-token", "http://localhost:27123"))
+	assert.Equal(t, []string{"filename"}, tools["delete_file"].InputSchema.(map[string]any)["required"])
+}
+
+// TestHandleToolsCallPolicy tests that disabled and unconfirmed calls are
+// refused before reaching the vault
+func TestHandleToolsCallPolicy(t *testing.T) {
+	var requests int
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer vault.Close()
+
+	call := func(server *MCPServer, args map[string]any) map[string]any {
+		t.Helper()
+		response := server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "tools/call",
+			Params:  map[string]any{"name": "delete_file", "arguments": args},
+		})
+		require.NotNil(t, response)
+		require.Nil(t, response.Error)
+		return response.Result.(map[string]any)
+	}
+
+	result := call(NewMCPServer("test-token", vault.URL, WithToolPolicy(ToolPolicy{Deny: []string{"delete_*"}})), map[string]any{"filename": "a.md"})
+	assert.Equal(t, true, result["isError"])
+	assert.Equal(t, "tool delete_file is disabled by the server configuration", result["content"].([]map[string]any)[0]["text"])
+
+	server := NewMCPServer("test-token", vault.URL, WithToolPolicy(ToolPolicy{RequireConfirm: true}))
+	result = call(server, map[string]any{"filename": "a.md"})
+	assert.Equal(t, true, result["isError"])
+	assert.Contains(t, result["content"].([]map[string]any)[0]["text"], `"confirm": true`)
+	assert.Zero(t, requests)
+
+	result = call(server, map[string]any{"filename": "a.md", "dryRun": true})
+	assert.NotContains(t, result, "isError")
+	assert.Equal(t, 1, requests)
+
+	requests = 0
+	result = call(server, map[string]any{"filename": "a.md", "confirm": true})
+	assert.NotContains(t, result, "isError")
+	assert.Equal(t, 1, requests)
+
+	// dryRun does not replace confirm for tools that cannot preview
+	requests = 0
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      2,
+		Method:  "tools/call",
+		Params:  map[string]any{"name": "delete_active_file", "arguments": map[string]any{"dryRun": true}},
+	})
+	require.NotNil(t, response)
+	result = response.Result.(map[string]any)
+	assert.Equal(t, true, result["isError"])
+	assert.Contains(t, result["content"].([]map[string]any)[0]["text"], `"confirm": true`)
+	assert.Zero(t, requests)
+}
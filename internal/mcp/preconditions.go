@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+)
+
+// preconditionTools lists the tools changing a single note, which accept the
+// expectedMtime and expectedHash arguments. The value is the argument naming
+// the note, or empty for the periodic note and active file tools.
+var preconditionTools = map[string]string{
+	"create_or_update_file":    "filename",
+	"append_to_file":           "filename",
+	"patch_file_content":       "filename",
+	"delete_file":              "filename",
+	"upload_attachment":        "filename",
+	"move_file":                "source",
+	"set_frontmatter_field":    "filename",
+	"delete_frontmatter_field": "filename",
+	"complete_task":            "filename",
+	"add_task":                 "filename",
+	"update_periodic_note":     "",
+	"append_to_periodic_note":  "",
+	"patch_periodic_note":      "",
+	"delete_periodic_note":     "",
+	"update_active_file":       "",
+	"append_to_active_file":    "",
+	"patch_active_file":        "",
+	"delete_active_file":       "",
+}
+
+// withPreconditions adds the expectedMtime and expectedHash arguments to the
+// input schemas of the tools listed in preconditionTools
+func withPreconditions(tools []ToolInfo) []ToolInfo {
+	for i, tool := range tools {
+		schema, ok := tool.InputSchema.(map[string]any)
+		if _, changesNote := preconditionTools[tool.Name]; !ok || !changesNote {
+			continue
+		}
+		schema = maps.Clone(schema)
+		properties, _ := schema["properties"].(map[string]any)
+		properties = maps.Clone(properties)
+		if properties == nil {
+			properties = map[string]any{}
+		}
+		properties["expectedMtime"] = map[string]any{
+			"type":        "integer",
+			"description": "Only make the change if the note was last modified at this time, the stat.mtime in milliseconds returned by the json format of the read tools",
+		}
+		properties["expectedHash"] = map[string]any{
+			"type":        "string",
+			"description": "Only make the change if the note content still has this hash, the 'sha256:<hex>' hash returned by the json format of the read tools",
+		}
+		schema["properties"] = properties
+		tools[i].InputSchema = schema
+	}
+	return tools
+}
+
+// preconditionFromParams reads the expectedMtime and expectedHash arguments
+// of a tool call. It returns nil when neither is given.
+func preconditionFromParams(name string, params map[string]any) (*obsidian.Precondition, error) {
+	pathArgument, changesNote := preconditionTools[name]
+	if !changesNote {
+		return nil, nil
+	}
+
+	var precondition obsidian.Precondition
+	if value, ok := params["expectedMtime"]; ok {
+		mtime, isNumber := value.(float64)
+		if !isNumber || mtime <= 0 || mtime != float64(int64(mtime)) {
+			return nil, fmt.Errorf("expectedMtime must be a positive integer of milliseconds")
+		}
+		precondition.Mtime = int64(mtime)
+	}
+	if value, ok := params["expectedHash"]; ok {
+		hash, isString := value.(string)
+		if !isString || hash == "" {
+			return nil, fmt.Errorf("expectedHash must be a non-empty string")
+		}
+		precondition.Hash = strings.TrimSpace(hash)
+	}
+	if precondition.Mtime == 0 && precondition.Hash == "" {
+		return nil, nil
+	}
+
+	if pathArgument != "" {
+		precondition.Path, _ = params[pathArgument].(string)
+	}
+	return &precondition, nil
+}
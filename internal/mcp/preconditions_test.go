@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPreconditionToolsExist tests that the precondition arguments are
+// declared by real tools only
+func TestPreconditionToolsExist(t *testing.T) {
+	tools := listToolNames(t, NewMCPServer("test-token", "http://localhost:27123"))
+	for name := range preconditionTools {
+		require.Contains(t, tools, name)
+		assert.True(t, mutatingTools[name], name)
+		properties := tools[name].InputSchema.(map[string]any)["properties"].(map[string]any)
+		assert.Contains(t, properties, "expectedMtime", name)
+		assert.Contains(t, properties, "expectedHash", name)
+	}
+	properties := tools["get_file_content"].InputSchema.(map[string]any)["properties"].(map[string]any)
+	assert.NotContains(t, properties, "expectedMtime")
+}
+
+// TestHandleToolsCallPreconditions tests that changes to notes modified since
+// they were read fail with a conflict
+func TestHandleToolsCallPreconditions(t *testing.T) {
+	var writes int
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writes++
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.Header.Get("Accept") != "application/vnd.olrapi.note+json" {
+			_, _ = w.Write([]byte("- [ ] one\n"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(obsidian.Note{Path: "a.md", Content: "- [ ] one\n", Stat: obsidian.NoteStat{Mtime: 100}})
+	}))
+	defer vault.Close()
+
+	server := NewMCPServer("test-token", vault.URL)
+	call := func(name string, args map[string]any) map[string]any {
+		t.Helper()
+		response := server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      name,
+			Method:  "tools/call",
+			Params:  map[string]any{"name": name, "arguments": args},
+		})
+		require.NotNil(t, response)
+		require.Nil(t, response.Error)
+		return response.Result.(map[string]any)
+	}
+	text := func(result map[string]any) string {
+		return result["content"].([]map[string]any)[0]["text"].(string)
+	}
+
+	result := call("append_to_file", map[string]any{"filename": "a.md", "content": "- [ ] two\n", "expectedMtime": 100.0})
+	require.NotContains(t, result, "isError", text(result))
+	result = call("complete_task", map[string]any{"filename": "a.md", "line": 1.0, "expectedHash": obsidian.ContentHash("- [ ] one\n")})
+	require.NotContains(t, result, "isError", text(result))
+	assert.Equal(t, 2, writes)
+
+	result = call("append_to_file", map[string]any{"filename": "a.md", "content": "- [ ] two\n", "expectedMtime": 90.0})
+	assert.Equal(t, true, result["isError"])
+	assert.Equal(t, "conflict: a.md has changed since it was read (expected mtime 90, found 100), nothing was written\n"+
+		"Diff from its current content to the content that would have been written:\n"+
+		"--- a.md\n+++ a.md\n@@ -1 +1,2 @@\n - [ ] one\n+- [ ] two\n", text(result))
+
+	result = call("update_active_file", map[string]any{"content": "x", "expectedHash": "sha256:abc"})
+	assert.Equal(t, true, result["isError"])
+	assert.Contains(t, text(result), "conflict: a.md has changed")
+
+	result = call("delete_file", map[string]any{"filename": "a.md", "expectedMtime": "yesterday"})
+	assert.Equal(t, true, result["isError"])
+	assert.Equal(t, "expectedMtime must be a positive integer of milliseconds", text(result))
+	assert.Equal(t, 2, writes)
+}
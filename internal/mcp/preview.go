@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/diff"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+)
+
+// fileChange computes the content of a file after a change from its current
+// content. exists reports whether the file exists before the change and keep
+// whether it still exists after it.
+type fileChange func(before string, exists bool) (after string, keep bool, err error)
+
+// previewChange returns the unified diff a change would make to a file
+// without writing anything
+func (s *MCPServer) previewChange(ctx context.Context, filename string, change fileChange) (*toolResult, error) {
+	before, exists, err := s.currentContent(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	if (&obsidian.File{Path: filename, MimeType: "application/octet-stream", Data: before}).IsBinary() {
+		return nil, fmt.Errorf("cannot preview changes to binary file %s", filename)
+	}
+
+	after, keep, err := change(string(before), exists)
+	if err != nil {
+		return nil, err
+	}
+
+	oldName, newName := filename, filename
+	if !exists {
+		oldName = "/dev/null"
+	}
+	if !keep {
+		newName = "/dev/null"
+	}
+	unified := diff.Unified(oldName, newName, string(before), after)
+	changed := unified != "" || exists != keep
+
+	message := fmt.Sprintf("Dry run: no changes were written to %s", filename)
+	text := unified
+	if !changed {
+		text = fmt.Sprintf("Dry run: %s would be unchanged", filename)
+	} else if unified == "" {
+		text = fmt.Sprintf("Dry run: empty file %s would be created or deleted", filename)
+	}
+	return &toolResult{
+		text: text,
+		structured: map[string]any{
+			"message": message,
+			"dryRun":  true,
+			"changed": changed,
+			"diff":    unified,
+		},
+	}, nil
+}
+
+// requireExisting wraps a change to files that must already exist, matching
+// the not found errors of the Local REST API
+func requireExisting(filename string, change fileChange) fileChange {
+	return func(before string, exists bool) (string, bool, error) {
+		if !exists {
+			return "", false, fmt.Errorf("file not found: %s", filename)
+		}
+		return change(before, exists)
+	}
+}
@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDryRunPreviews tests that dry runs return diffs without writing
+func TestDryRunPreviews(t *testing.T) {
+	var writes int
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writes++
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.URL.Path != "/vault/a.md" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errorCode": 40400, "message": "File not found"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/markdown")
+		_, _ = w.Write([]byte("# Todo\n- one\n"))
+	}))
+	defer vault.Close()
+
+	call := func(server *MCPServer, name string, args map[string]any) map[string]any {
+		t.Helper()
+		response := server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      name,
+			Method:  "tools/call",
+			Params:  map[string]any{"name": name, "arguments": args},
+		})
+		require.NotNil(t, response)
+		require.Nil(t, response.Error)
+		return response.Result.(map[string]any)
+	}
+	diffOf := func(result map[string]any) string {
+		t.Helper()
+		require.NotContains(t, result, "isError", result["content"])
+		structured := result["structuredContent"].(map[string]any)
+		assert.Equal(t, true, structured["dryRun"])
+		return structured["diff"].(string)
+	}
+
+	server := NewMCPServer("test-token", vault.URL)
+	assert.Equal(t, "--- a.md\n+++ a.md\n@@ -1,2 +1,3 @@\n # Todo\n - one\n+- two\n",
+		diffOf(call(server, "append_to_file", map[string]any{"filename": "a.md", "content": "- two\n", "dryRun": true})))
+	assert.Equal(t, "--- a.md\n+++ a.md\n@@ -1,2 +1,3 @@\n # Todo\n+- zero\n - one\n",
+		diffOf(call(server, "patch_file_content", map[string]any{
+			"filename": "a.md", "operation": "prepend", "targetType": "heading", "target": "Todo", "content": "- zero", "dryRun": true,
+		})))
+	assert.Equal(t, "--- a.md\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-# Todo\n-- one\n",
+		diffOf(call(server, "delete_file", map[string]any{"filename": "a.md", "dryRun": true})))
+	assert.Equal(t, "--- /dev/null\n+++ b.md\n@@ -0,0 +1 @@\n+new\n",
+		diffOf(call(server, "create_or_update_file", map[string]any{"filename": "b.md", "content": "new\n", "dryRun": true})))
+
+	result := call(server, "delete_file", map[string]any{"filename": "b.md", "dryRun": true})
+	assert.Equal(t, true, result["isError"])
+	assert.Equal(t, "file not found: b.md", result["content"].([]map[string]any)[0]["text"])
+	assert.Zero(t, writes)
+
+	// The global dry-run mode previews by default and disables the tools
+	// that cannot preview their changes
+	server = NewMCPServer("test-token", vault.URL, WithToolPolicy(ToolPolicy{DryRun: true}))
+	assert.NotEmpty(t, diffOf(call(server, "create_or_update_file", map[string]any{"filename": "a.md", "content": "replaced\n"})))
+	result = call(server, "complete_task", map[string]any{"filename": "a.md", "line": 2})
+	assert.Equal(t, true, result["isError"])
+	assert.NotContains(t, listToolNames(t, server), "complete_task")
+	assert.Zero(t, writes)
+}
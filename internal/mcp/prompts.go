@@ -0,0 +1,332 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/markdown"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+)
+
+// defaultPromptsFolder is the vault folder user-defined prompts are loaded from
+const defaultPromptsFolder = "_mcp/prompts"
+
+// missingPromptArgumentError is returned when a required prompt argument is missing
+type missingPromptArgumentError struct {
+	name string
+}
+
+func (e *missingPromptArgumentError) Error() string {
+	return e.name + " is required"
+}
+
+// PromptInfo represents a prompt offered by the server
+type PromptInfo struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptArgument describes an argument accepted by a prompt
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptMessage represents a message returned by prompts/get
+type PromptMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+// builtinPrompt is a prompt shipped with the server
+type builtinPrompt struct {
+	info  PromptInfo
+	build func(ctx context.Context, s *MCPServer, args map[string]string) ([]PromptMessage, error)
+}
+
+// builtinPrompts lists the prompts shipped with the server
+var builtinPrompts = []builtinPrompt{
+	{
+		info: PromptInfo{
+			Name:        "summarize_note",
+			Description: "Summarize a note from the vault",
+			Arguments: []PromptArgument{
+				{Name: "filename", Description: "Path to the note relative to vault root", Required: true},
+			},
+		},
+		build: func(ctx context.Context, s *MCPServer, args map[string]string) ([]PromptMessage, error) {
+			note, err := s.promptResource(ctx, vaultFileURI(args["filename"]))
+			if err != nil {
+				return nil, err
+			}
+			return []PromptMessage{
+				note,
+				textPromptMessage("Summarize the note above. Lead with its main point, then list the key ideas, decisions and open questions."),
+			}, nil
+		},
+	},
+	{
+		info: PromptInfo{
+			Name:        "daily_review",
+			Description: "Review a daily note and plan next steps",
+			Arguments: []PromptArgument{
+				{Name: "date", Description: "Date of the daily note as YYYY-MM-DD (defaults to today)"},
+			},
+		},
+		build: func(ctx context.Context, s *MCPServer, args map[string]string) ([]PromptMessage, error) {
+			uri := periodicURIPrefix + "daily"
+			if args["date"] != "" {
+				uri += "/" + args["date"]
+			}
+			note, err := s.promptResource(ctx, uri)
+			if err != nil {
+				return nil, err
+			}
+			return []PromptMessage{
+				note,
+				textPromptMessage("Review the daily note above. Summarize what was done, list unfinished tasks and suggest priorities for the next day."),
+			}, nil
+		},
+	},
+	{
+		info: PromptInfo{
+			Name:        "find_related_notes",
+			Description: "Find notes in the vault related to a given note",
+			Arguments: []PromptArgument{
+				{Name: "filename", Description: "Path to the note relative to vault root", Required: true},
+			},
+		},
+		build: func(ctx context.Context, s *MCPServer, args map[string]string) ([]PromptMessage, error) {
+			note, err := s.promptResource(ctx, vaultFileURI(args["filename"]))
+			if err != nil {
+				return nil, err
+			}
+			return []PromptMessage{
+				note,
+				textPromptMessage("Identify the main topics of the note above, then use the search_vault_simple and search_vault_advanced tools to find other notes in the vault about the same topics. List each related note with a one-line explanation of how it relates."),
+			}, nil
+		},
+	},
+}
+
+// promptArgumentPattern matches {{argument}} placeholders in prompt templates
+var promptArgumentPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_-]+)\s*\}\}`)
+
+// textPromptMessage creates a user message with text content
+func textPromptMessage(text string) PromptMessage {
+	return PromptMessage{
+		Role: "user",
+		Content: map[string]any{
+			"type": "text",
+			"text": text,
+		},
+	}
+}
+
+// promptResource creates a user message embedding a resource
+func (s *MCPServer) promptResource(ctx context.Context, uri string) (PromptMessage, error) {
+	contents, err := s.readResource(ctx, uri)
+	if err != nil {
+		return PromptMessage{}, err
+	}
+	if contents == nil {
+		return PromptMessage{}, fmt.Errorf("unknown resource: %s", uri)
+	}
+	return PromptMessage{
+		Role: "user",
+		Content: map[string]any{
+			"type":     "resource",
+			"resource": contents,
+		},
+	}, nil
+}
+
+// userPrompt is a prompt loaded from a note in the prompts folder
+type userPrompt struct {
+	info     PromptInfo
+	template string
+}
+
+// loadUserPrompts reads the prompt templates stored in the vault. Each markdown
+// note in the prompts folder is a prompt whose body is the message template and
+// whose frontmatter may declare a name, description and arguments. A missing
+// folder holds no prompts, and notes that cannot be read as prompts are
+// logged and skipped.
+func (s *MCPServer) loadUserPrompts(ctx context.Context) ([]userPrompt, error) {
+	if s.promptsFolder == "" {
+		return nil, nil
+	}
+
+	files, err := s.obsidianClient.ListAllVaultFiles(ctx, s.promptsFolder)
+	var apiErr *obsidian.APIError
+	if errors.As(err, &apiErr) && apiErr.NotFound() {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var prompts []userPrompt
+	for _, file := range files {
+		if path.Ext(file) != ".md" {
+			continue
+		}
+
+		note, err := s.obsidianClient.GetNote(ctx, file)
+		if err != nil {
+			fmt.Fprintf(s.stderr, "Skipping prompt %s: %v\n", file, err)
+			continue
+		}
+
+		var meta struct {
+			Name        string           `json:"name"`
+			Description string           `json:"description"`
+			Arguments   []PromptArgument `json:"arguments"`
+		}
+		if raw, err := json.Marshal(note.Frontmatter); err == nil {
+			if err := json.Unmarshal(raw, &meta); err != nil {
+				fmt.Fprintf(s.stderr, "Skipping prompt %s: invalid frontmatter: %v\n", file, err)
+				continue
+			}
+		}
+		if meta.Name == "" {
+			meta.Name = strings.TrimSuffix(path.Base(file), ".md")
+		}
+
+		_, body := markdown.SplitFrontmatter(note.Content)
+		prompts = append(prompts, userPrompt{
+			info: PromptInfo{
+				Name:        meta.Name,
+				Description: meta.Description,
+				Arguments:   meta.Arguments,
+			},
+			template: strings.TrimSpace(body),
+		})
+	}
+
+	return prompts, nil
+}
+
+// handlePromptsList returns the built-in and user-defined prompts
+func (s *MCPServer) handlePromptsList(ctx context.Context, request *MCPRequest) *MCPResponse {
+	prompts := make([]PromptInfo, 0, len(builtinPrompts))
+	seen := make(map[string]bool)
+	for _, prompt := range builtinPrompts {
+		prompts = append(prompts, prompt.info)
+		seen[prompt.info.Name] = true
+	}
+
+	userPrompts, err := s.loadUserPrompts(ctx)
+	if err != nil {
+		fmt.Fprintf(s.stderr, "Failed to load prompts from %s: %v\n", s.promptsFolder, err)
+	}
+	for _, prompt := range userPrompts {
+		if seen[prompt.info.Name] {
+			continue
+		}
+		prompts = append(prompts, prompt.info)
+		seen[prompt.info.Name] = true
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      request.ID,
+		Result: map[string]any{
+			"prompts": prompts,
+		},
+	}
+}
+
+// handlePromptsGet renders a prompt with the given arguments
+func (s *MCPServer) handlePromptsGet(ctx context.Context, request *MCPRequest) *MCPResponse {
+	name, ok := request.Params["name"].(string)
+	if !ok {
+		return s.createErrorResponse(request.ID, -32602, "Invalid params: missing prompt name")
+	}
+
+	args := make(map[string]string)
+	if rawArgs, ok := request.Params["arguments"].(map[string]any); ok {
+		for key, value := range rawArgs {
+			if str, ok := value.(string); ok {
+				args[key] = str
+			} else {
+				args[key] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	info, messages, err := s.renderPrompt(ctx, name, args)
+	var missingArgErr *missingPromptArgumentError
+	if errors.As(err, &missingArgErr) {
+		return s.createErrorResponse(request.ID, -32602, "Invalid params: "+err.Error())
+	}
+	if err != nil {
+		return s.createErrorResponse(request.ID, -32603, err.Error())
+	}
+	if info == nil {
+		return s.createErrorResponse(request.ID, -32602, fmt.Sprintf("Invalid params: unknown prompt: %s", name))
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      request.ID,
+		Result: map[string]any{
+			"description": info.Description,
+			"messages":    messages,
+		},
+	}
+}
+
+// renderPrompt resolves a prompt by name and renders its messages. It returns
+// nil info when no prompt with that name exists.
+func (s *MCPServer) renderPrompt(ctx context.Context, name string, args map[string]string) (*PromptInfo, []PromptMessage, error) {
+	for _, prompt := range builtinPrompts {
+		if prompt.info.Name != name {
+			continue
+		}
+		if err := checkPromptArguments(prompt.info, args); err != nil {
+			return nil, nil, err
+		}
+		messages, err := prompt.build(ctx, s, args)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &prompt.info, messages, nil
+	}
+
+	userPrompts, err := s.loadUserPrompts(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load prompts from %s: %w", s.promptsFolder, err)
+	}
+	for _, prompt := range userPrompts {
+		if prompt.info.Name != name {
+			continue
+		}
+		if err := checkPromptArguments(prompt.info, args); err != nil {
+			return nil, nil, err
+		}
+		text := promptArgumentPattern.ReplaceAllStringFunc(prompt.template, func(placeholder string) string {
+			return args[promptArgumentPattern.FindStringSubmatch(placeholder)[1]]
+		})
+		return &prompt.info, []PromptMessage{textPromptMessage(text)}, nil
+	}
+
+	return nil, nil, nil
+}
+
+// checkPromptArguments verifies that all required prompt arguments are present
+func checkPromptArguments(info PromptInfo, args map[string]string) error {
+	for _, arg := range info.Arguments {
+		if arg.Required && args[arg.Name] == "" {
+			return &missingPromptArgumentError{name: arg.Name}
+		}
+	}
+	return nil
+}
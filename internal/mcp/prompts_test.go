@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPromptsVaultServer starts a fake Obsidian server with a prompts folder
+func newPromptsVaultServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/_mcp/prompts/":
+			_, _ = w.Write([]byte(`{"files": ["broken.md", "standup.md", "notes.txt"]}`))
+		case "/vault/_mcp/prompts/broken.md":
+			_, _ = w.Write([]byte(`{"content": "---\narguments: project\n---\nBroken", "frontmatter": {"arguments": "project"}}`))
+		case "/vault/_mcp/prompts/standup.md":
+			_, _ = w.Write([]byte(`{
+				"content": "---\ndescription: Draft a standup update\narguments:\n  - name: project\n    required: true\n---\nWrite a standup update for {{project}} covering {{ focus }}.\n",
+				"path": "_mcp/prompts/standup.md",
+				"tags": [],
+				"frontmatter": {
+					"description": "Draft a standup update",
+					"arguments": [{"name": "project", "required": true}, {"name": "focus"}]
+				},
+				"stat": {"ctime": 1, "mtime": 1, "size": 1}
+			}`))
+		case "/vault/Project.md":
+			_, _ = w.Write([]byte("# Project\n\nStatus: green"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestHandlePromptsList tests listing built-in and vault prompts, skipping
+// malformed ones
+func TestHandlePromptsList(t *testing.T) {
+	vault := newPromptsVaultServer(t)
+	server := NewMCPServer("test-token", vault.URL)
+	stderr := &syncBuffer{}
+	server.stderr = stderr
+
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      "prompts",
+		Method:  "prompts/list",
+	})
+	require.Nil(t, response.Error)
+
+	prompts := response.Result.(map[string]any)["prompts"].([]PromptInfo)
+	names := make([]string, len(prompts))
+	for i, prompt := range prompts {
+		names[i] = prompt.Name
+	}
+	assert.Equal(t, []string{"summarize_note", "daily_review", "find_related_notes", "standup"}, names)
+
+	standup := prompts[3]
+	assert.Equal(t, "Draft a standup update", standup.Description)
+	require.Len(t, standup.Arguments, 2)
+	assert.True(t, standup.Arguments[0].Required)
+	assert.Contains(t, stderr.String(), "Skipping prompt _mcp/prompts/broken.md: invalid frontmatter")
+}
+
+// TestHandlePromptsListWithoutFolder tests that a missing prompts folder only hides user prompts
+func TestHandlePromptsListWithoutFolder(t *testing.T) {
+	vault := newPromptsVaultServer(t)
+	server := NewMCPServer("test-token", vault.URL, WithPromptsFolder("missing"))
+	stderr := &syncBuffer{}
+	server.stderr = stderr
+
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      "prompts",
+		Method:  "prompts/list",
+	})
+	require.Nil(t, response.Error)
+	assert.Len(t, response.Result.(map[string]any)["prompts"], len(builtinPrompts))
+	assert.Empty(t, stderr.String())
+}
+
+// TestHandlePromptsGetUserPrompt tests rendering a vault prompt template
+func TestHandlePromptsGetUserPrompt(t *testing.T) {
+	vault := newPromptsVaultServer(t)
+	server := NewMCPServer("test-token", vault.URL)
+
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      "prompt",
+		Method:  "prompts/get",
+		Params: map[string]any{
+			"name":      "standup",
+			"arguments": map[string]any{"project": "Apollo", "focus": "blockers"},
+		},
+	})
+	require.Nil(t, response.Error)
+
+	result := response.Result.(map[string]any)
+	assert.Equal(t, "Draft a standup update", result["description"])
+	messages := result["messages"].([]PromptMessage)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "user", messages[0].Role)
+	assert.Equal(t, "Write a standup update for Apollo covering blockers.", messages[0].Content.(map[string]any)["text"])
+}
+
+// TestHandlePromptsGetBuiltin tests rendering a built-in prompt with an embedded note
+func TestHandlePromptsGetBuiltin(t *testing.T) {
+	vault := newPromptsVaultServer(t)
+	server := NewMCPServer("test-token", vault.URL)
+
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      "prompt",
+		Method:  "prompts/get",
+		Params: map[string]any{
+			"name":      "summarize_note",
+			"arguments": map[string]any{"filename": "Project.md"},
+		},
+	})
+	require.Nil(t, response.Error)
+
+	messages := response.Result.(map[string]any)["messages"].([]PromptMessage)
+	require.Len(t, messages, 2)
+	content := messages[0].Content.(map[string]any)
+	assert.Equal(t, "resource", content["type"])
+	resource := content["resource"].(*ResourceContents)
+	assert.Equal(t, "obsidian://vault/Project.md", resource.URI)
+	assert.Contains(t, resource.Text, "Status: green")
+}
+
+// TestHandlePromptsGetErrors tests missing arguments and unknown prompts
+func TestHandlePromptsGetErrors(t *testing.T) {
+	vault := newPromptsVaultServer(t)
+	server := NewMCPServer("test-token", vault.URL)
+
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      "prompt",
+		Method:  "prompts/get",
+		Params:  map[string]any{"name": "summarize_note"},
+	})
+	require.NotNil(t, response.Error)
+	assert.Equal(t, -32602, response.Error.Code)
+	assert.Contains(t, response.Error.Message, "filename is required")
+
+	response = server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      "prompt",
+		Method:  "prompts/get",
+		Params:  map[string]any{"name": "does_not_exist"},
+	})
+	require.NotNil(t, response.Error)
+	assert.Equal(t, -32602, response.Error.Code)
+	assert.Contains(t, response.Error.Message, "unknown prompt")
+}
@@ -0,0 +1,192 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/diff"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/markdown"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/tags"
+)
+
+// defaultReplaceMaxFiles is the number of files replace_in_vault may change
+// unless the call raises it
+const defaultReplaceMaxFiles = 20
+
+// replaceRequest describes a search and replace across the vault
+type replaceRequest struct {
+	Pattern     string
+	Replacement string
+	// Regex makes Pattern a regular expression instead of literal text
+	Regex bool
+	// Folder, Glob and Tag limit the notes searched when set
+	Folder   string
+	Glob     string
+	Tag      string
+	MaxFiles int
+	DryRun   bool
+	Options  markdown.ReplaceOptions
+}
+
+// replaceResult is the outcome of replace_in_vault
+type replaceResult struct {
+	Pattern      string         `json:"pattern"`
+	DryRun       bool           `json:"dryRun"`
+	Replacements int            `json:"replacements"`
+	Files        []replacedFile `json:"files"`
+	// Errors maps the notes that could not be read or updated to the reason
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// replacedFile is a note changed by replace_in_vault. Diff is only set for
+// dry runs.
+type replacedFile struct {
+	Path         string `json:"path"`
+	Replacements int    `json:"replacements"`
+	Diff         string `json:"diff,omitempty"`
+}
+
+// replaceRequestFromParams reads the arguments of replace_in_vault
+func replaceRequestFromParams(params map[string]any) (*replaceRequest, error) {
+	pattern, ok := params["pattern"].(string)
+	if !ok || pattern == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	replacement, ok := params["replacement"].(string)
+	if !ok {
+		return nil, fmt.Errorf("replacement is required")
+	}
+
+	req := &replaceRequest{Pattern: pattern, Replacement: replacement, MaxFiles: defaultReplaceMaxFiles}
+	req.Regex, _ = params["regex"].(bool)
+	req.Folder, _ = params["folder"].(string)
+	req.Glob, _ = params["glob"].(string)
+	req.Tag, _ = params["tag"].(string)
+	req.DryRun, _ = params["dryRun"].(bool)
+	req.Options.Literal = !req.Regex
+	req.Options.SkipCode, _ = params["skipCodeBlocks"].(bool)
+	req.Options.SkipFrontmatter, _ = params["skipFrontmatter"].(bool)
+	if maxFiles, ok := params["maxFiles"].(float64); ok {
+		if maxFiles < 1 {
+			return nil, fmt.Errorf("maxFiles must be at least 1")
+		}
+		req.MaxFiles = int(maxFiles)
+	}
+	if req.Glob != "" {
+		if err := (obsidian.PathRules{Include: []string{req.Glob}}).Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// replaceInVault replaces a pattern in every note in scope. Nothing is
+// written when the matches span more than MaxFiles notes, and each note is
+// only written if it has not changed since it was read.
+func (s *MCPServer) replaceInVault(ctx context.Context, req *replaceRequest) (*replaceResult, error) {
+	expr := regexp.QuoteMeta(req.Pattern)
+	if req.Regex {
+		expr = req.Pattern
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	candidates, err := s.replaceCandidates(ctx, req, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &replaceResult{Pattern: req.Pattern, DryRun: req.DryRun, Files: []replacedFile{}}
+	fail := func(filename string, err error) {
+		if result.Errors == nil {
+			result.Errors = make(map[string]string)
+		}
+		result.Errors[filename] = err.Error()
+	}
+
+	type change struct {
+		note    *obsidian.Note
+		updated string
+		count   int
+	}
+	var changes []change
+	for i, file := range candidates {
+		obsidian.ReportProgress(ctx, float64(i+1), float64(len(candidates)), "Scanned "+file)
+		note, err := s.obsidianClient.GetNote(ctx, file)
+		if err != nil {
+			fail(file, err)
+			continue
+		}
+		if req.Tag != "" && !slices.ContainsFunc(note.Tags, func(tag string) bool { return tags.Matches(tag, req.Tag, true) }) {
+			continue
+		}
+		if note.Path == "" {
+			note.Path = file
+		}
+		if updated, count := markdown.ReplaceText(note.Content, pattern, req.Replacement, req.Options); count > 0 {
+			changes = append(changes, change{note, updated, count})
+		}
+	}
+	if len(changes) > req.MaxFiles {
+		return nil, fmt.Errorf("pattern matches %d files, more than maxFiles (%d): narrow the scope or raise maxFiles", len(changes), req.MaxFiles)
+	}
+
+	for _, c := range changes {
+		file := replacedFile{Path: c.note.Path, Replacements: c.count}
+		if req.DryRun {
+			file.Diff = diff.Unified(c.note.Path, c.note.Path, c.note.Content, c.updated)
+		} else {
+			guarded := obsidian.WithPrecondition(ctx, obsidian.Precondition{Path: c.note.Path, Mtime: c.note.Stat.Mtime})
+			if _, err := s.obsidianClient.CreateOrUpdateFile(guarded, c.note.Path, c.updated, "text/markdown"); err != nil {
+				fail(c.note.Path, err)
+				continue
+			}
+		}
+		result.Files = append(result.Files, file)
+		result.Replacements += c.count
+	}
+	return result, nil
+}
+
+// replaceCandidates finds the notes in scope that may contain the pattern,
+// with a simple search for literal text and by matching regular expressions
+// against the indexed content, as the search API would run them with
+// JavaScript semantics. The matches are confirmed when the notes are read.
+func (s *MCPServer) replaceCandidates(ctx context.Context, req *replaceRequest, pattern *regexp.Regexp) ([]string, error) {
+	var files []string
+	if req.Regex {
+		vault, err := s.index.Refresh(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for file, note := range vault.Notes {
+			if pattern.MatchString(note.Content) {
+				files = append(files, file)
+			}
+		}
+	} else {
+		matches, err := s.obsidianClient.SearchVaultSimple(ctx, req.Pattern, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			files = append(files, match.Filename)
+		}
+	}
+
+	folder := strings.Trim(req.Folder, "/")
+	files = slices.DeleteFunc(files, func(file string) bool {
+		if folder != "" && !strings.HasPrefix(file, folder+"/") {
+			return true
+		}
+		return req.Glob != "" && !(obsidian.PathRules{Include: []string{req.Glob}}).Allowed(file)
+	})
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
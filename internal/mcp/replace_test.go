@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplaceInVault tests replacing text across the notes in scope
+func TestReplaceInVault(t *testing.T) {
+	notes := map[string]*obsidian.Note{
+		"Projects/alpha.md": {Content: "---\nstatus: draft\n---\nalpha draft\n`draft`\n", Tags: []string{"project/alpha"}},
+		"Projects/beta.md":  {Content: "beta draft 2024-01-05\n", Tags: []string{"project"}},
+		"Journal/today.md":  {Content: "a draft of today\n", Tags: []string{"journal"}},
+	}
+	var queries []string
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/search/simple/":
+			queries = append(queries, "simple "+r.URL.Query().Get("query"))
+			var results []obsidian.SearchResult
+			for file, note := range notes {
+				if strings.Contains(strings.ToLower(note.Content), strings.ToLower(r.URL.Query().Get("query"))) {
+					results = append(results, obsidian.SearchResult{Filename: file})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(results)
+		case r.URL.Path == "/search/":
+			// Without modification times the index fetches every note
+			w.WriteHeader(http.StatusBadRequest)
+		case strings.HasSuffix(r.URL.Path, "/"):
+			dir := strings.TrimPrefix(r.URL.Path, "/vault/")
+			list := obsidian.FileList{Files: []string{}}
+			for file := range notes {
+				if rest, ok := strings.CutPrefix(file, dir); ok {
+					if sub, _, nested := strings.Cut(rest, "/"); nested {
+						rest = sub + "/"
+					}
+					if !slices.Contains(list.Files, rest) {
+						list.Files = append(list.Files, rest)
+					}
+				}
+			}
+			_ = json.NewEncoder(w).Encode(list)
+		case r.Method == http.MethodGet:
+			file := strings.TrimPrefix(r.URL.Path, "/vault/")
+			note := *notes[file]
+			note.Path = file
+			_ = json.NewEncoder(w).Encode(note)
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			notes[strings.TrimPrefix(r.URL.Path, "/vault/")].Content = string(body)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer vault.Close()
+
+	server := NewMCPServer("test-token", vault.URL)
+	call := func(args map[string]any) map[string]any {
+		t.Helper()
+		response := server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "tools/call",
+			Params:  map[string]any{"name": "replace_in_vault", "arguments": args},
+		})
+		require.NotNil(t, response)
+		require.Nil(t, response.Error)
+		return response.Result.(map[string]any)
+	}
+	structured := func(result map[string]any) *replaceResult {
+		t.Helper()
+		require.NotContains(t, result, "isError", result["content"])
+		return result["structuredContent"].(*replaceResult)
+	}
+
+	// A dry run in a folder, skipping code and frontmatter, only shows diffs
+	result := structured(call(map[string]any{
+		"pattern": "draft", "replacement": "final", "folder": "Projects",
+		"skipCodeBlocks": true, "skipFrontmatter": true, "dryRun": true,
+	}))
+	assert.Equal(t, []string{"simple draft"}, queries)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 2, result.Replacements)
+	require.Len(t, result.Files, 2)
+	assert.Equal(t, replacedFile{
+		Path:         "Projects/alpha.md",
+		Replacements: 1,
+		Diff:         "--- Projects/alpha.md\n+++ Projects/alpha.md\n@@ -1,5 +1,5 @@\n ---\n status: draft\n ---\n-alpha draft\n+alpha final\n `draft`\n",
+	}, result.Files[0])
+	assert.Equal(t, "Projects/beta.md", result.Files[1].Path)
+	assert.Contains(t, notes["Projects/beta.md"].Content, "draft")
+
+	// Regular expressions are matched by the server against the indexed
+	// notes, with Go syntax, and expand capture groups, within a tag
+	result = structured(call(map[string]any{
+		"pattern": `(?P<year>\d{4})-(\d{2})-(\d{2})`, "replacement": "$3.$2.$year", "regex": true, "tag": "project",
+	}))
+	assert.Equal(t, []string{"simple draft"}, queries)
+	assert.Equal(t, []replacedFile{{Path: "Projects/beta.md", Replacements: 1}}, result.Files)
+	assert.Equal(t, "beta draft 05.01.2024\n", notes["Projects/beta.md"].Content)
+
+	// Nothing is written when more files would change than allowed
+	response := call(map[string]any{"pattern": "draft", "replacement": "final", "maxFiles": 2.0})
+	assert.Equal(t, true, response["isError"])
+	assert.Equal(t, "pattern matches 3 files, more than maxFiles (2): narrow the scope or raise maxFiles",
+		response["content"].([]map[string]any)[0]["text"])
+
+	result = structured(call(map[string]any{"pattern": "draft", "replacement": "final", "glob": "Journal/*.md"}))
+	assert.Equal(t, 1, result.Replacements)
+	assert.Equal(t, "a final of today\n", notes["Journal/today.md"].Content)
+	assert.Contains(t, notes["Projects/alpha.md"].Content, "alpha draft")
+}
@@ -0,0 +1,213 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+)
+
+const (
+	// vaultURIPrefix is the URI prefix for vault files exposed as resources
+	vaultURIPrefix = "obsidian://vault/"
+	// periodicURIPrefix is the URI prefix for periodic notes exposed as resources
+	periodicURIPrefix = "obsidian://periodic/"
+	// resourcePageSize is the number of resources returned per resources/list page
+	resourcePageSize = 100
+)
+
+// ResourceInfo represents a resource exposed by the server
+type ResourceInfo struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplate represents a parameterized resource URI
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceContents represents the contents of a read resource
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// vaultFileURI builds the resource URI for a vault file
+func vaultFileURI(filename string) string {
+	segments := strings.Split(strings.TrimPrefix(filename, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return vaultURIPrefix + strings.Join(segments, "/")
+}
+
+// vaultFileFromURI extracts the vault path from a vault file resource URI
+func vaultFileFromURI(uri string) (string, bool) {
+	escaped, ok := strings.CutPrefix(uri, vaultURIPrefix)
+	if !ok || escaped == "" {
+		return "", false
+	}
+	filename, err := url.PathUnescape(escaped)
+	if err != nil {
+		return "", false
+	}
+	return filename, true
+}
+
+// handleResourcesList returns a page of vault files as resources
+func (s *MCPServer) handleResourcesList(ctx context.Context, request *MCPRequest) *MCPResponse {
+	offset := 0
+	if cursor, ok := request.Params["cursor"].(string); ok && cursor != "" {
+		n, err := strconv.Atoi(cursor)
+		if err != nil || n < 0 {
+			return s.createErrorResponse(request.ID, -32602, "Invalid params: invalid cursor")
+		}
+		offset = n
+	}
+
+	files, err := s.obsidianClient.ListAllVaultFiles(ctx, "")
+	if err != nil {
+		return s.createErrorResponse(request.ID, -32603, err.Error())
+	}
+
+	offset = min(offset, len(files))
+	end := min(offset+resourcePageSize, len(files))
+
+	resources := make([]ResourceInfo, 0, end-offset)
+	for _, file := range files[offset:end] {
+		resources = append(resources, ResourceInfo{
+			URI:         vaultFileURI(file),
+			Name:        path.Base(file),
+			Description: file,
+			MimeType:    obsidian.MimeTypeForPath(file),
+		})
+	}
+
+	result := map[string]any{
+		"resources": resources,
+	}
+	if end < len(files) {
+		result["nextCursor"] = strconv.Itoa(end)
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      request.ID,
+		Result:  result,
+	}
+}
+
+// handleResourceTemplatesList returns the parameterized resources supported by the server
+func (s *MCPServer) handleResourceTemplatesList(request *MCPRequest) *MCPResponse {
+	templates := []ResourceTemplate{
+		{
+			URITemplate: vaultURIPrefix + "{path}",
+			Name:        "Vault file",
+			Description: "A file in the vault, addressed by its path relative to the vault root",
+		},
+		{
+			URITemplate: periodicURIPrefix + "{period}",
+			Name:        "Current periodic note",
+			Description: "The current daily, weekly, monthly, quarterly or yearly note",
+			MimeType:    "text/markdown",
+		},
+		{
+			URITemplate: periodicURIPrefix + "{period}/{date}",
+			Name:        "Periodic note by date",
+			Description: "The periodic note containing the given date (YYYY-MM-DD)",
+			MimeType:    "text/markdown",
+		},
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      request.ID,
+		Result: map[string]any{
+			"resourceTemplates": templates,
+		},
+	}
+}
+
+// handleResourcesRead returns the contents of a vault file or periodic note
+func (s *MCPServer) handleResourcesRead(ctx context.Context, request *MCPRequest) *MCPResponse {
+	uri, ok := request.Params["uri"].(string)
+	if !ok || uri == "" {
+		return s.createErrorResponse(request.ID, -32602, "Invalid params: missing uri")
+	}
+
+	contents, err := s.readResource(ctx, uri)
+	var apiErr *obsidian.APIError
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.NotFound()) {
+		return s.createErrorResponse(request.ID, -32603, err.Error())
+	}
+	if contents == nil {
+		return &MCPResponse{
+			JSONRPC: "2.0",
+			ID:      request.ID,
+			Error: &MCPError{
+				Code:    -32002,
+				Message: "Resource not found",
+				Data:    map[string]any{"uri": uri},
+			},
+		}
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      request.ID,
+		Result: map[string]any{
+			"contents": []ResourceContents{*contents},
+		},
+	}
+}
+
+// readResource resolves a resource URI and fetches its contents. It returns
+// nil contents when the URI does not name a resource served by this server.
+func (s *MCPServer) readResource(ctx context.Context, uri string) (*ResourceContents, error) {
+	if filename, ok := vaultFileFromURI(uri); ok {
+		file, err := s.obsidianClient.GetFile(ctx, filename)
+		if err != nil {
+			return nil, err
+		}
+
+		contents := &ResourceContents{
+			URI:      uri,
+			MimeType: file.MimeType,
+		}
+		if file.IsBinary() {
+			contents.Blob = base64.StdEncoding.EncodeToString(file.Data)
+		} else {
+			contents.Text = string(file.Data)
+		}
+		return contents, nil
+	}
+
+	if rest, ok := strings.CutPrefix(uri, periodicURIPrefix); ok && rest != "" {
+		period, date, _ := strings.Cut(rest, "/")
+		data, err := s.obsidianClient.GetPeriodicNote(ctx, period, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", uri, err)
+		}
+		return &ResourceContents{
+			URI:      uri,
+			MimeType: "text/markdown",
+			Text:     data,
+		}, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,164 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVaultServer starts a fake Obsidian server that serves a small vault
+func newVaultServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/":
+			_, _ = w.Write([]byte(`{"files": ["Welcome.md", "Daily Notes/", "image.png"]}`))
+		case "/vault/Daily Notes/":
+			_, _ = w.Write([]byte(`{"files": ["2024-01-15.md"]}`))
+		case "/vault/Daily Notes/2024-01-15.md":
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			_, _ = w.Write([]byte("# Monday"))
+		case "/vault/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte{0x89, 'P', 'N', 'G'})
+		case "/periodic/daily/2024/1/15/":
+			_, _ = w.Write([]byte("# Daily"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errorCode": 40400, "message": "Not Found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestVaultFileURIRoundTrip tests building and parsing vault file URIs
+func TestVaultFileURIRoundTrip(t *testing.T) {
+	uri := vaultFileURI("Daily Notes/2024-01-15.md")
+	assert.Equal(t, "obsidian://vault/Daily%20Notes/2024-01-15.md", uri)
+
+	filename, ok := vaultFileFromURI(uri)
+	require.True(t, ok)
+	assert.Equal(t, "Daily Notes/2024-01-15.md", filename)
+
+	_, ok = vaultFileFromURI("file:///etc/passwd")
+	assert.False(t, ok)
+}
+
+// TestHandleResourcesList tests listing vault files as resources
+func TestHandleResourcesList(t *testing.T) {
+	vault := newVaultServer(t)
+	server := NewMCPServer("test-token", vault.URL)
+
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      "res-list",
+		Method:  "resources/list",
+	})
+	require.NotNil(t, response)
+	require.Nil(t, response.Error)
+
+	result, ok := response.Result.(map[string]any)
+	require.True(t, ok)
+	assert.NotContains(t, result, "nextCursor")
+
+	resources, ok := result["resources"].([]ResourceInfo)
+	require.True(t, ok)
+	require.Len(t, resources, 3)
+	assert.Equal(t, "obsidian://vault/Daily%20Notes/2024-01-15.md", resources[0].URI)
+	assert.Equal(t, "2024-01-15.md", resources[0].Name)
+	assert.Equal(t, "text/markdown", resources[0].MimeType)
+	assert.Equal(t, "image/png", resources[2].MimeType)
+}
+
+// TestHandleResourcesListCursor tests pagination of resources/list
+func TestHandleResourcesListCursor(t *testing.T) {
+	vault := newVaultServer(t)
+	server := NewMCPServer("test-token", vault.URL)
+
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      "res-list",
+		Method:  "resources/list",
+		Params:  map[string]any{"cursor": "2"},
+	})
+	require.Nil(t, response.Error)
+	resources := response.Result.(map[string]any)["resources"].([]ResourceInfo)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "image.png", resources[0].Name)
+
+	response = server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      "res-list",
+		Method:  "resources/list",
+		Params:  map[string]any{"cursor": "bogus"},
+	})
+	require.NotNil(t, response.Error)
+	assert.Equal(t, -32602, response.Error.Code)
+}
+
+// TestHandleResourcesRead tests reading text, binary and periodic resources
+func TestHandleResourcesRead(t *testing.T) {
+	vault := newVaultServer(t)
+	server := NewMCPServer("test-token", vault.URL)
+
+	read := func(uri string) *MCPResponse {
+		return server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      "res-read",
+			Method:  "resources/read",
+			Params:  map[string]any{"uri": uri},
+		})
+	}
+
+	response := read("obsidian://vault/Daily%20Notes/2024-01-15.md")
+	require.Nil(t, response.Error)
+	contents := response.Result.(map[string]any)["contents"].([]ResourceContents)
+	require.Len(t, contents, 1)
+	assert.Equal(t, "# Monday", contents[0].Text)
+	assert.Equal(t, "text/markdown", contents[0].MimeType)
+
+	response = read("obsidian://vault/image.png")
+	require.Nil(t, response.Error)
+	contents = response.Result.(map[string]any)["contents"].([]ResourceContents)
+	assert.Empty(t, contents[0].Text)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0x89, 'P', 'N', 'G'}), contents[0].Blob)
+
+	response = read("obsidian://periodic/daily/2024-01-15")
+	require.Nil(t, response.Error)
+	contents = response.Result.(map[string]any)["contents"].([]ResourceContents)
+	assert.Equal(t, "# Daily", contents[0].Text)
+
+	response = read("https://example.com/")
+	require.NotNil(t, response.Error)
+	assert.Equal(t, -32002, response.Error.Code)
+
+	response = read("obsidian://vault/missing.md")
+	require.NotNil(t, response.Error)
+	assert.Equal(t, -32002, response.Error.Code)
+}
+
+// TestHandleResourceTemplatesList tests listing resource templates
+func TestHandleResourceTemplatesList(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123")
+
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      "res-templates",
+		Method:  "resources/templates/list",
+	})
+	require.Nil(t, response.Error)
+
+	templates := response.Result.(map[string]any)["resourceTemplates"].([]ResourceTemplate)
+	uriTemplates := make([]string, len(templates))
+	for i, template := range templates {
+		uriTemplates[i] = template.URITemplate
+	}
+	assert.Contains(t, uriTemplates, "obsidian://vault/{path}")
+	assert.Contains(t, uriTemplates, "obsidian://periodic/{period}/{date}")
+}
@@ -0,0 +1,558 @@
+package mcp
+
+// Output schemas describe the structuredContent returned by each tool. They
+// mirror the result types of the obsidian client.
+
+// messageOutputSchema describes tools that only report what they did
+var messageOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"message": map[string]any{
+			"type":        "string",
+			"description": "Description of the completed operation",
+		},
+	},
+	"required": []string{"message"},
+}
+
+// changeOutputSchema describes tools that change a file and can preview the
+// change as a unified diff instead
+var changeOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"message": map[string]any{
+			"type":        "string",
+			"description": "Description of the completed operation",
+		},
+		"dryRun":  map[string]any{"type": "boolean"},
+		"changed": map[string]any{"type": "boolean", "description": "Whether the change would modify the file"},
+		"diff":    map[string]any{"type": "string", "description": "Unified diff of the change that would be made"},
+	},
+	"required": []string{"message"},
+}
+
+// serverStatusOutputSchema describes obsidian.ServerStatus
+var serverStatusOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"status":        map[string]any{"type": "string"},
+		"service":       map[string]any{"type": "string"},
+		"authenticated": map[string]any{"type": "boolean"},
+		"versions": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"obsidian": map[string]any{"type": "string"},
+				"self":     map[string]any{"type": "string"},
+			},
+		},
+	},
+	"required": []string{"authenticated"},
+}
+
+// fileListOutputSchema describes obsidian.FileList
+var fileListOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"files": map[string]any{
+			"type":        "array",
+			"description": "Paths relative to the listed directory; directories end with a slash",
+			"items":       map[string]any{"type": "string"},
+		},
+	},
+	"required": []string{"files"},
+}
+
+// noteSchema describes obsidian.Note
+var noteSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"path":        map[string]any{"type": "string"},
+		"content":     map[string]any{"type": "string"},
+		"frontmatter": map[string]any{"type": "object"},
+		"tags": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+		"stat": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"ctime": map[string]any{"type": "integer"},
+				"mtime": map[string]any{"type": "integer"},
+				"size":  map[string]any{"type": "integer"},
+			},
+		},
+		"hash": map[string]any{"type": "string", "description": "SHA-256 hash of the content, usable as expectedHash"},
+	},
+	"required": []string{"content"},
+}
+
+// fileContentOutputSchema describes the results of get_file_content: a note,
+// or the media type and size of a binary file returned as image or resource
+// content
+var fileContentOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"path":        map[string]any{"type": "string"},
+		"content":     map[string]any{"type": "string"},
+		"frontmatter": map[string]any{"type": "object"},
+		"tags": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+		"stat":     noteSchema["properties"].(map[string]any)["stat"],
+		"hash":     noteSchema["properties"].(map[string]any)["hash"],
+		"mimeType": map[string]any{"type": "string", "description": "Media type of a binary file"},
+		"size":     map[string]any{"type": "integer", "description": "Size of a binary file in bytes"},
+	},
+}
+
+// multipleFilesOutputSchema describes the results of get_multiple_files
+var multipleFilesOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"files": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{"type": "string"},
+					"content":  map[string]any{"type": "string"},
+					"note":     noteSchema,
+					"error":    map[string]any{"type": "string"},
+				},
+				"required": []string{"filename"},
+			},
+		},
+	},
+	"required": []string{"files"},
+}
+
+// searchResultsOutputSchema describes a list of obsidian.SearchResult
+var searchResultsOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"results": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{"type": "string"},
+					"score":    map[string]any{"type": "number"},
+					"matches": map[string]any{
+						"type": "array",
+						"items": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"context": map[string]any{"type": "string"},
+								"match": map[string]any{
+									"type": "object",
+									"properties": map[string]any{
+										"start": map[string]any{"type": "integer"},
+										"end":   map[string]any{"type": "integer"},
+									},
+								},
+							},
+						},
+					},
+				},
+				"required": []string{"filename"},
+			},
+		},
+	},
+	"required": []string{"results"},
+}
+
+// advancedSearchResultsOutputSchema describes a list of obsidian.AdvancedSearchResult
+var advancedSearchResultsOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"results": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{"type": "string"},
+					"result":   map[string]any{"description": "Value the query produced for the file"},
+				},
+				"required": []string{"filename"},
+			},
+		},
+	},
+	"required": []string{"results"},
+}
+
+// commandListOutputSchema describes obsidian.CommandList
+var commandListOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"commands": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id":   map[string]any{"type": "string"},
+					"name": map[string]any{"type": "string"},
+				},
+				"required": []string{"id", "name"},
+			},
+		},
+	},
+	"required": []string{"commands"},
+}
+
+// moveResultOutputSchema describes obsidian.MoveResult
+var moveResultOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"source":      map[string]any{"type": "string"},
+		"destination": map[string]any{"type": "string"},
+		"dryRun":      map[string]any{"type": "boolean"},
+		"updatedFiles": map[string]any{
+			"type":        "array",
+			"description": "Files whose links were (or would be) rewritten",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path": map[string]any{"type": "string"},
+					"changes": map[string]any{
+						"type": "array",
+						"items": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"line": map[string]any{"type": "integer"},
+								"old":  map[string]any{"type": "string"},
+								"new":  map[string]any{"type": "string"},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+	"required": []string{"source", "destination", "dryRun", "updatedFiles"},
+}
+
+// linkSchema describes links.Edge
+var linkSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"source":  map[string]any{"type": "string", "description": "Note containing the link"},
+		"target":  map[string]any{"type": "string", "description": "File the link resolves to, absent for broken links"},
+		"path":    map[string]any{"type": "string", "description": "Link target as written"},
+		"heading": map[string]any{"type": "string"},
+		"block":   map[string]any{"type": "string"},
+		"alias":   map[string]any{"type": "string"},
+		"embed":   map[string]any{"type": "boolean"},
+		"line":    map[string]any{"type": "integer"},
+		"raw":     map[string]any{"type": "string"},
+	},
+	"required": []string{"source", "path", "line", "raw"},
+}
+
+// backlinksOutputSchema describes the results of get_backlinks
+var backlinksOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"file":      map[string]any{"type": "string"},
+		"backlinks": map[string]any{"type": "array", "items": linkSchema},
+	},
+	"required": []string{"file", "backlinks"},
+}
+
+// outgoingLinksOutputSchema describes the results of get_outgoing_links
+var outgoingLinksOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"file":  map[string]any{"type": "string"},
+		"links": map[string]any{"type": "array", "items": linkSchema},
+	},
+	"required": []string{"file", "links"},
+}
+
+// orphanNotesOutputSchema describes the results of find_orphan_notes
+var orphanNotesOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"orphans": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+	},
+	"required": []string{"orphans"},
+}
+
+// brokenLinksOutputSchema describes the results of find_broken_links
+var brokenLinksOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"brokenLinks": map[string]any{"type": "array", "items": linkSchema},
+	},
+	"required": []string{"brokenLinks"},
+}
+
+// tagNodeSchema describes tags.Node. Children have the same shape.
+var tagNodeSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"tag":   map[string]any{"type": "string", "description": "Full tag without the leading #"},
+		"name":  map[string]any{"type": "string", "description": "Last segment of the tag"},
+		"count": map[string]any{"type": "integer", "description": "Notes tagged with exactly this tag"},
+		"total": map[string]any{"type": "integer", "description": "Notes tagged with this tag or a nested one"},
+		"children": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "object"},
+		},
+	},
+	"required": []string{"tag", "name", "count", "total"},
+}
+
+// tagListOutputSchema describes the results of list_tags
+var tagListOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"tags": map[string]any{"type": "array", "items": tagNodeSchema},
+	},
+	"required": []string{"tags"},
+}
+
+// taggedNotesOutputSchema describes the results of find_notes_by_tag
+var taggedNotesOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"tag": map[string]any{"type": "string"},
+		"notes": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+	},
+	"required": []string{"tag", "notes"},
+}
+
+// renameTagsOutputSchema describes tags.RenameResult
+var renameTagsOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"tags": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+		"target": map[string]any{"type": "string"},
+		"dryRun": map[string]any{"type": "boolean"},
+		"updatedFiles": map[string]any{
+			"type":        "array",
+			"description": "Files whose tags were (or would be) rewritten",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path": map[string]any{"type": "string"},
+					"changes": map[string]any{
+						"type": "array",
+						"items": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"line": map[string]any{"type": "integer"},
+								"old":  map[string]any{"type": "string"},
+								"new":  map[string]any{"type": "string"},
+							},
+						},
+					},
+				},
+			},
+		},
+		"errors": map[string]any{
+			"type":                 "object",
+			"description":          "Notes that could not be updated, such as those changed while renaming, and why",
+			"additionalProperties": map[string]any{"type": "string"},
+		},
+	},
+	"required": []string{"tags", "target", "dryRun", "updatedFiles"},
+}
+
+// replaceOutputSchema describes replaceResult
+var replaceOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"pattern":      map[string]any{"type": "string"},
+		"dryRun":       map[string]any{"type": "boolean"},
+		"replacements": map[string]any{"type": "integer", "description": "Number of matches replaced in all notes"},
+		"files": map[string]any{
+			"type":        "array",
+			"description": "Notes that were (or would be) changed",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path":         map[string]any{"type": "string"},
+					"replacements": map[string]any{"type": "integer"},
+					"diff":         map[string]any{"type": "string", "description": "Unified diff of the change, for dry runs"},
+				},
+			},
+		},
+		"errors": map[string]any{
+			"type":                 "object",
+			"description":          "Notes that could not be read or updated and why",
+			"additionalProperties": map[string]any{"type": "string"},
+		},
+	},
+	"required": []string{"pattern", "dryRun", "replacements", "files"},
+}
+
+// frontmatterOutputSchema describes the results of get_frontmatter, which
+// holds either all properties or the requested one
+var frontmatterOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"path":        map[string]any{"type": "string"},
+		"frontmatter": map[string]any{"type": "object"},
+		"field":       map[string]any{"type": "string"},
+		"value":       map[string]any{"description": "Value of the requested property"},
+		"exists":      map[string]any{"type": "boolean", "description": "Whether the requested property is set"},
+	},
+	"required": []string{"path"},
+}
+
+// frontmatterUpdateOutputSchema describes obsidian.FrontmatterUpdate
+var frontmatterUpdateOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"field":  map[string]any{"type": "string"},
+		"dryRun": map[string]any{"type": "boolean"},
+		"updatedFiles": map[string]any{
+			"type":        "array",
+			"description": "Notes that were (or would be) updated",
+			"items":       map[string]any{"type": "string"},
+		},
+		"unchangedFiles": map[string]any{
+			"type":        "array",
+			"description": "Notes that already had the value",
+			"items":       map[string]any{"type": "string"},
+		},
+		"errors": map[string]any{
+			"type":                 "object",
+			"description":          "Notes that could not be updated and why",
+			"additionalProperties": map[string]any{"type": "string"},
+		},
+	},
+	"required": []string{"field", "dryRun", "updatedFiles", "unchangedFiles"},
+}
+
+// taskListOutputSchema describes the results of list_tasks
+var taskListOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"tasks": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path":   map[string]any{"type": "string"},
+					"line":   map[string]any{"type": "integer"},
+					"status": map[string]any{"type": "string"},
+					"symbol": map[string]any{"type": "string", "description": "Character between the checkbox brackets"},
+					"text":   map[string]any{"type": "string"},
+					"due":    map[string]any{"type": "string"},
+					"tags": map[string]any{
+						"type":  "array",
+						"items": map[string]any{"type": "string"},
+					},
+					"blockId": map[string]any{"type": "string"},
+				},
+				"required": []string{"path", "line", "status", "symbol", "text"},
+			},
+		},
+	},
+	"required": []string{"tasks"},
+}
+
+// blockSchema describes markdown.Block
+var blockSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"id":   map[string]any{"type": "string"},
+		"line": map[string]any{"type": "integer"},
+	},
+	"required": []string{"id", "line"},
+}
+
+// outlineOutputSchema describes the results of get_note_outline. Children
+// have the same shape as the headings.
+var outlineOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"path":  map[string]any{"type": "string"},
+		"lines": map[string]any{"type": "integer", "description": "Number of lines in the note"},
+		"blocks": map[string]any{
+			"type":        "array",
+			"description": "Block IDs before the first heading",
+			"items":       blockSchema,
+		},
+		"headings": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"heading":   map[string]any{"type": "string"},
+					"level":     map[string]any{"type": "integer"},
+					"startLine": map[string]any{"type": "integer"},
+					"endLine":   map[string]any{"type": "integer", "description": "Last line of the section, including subsections"},
+					"blocks":    map[string]any{"type": "array", "items": blockSchema},
+					"children": map[string]any{
+						"type":  "array",
+						"items": map[string]any{"type": "object"},
+					},
+				},
+				"required": []string{"heading", "level", "startLine", "endLine"},
+			},
+		},
+	},
+	"required": []string{"path", "lines", "headings"},
+}
+
+// sectionOutputSchema describes the results of get_note_section
+var sectionOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"path":      map[string]any{"type": "string"},
+		"heading":   map[string]any{"type": "string"},
+		"startLine": map[string]any{"type": "integer", "description": "First line returned, after the heading unless it is included"},
+		"endLine":   map[string]any{"type": "integer"},
+		"content":   map[string]any{"type": "string"},
+	},
+	"required": []string{"path", "heading", "startLine", "endLine", "content"},
+}
+
+// snapshotListOutputSchema describes the results of list_snapshots
+var snapshotListOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"snapshots": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id":          map[string]any{"type": "string"},
+					"path":        map[string]any{"type": "string"},
+					"operation":   map[string]any{"type": "string", "description": "Change the snapshot was taken before: update, append, patch, delete or restore"},
+					"operationId": map[string]any{"type": "string", "description": "Tool call the snapshot was taken for, shared by every file it changed"},
+					"time":        map[string]any{"type": "string", "format": "date-time"},
+					"exists":      map[string]any{"type": "boolean", "description": "False when the change created the file"},
+					"size":        map[string]any{"type": "integer"},
+					"undone":      map[string]any{"type": "boolean"},
+				},
+				"required": []string{"id", "path", "operation", "time", "exists", "size"},
+			},
+		},
+	},
+	"required": []string{"snapshots"},
+}
+
+// snapshotDiffOutputSchema describes the results of diff_snapshot
+var snapshotDiffOutputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"id":      map[string]any{"type": "string"},
+		"path":    map[string]any{"type": "string"},
+		"changed": map[string]any{"type": "boolean"},
+		"diff":    map[string]any{"type": "string", "description": "Unified diff from the snapshot to the current content"},
+	},
+	"required": []string{"id", "path", "changed", "diff"},
+}
@@ -1,31 +1,151 @@
 package mcp
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/audit"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/index"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/markdown"
 	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/snapshot"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/tags"
 )
 
+const (
+	// defaultPollInterval is how often subscribed resources are checked for changes
+	defaultPollInterval = 5 * time.Second
+	// defaultMaxConcurrency is the number of requests handled in parallel
+	defaultMaxConcurrency = 8
+	// defaultProtocolVersion is answered to clients that request no supported version
+	defaultProtocolVersion = "2024-11-05"
+)
+
+// supportedProtocolVersions lists the MCP revisions the server can speak.
+// Structured tool output requires 2025-06-18 but is harmless to older clients.
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
 // MCPServer represents the MCP server instance
 type MCPServer struct {
 	obsidianClient *obsidian.Client
 	stdin          io.Reader
 	stdout         io.Writer
 	stderr         io.Writer
+
+	pollInterval   time.Duration
+	promptsFolder  string
+	maxConcurrency int
+	indexCache     string
+	policy         ToolPolicy
+	pathRules      obsidian.PathRules
+
+	// snapshots holds the content of files before they were changed, when set
+	snapshots *snapshot.Store
+
+	// audit records tool calls when set
+	audit *audit.Logger
+
+	// index holds the notes read by vault-wide tools
+	index *index.Index
+
+	// slots bounds the number of requests handled concurrently
+	slots chan struct{}
+
+	// httpToken is the bearer token HTTP clients must present, when set
+	httpToken string
+	// allowedHosts are the host names HTTP requests may be addressed to
+	// besides loopback names and IP addresses
+	allowedHosts []string
+	// sessionIdleTimeout is how long an HTTP session may go unused before it
+	// is closed
+	sessionIdleTimeout time.Duration
+
+	// stdio is the session of the client connected over stdin/stdout
+	stdio      *session
+	sessions   map[string]*session
+	sessionsMu sync.Mutex
+}
+
+// Option configures optional MCPServer behavior
+type Option func(*MCPServer)
+
+// WithPollInterval sets how often subscribed resources are polled for changes
+func WithPollInterval(interval time.Duration) Option {
+	return func(s *MCPServer) {
+		s.pollInterval = interval
+	}
+}
+
+// WithPromptsFolder sets the vault folder user-defined prompts are loaded
+// from. An empty folder disables user-defined prompts.
+func WithPromptsFolder(folder string) Option {
+	return func(s *MCPServer) {
+		s.promptsFolder = folder
+	}
+}
+
+// WithMaxConcurrency sets the maximum number of requests handled in parallel
+func WithMaxConcurrency(n int) Option {
+	return func(s *MCPServer) {
+		s.maxConcurrency = max(n, 1)
+	}
+}
+
+// WithIndexCache sets the file the vault index is persisted to between runs.
+// An empty path keeps the index in memory only.
+func WithIndexCache(path string) Option {
+	return func(s *MCPServer) {
+		s.indexCache = path
+	}
+}
+
+// WithPathRules restricts the vault paths tools and resources can access
+func WithPathRules(rules obsidian.PathRules) Option {
+	return func(s *MCPServer) {
+		s.pathRules = rules
+	}
 }
 
 // NewMCPServer creates a new MCP server instance
-func NewMCPServer(apiToken, baseURL string) *MCPServer {
-	client := obsidian.NewClient(apiToken, baseURL)
-	return &MCPServer{
-		obsidianClient: client,
+func NewMCPServer(apiToken, baseURL string, opts ...Option) *MCPServer {
+	s := &MCPServer{
 		stdin:          os.Stdin,
 		stdout:         os.Stdout,
 		stderr:         os.Stderr,
+		pollInterval:   defaultPollInterval,
+		promptsFolder:  defaultPromptsFolder,
+		maxConcurrency: defaultMaxConcurrency,
+		sessions:       make(map[string]*session),
+
+		sessionIdleTimeout: defaultSessionIdleTimeout,
+	}
+	s.stdio = newSession("", func(data []byte) error {
+		_, err := s.stdout.Write(append(data, '\n'))
+		return err
+	})
+	s.addSession(s.stdio)
+	for _, opt := range opts {
+		opt(s)
+	}
+	clientOpts := []obsidian.ClientOption{obsidian.WithPathRules(s.pathRules)}
+	if s.snapshots != nil {
+		clientOpts = append(clientOpts, obsidian.WithSnapshotter(s.snapshots))
 	}
+	s.obsidianClient = obsidian.NewClient(apiToken, baseURL, clientOpts...)
+	s.slots = make(chan struct{}, s.maxConcurrency)
+	s.index = index.New(s.obsidianClient, s.indexCache)
+	return s
 }
 
 // MCPRequest represents an incoming MCP request
@@ -44,6 +164,13 @@ type MCPResponse struct {
 	Error   *MCPError `json:"error,omitempty"`
 }
 
+// MCPNotification represents a server-initiated notification
+type MCPNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
 // MCPError represents an MCP error response
 type MCPError struct {
 	Code    int    `json:"code"`
@@ -53,9 +180,10 @@ type MCPError struct {
 
 // ToolInfo represents information about available tools
 type ToolInfo struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	InputSchema any    `json:"inputSchema"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	InputSchema  any    `json:"inputSchema"`
+	OutputSchema any    `json:"outputSchema,omitempty"`
 }
 
 // ServerInfo represents server information
@@ -73,8 +201,25 @@ type Capabilities struct {
 	Sampling  any `json:"sampling,omitempty"`
 }
 
-// Run starts the MCP server and handles requests
+// Run starts the MCP server and handles requests. Requests are handled
+// concurrently, so responses may be written in a different order than the
+// requests were received.
 func (s *MCPServer) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	var pollWG sync.WaitGroup
+	pollWG.Add(1)
+	go func() {
+		defer pollWG.Done()
+		s.pollSubscriptions(ctx)
+	}()
+	defer pollWG.Wait()
+	defer cancel()
+
+	var (
+		requestWG sync.WaitGroup
+		sendOnce  sync.Once
+		sendErr   error
+	)
 	decoder := json.NewDecoder(s.stdin)
 
 	for {
@@ -87,26 +232,98 @@ func (s *MCPServer) Run() error {
 			continue
 		}
 
-		response := s.handleRequest(&request)
-		if response != nil {
+		requestWG.Add(1)
+		go func(request *MCPRequest) {
+			defer requestWG.Done()
+			response := s.handleRequest(ctx, request)
+			if response == nil {
+				return
+			}
 			if err := s.sendResponse(response); err != nil {
-				return fmt.Errorf("failed to send response: %w", err)
+				sendOnce.Do(func() {
+					sendErr = fmt.Errorf("failed to send response: %w", err)
+				})
 			}
-		}
+		}(&request)
+	}
+
+	requestWG.Wait()
+	return sendErr
+}
+
+// handleRequest processes incoming MCP requests. The context carries the
+// session of the client the request came from. Notifications and cancelled
+// requests produce no response.
+func (s *MCPServer) handleRequest(ctx context.Context, request *MCPRequest) *MCPResponse {
+	if request.ID == nil {
+		s.handleNotification(ctx, request)
+		return nil
+	}
+
+	if !validRequestID(request.ID) {
+		return s.createErrorResponse(nil, -32600, "Invalid Request: id must be a string or a number")
+	}
+
+	ctx, done := s.sessionFor(ctx).track(ctx, request.ID)
+	defer done()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return nil
+	}
+
+	response := s.dispatchRequest(ctx, request)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return response
+}
+
+// validRequestID reports whether a JSON-RPC request ID is a string or a
+// number. Other values cannot key the in-flight requests of a session.
+func validRequestID(id any) bool {
+	switch id.(type) {
+	case string, float64, int, int64, json.Number:
+		return true
 	}
+	return false
+}
 
-	return nil
+// handleNotification processes notifications sent by the client
+func (s *MCPServer) handleNotification(ctx context.Context, request *MCPRequest) {
+	switch request.Method {
+	case "notifications/cancelled":
+		if requestID := request.Params["requestId"]; requestID != nil && validRequestID(requestID) {
+			s.sessionFor(ctx).cancel(requestID)
+		}
+	}
 }
 
-// handleRequest processes incoming MCP requests
-func (s *MCPServer) handleRequest(request *MCPRequest) *MCPResponse {
+// dispatchRequest routes a request to the handler for its method
+func (s *MCPServer) dispatchRequest(ctx context.Context, request *MCPRequest) *MCPResponse {
 	switch request.Method {
 	case "initialize":
-		return s.handleInitialize(request)
+		return s.handleInitialize(ctx, request)
 	case "tools/list":
 		return s.handleToolsList(request)
 	case "tools/call":
-		return s.handleToolsCall(request)
+		return s.handleToolsCall(ctx, request)
+	case "prompts/list":
+		return s.handlePromptsList(ctx, request)
+	case "prompts/get":
+		return s.handlePromptsGet(ctx, request)
+	case "resources/list":
+		return s.handleResourcesList(ctx, request)
+	case "resources/templates/list":
+		return s.handleResourceTemplatesList(request)
+	case "resources/read":
+		return s.handleResourcesRead(ctx, request)
+	case "resources/subscribe":
+		return s.handleResourcesSubscribe(ctx, request)
+	case "resources/unsubscribe":
+		return s.handleResourcesUnsubscribe(ctx, request)
 	case "ping":
 		return s.handlePing(request)
 	default:
@@ -123,14 +340,27 @@ func (s *MCPServer) handleRequest(request *MCPRequest) *MCPResponse {
 }
 
 // handleInitialize handles the initialize request
-func (s *MCPServer) handleInitialize(request *MCPRequest) *MCPResponse {
+func (s *MCPServer) handleInitialize(ctx context.Context, request *MCPRequest) *MCPResponse {
+	if info, ok := request.Params["clientInfo"].(map[string]any); ok {
+		s.sessionFor(ctx).setClientInfo(info)
+	}
+
+	protocolVersion := defaultProtocolVersion
+	if requested, ok := request.Params["protocolVersion"].(string); ok && slices.Contains(supportedProtocolVersions, requested) {
+		protocolVersion = requested
+	}
+
 	return &MCPResponse{
 		JSONRPC: "2.0",
 		ID:      request.ID,
 		Result: map[string]any{
-			"protocolVersion": "2024-11-05",
+			"protocolVersion": protocolVersion,
 			"capabilities": Capabilities{
-				Tools: map[string]any{},
+				Tools:   map[string]any{},
+				Prompts: map[string]any{},
+				Resources: map[string]any{
+					"subscribe": true,
+				},
 			},
 			"serverInfo": ServerInfo{
 				Name:    "obsidian-mcp-server",
@@ -159,6 +389,7 @@ func (s *MCPServer) handleToolsList(request *MCPRequest) *MCPResponse {
 				"type":       "object",
 				"properties": map[string]any{},
 			},
+			OutputSchema: serverStatusOutputSchema,
 		},
 		{
 			Name:        "list_vault_files",
@@ -170,12 +401,17 @@ func (s *MCPServer) handleToolsList(request *MCPRequest) *MCPResponse {
 						"type":        "string",
 						"description": "Directory path relative to vault root (optional, defaults to root)",
 					},
+					"recursive": map[string]any{
+						"type":        "boolean",
+						"description": "List files in all sub-directories as well (default: false)",
+					},
 				},
 			},
+			OutputSchema: fileListOutputSchema,
 		},
 		{
 			Name:        "get_file_content",
-			Description: "Get the content of a specific file, supports both markdown and JSON format",
+			Description: "Get the content of a specific file, supports both markdown and JSON format. Images are returned as image content and other binary files as embedded resources",
 			InputSchema: map[string]any{
 				"type": "object",
 				"properties": map[string]any{
@@ -191,6 +427,74 @@ func (s *MCPServer) handleToolsList(request *MCPRequest) *MCPResponse {
 				},
 				"required": []string{"filename"},
 			},
+			OutputSchema: fileContentOutputSchema,
+		},
+		{
+			Name:        "get_multiple_files",
+			Description: "Get the content of several files at once, supports both markdown and JSON format",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filenames": map[string]any{
+						"type":        "array",
+						"description": "Paths to the files relative to vault root",
+						"items":       map[string]any{"type": "string"},
+					},
+					"format": map[string]any{
+						"type":        "string",
+						"description": "Response format: 'markdown' (default) or 'json' (includes metadata)",
+						"enum":        []string{"markdown", "json"},
+					},
+				},
+				"required": []string{"filenames"},
+			},
+			OutputSchema: multipleFilesOutputSchema,
+		},
+		{
+			Name:        "get_note_outline",
+			Description: "Get the heading tree of a note with the line range of each section and its block IDs, to read only the parts needed",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{
+						"type":        "string",
+						"description": "Path to the note relative to vault root",
+					},
+				},
+				"required": []string{"filename"},
+			},
+			OutputSchema: outlineOutputSchema,
+		},
+		{
+			Name:        "get_note_section",
+			Description: "Get the content of a note below a heading, up to the next heading of the same or a higher level",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{
+						"type":        "string",
+						"description": "Path to the note relative to vault root",
+					},
+					"heading": map[string]any{
+						"type":        "string",
+						"description": "Heading path starting at a top-level heading, such as 'Projects::Alpha'",
+					},
+					"delimiter": map[string]any{
+						"type":        "string",
+						"description": "Delimiter between the headings of the path (default: '::')",
+					},
+					"includeSubsections": map[string]any{
+						"type":        "boolean",
+						"description": "Include the subsections below the heading (default: true)",
+					},
+					"includeHeading": map[string]any{
+						"type":        "boolean",
+						"description": "Include the heading line itself (default: false)",
+					},
+				},
+				"required": []string{"filename", "heading"},
+			},
+			OutputSchema: sectionOutputSchema,
 		},
 		{
 			Name:        "create_or_update_file",
@@ -210,9 +514,14 @@ func (s *MCPServer) handleToolsList(request *MCPRequest) *MCPResponse {
 						"type":        "string",
 						"description": "Content type (defaults to 'text/markdown')",
 					},
+					"dryRun": map[string]any{
+						"type":        "boolean",
+						"description": "Only return a unified diff of the change without writing it (default: false)",
+					},
 				},
 				"required": []string{"filename", "content"},
 			},
+			OutputSchema: changeOutputSchema,
 		},
 		{
 			Name:        "append_to_file",
@@ -228,9 +537,14 @@ func (s *MCPServer) handleToolsList(request *MCPRequest) *MCPResponse {
 						"type":        "string",
 						"description": "Content to append to the file",
 					},
+					"dryRun": map[string]any{
+						"type":        "boolean",
+						"description": "Only return a unified diff of the change without writing it (default: false)",
+					},
 				},
 				"required": []string{"filename", "content"},
 			},
+			OutputSchema: changeOutputSchema,
 		},
 		{
 			Name:        "patch_file_content",
@@ -268,9 +582,14 @@ func (s *MCPServer) handleToolsList(request *MCPRequest) *MCPResponse {
 						"type":        "string",
 						"description": "Delimiter for nested targets (defaults to '::')",
 					},
+					"dryRun": map[string]any{
+						"type":        "boolean",
+						"description": "Only return a unified diff of the change without writing it (default: false)",
+					},
 				},
 				"required": []string{"filename", "operation", "targetType", "target", "content"},
 			},
+			OutputSchema: changeOutputSchema,
 		},
 		{
 			Name:        "delete_file",
@@ -282,72 +601,126 @@ func (s *MCPServer) handleToolsList(request *MCPRequest) *MCPResponse {
 						"type":        "string",
 						"description": "Path to the file relative to vault root",
 					},
+					"dryRun": map[string]any{
+						"type":        "boolean",
+						"description": "Only return a unified diff of the change without writing it (default: false)",
+					},
 				},
 				"required": []string{"filename"},
 			},
+			OutputSchema: changeOutputSchema,
 		},
 		{
-			Name:        "search_vault_simple",
-			Description: "Simple text search across the vault",
+			Name:        "upload_attachment",
+			Description: "Upload a binary file such as an image or PDF to the vault from base64 data",
 			InputSchema: map[string]any{
 				"type": "object",
 				"properties": map[string]any{
-					"query": map[string]any{
+					"filename": map[string]any{
 						"type":        "string",
-						"description": "Search query",
+						"description": "Path of the attachment relative to vault root, including its extension",
 					},
-					"contextLength": map[string]any{
-						"type":        "integer",
-						"description": "Amount of context to return around matches (default: 100)",
+					"data": map[string]any{
+						"type":        "string",
+						"description": "Base64-encoded file content; a data: URL is also accepted",
+					},
+					"mimeType": map[string]any{
+						"type":        "string",
+						"description": "Content type of the file (default: derived from the extension)",
 					},
 				},
-				"required": []string{"query"},
+				"required": []string{"filename", "data"},
 			},
+			OutputSchema: messageOutputSchema,
 		},
 		{
-			Name:        "search_vault_advanced",
-			Description: "Advanced search using Dataview DQL or JsonLogic queries",
+			Name:        "move_file",
+			Description: "Move or rename a file and update every wikilink, embed and markdown link pointing at it",
 			InputSchema: map[string]any{
 				"type": "object",
 				"properties": map[string]any{
-					"query": map[string]any{
+					"source": map[string]any{
 						"type":        "string",
-						"description": "Search query (DQL or JsonLogic)",
+						"description": "Current path of the file relative to vault root",
 					},
-					"queryType": map[string]any{
+					"destination": map[string]any{
 						"type":        "string",
-						"description": "Query type",
-						"enum":        []string{"dataview", "jsonlogic"},
+						"description": "New path of the file relative to vault root",
+					},
+					"dryRun": map[string]any{
+						"type":        "boolean",
+						"description": "Only report the changes that would be made (default: false)",
 					},
 				},
-				"required": []string{"query", "queryType"},
+				"required": []string{"source", "destination"},
 			},
+			OutputSchema: moveResultOutputSchema,
 		},
 		{
-			Name:        "list_commands",
-			Description: "Get a list of available Obsidian commands",
+			Name:        "list_snapshots",
+			Description: "List the snapshots taken of files before they were changed, newest first",
 			InputSchema: map[string]any{
-				"type":       "object",
-				"properties": map[string]any{},
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{
+						"type":        "string",
+						"description": "Only list snapshots of this file (default: all files)",
+					},
+					"limit": map[string]any{
+						"type":        "integer",
+						"description": "Maximum number of snapshots to return (default: 50)",
+					},
+				},
 			},
+			OutputSchema: snapshotListOutputSchema,
 		},
 		{
-			Name:        "execute_command",
-			Description: "Execute a specific Obsidian command",
+			Name:        "diff_snapshot",
+			Description: "Show a unified diff from a snapshot to the current content of its file",
 			InputSchema: map[string]any{
 				"type": "object",
 				"properties": map[string]any{
-					"commandId": map[string]any{
+					"id": map[string]any{
 						"type":        "string",
-						"description": "ID of the command to execute",
+						"description": "ID of the snapshot",
 					},
 				},
-				"required": []string{"commandId"},
+				"required": []string{"id"},
 			},
+			OutputSchema: snapshotDiffOutputSchema,
 		},
 		{
-			Name:        "open_file",
-			Description: "Open a file in the Obsidian UI",
+			Name:        "restore_snapshot",
+			Description: "Restore a file to the content it had when a snapshot was taken",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id": map[string]any{
+						"type":        "string",
+						"description": "ID of the snapshot",
+					},
+				},
+				"required": []string{"id"},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "undo_last_change",
+			Description: "Roll back the most recent change that has not been undone yet",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{
+						"type":        "string",
+						"description": "Only undo changes to this file (default: the latest change to any file)",
+					},
+				},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "get_backlinks",
+			Description: "List the links from other notes pointing at a file",
 			InputSchema: map[string]any{
 				"type": "object",
 				"properties": map[string]any{
@@ -355,118 +728,897 @@ func (s *MCPServer) handleToolsList(request *MCPRequest) *MCPResponse {
 						"type":        "string",
 						"description": "Path to the file relative to vault root",
 					},
-					"newLeaf": map[string]any{
-						"type":        "boolean",
-						"description": "Open in a new leaf (default: false)",
+				},
+				"required": []string{"filename"},
+			},
+			OutputSchema: backlinksOutputSchema,
+		},
+		{
+			Name:        "get_outgoing_links",
+			Description: "List the wikilinks, embeds and markdown links of a note and the files they resolve to",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{
+						"type":        "string",
+						"description": "Path to the note relative to vault root",
 					},
 				},
 				"required": []string{"filename"},
 			},
+			OutputSchema: outgoingLinksOutputSchema,
 		},
-	}
-
-	return &MCPResponse{
-		JSONRPC: "2.0",
-		ID:      request.ID,
-		Result: map[string]any{
-			"tools": tools,
+		{
+			Name:        "find_orphan_notes",
+			Description: "Find markdown notes that no other note links to",
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{},
+			},
+			OutputSchema: orphanNotesOutputSchema,
 		},
-	}
-}
-
-// handleToolsCall handles tool execution requests
-func (s *MCPServer) handleToolsCall(request *MCPRequest) *MCPResponse {
-	params, ok := request.Params["arguments"].(map[string]any)
-	if !ok {
-		params = make(map[string]any)
-	}
-
-	name, ok := request.Params["name"].(string)
-	if !ok {
-		return s.createErrorResponse(request.ID, -32602, "Invalid params: missing tool name")
-	}
-
-	result, err := s.executeTool(name, params)
-	if err != nil {
-		return s.createErrorResponse(request.ID, -32603, err.Error())
-	}
-
-	return &MCPResponse{
-		JSONRPC: "2.0",
-		ID:      request.ID,
-		Result: map[string]any{
-			"content": []map[string]any{
-				{
-					"type": "text",
-					"text": result,
+		{
+			Name:        "find_broken_links",
+			Description: "Find links that do not resolve to any file in the vault",
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{},
+			},
+			OutputSchema: brokenLinksOutputSchema,
+		},
+		{
+			Name:        "get_frontmatter",
+			Description: "Get the frontmatter properties of a note, or a single property",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{
+						"type":        "string",
+						"description": "Path to the note relative to vault root",
+					},
+					"field": map[string]any{
+						"type":        "string",
+						"description": "Only return this property",
+					},
 				},
+				"required": []string{"filename"},
 			},
+			OutputSchema: frontmatterOutputSchema,
 		},
-	}
-}
-
-// executeTool executes the specified tool with given parameters
-func (s *MCPServer) executeTool(name string, params map[string]any) (string, error) {
-	switch name {
-	case "get_server_info":
-		return s.obsidianClient.GetServerInfo()
-	case "list_vault_files":
-		path, _ := params["path"].(string)
-		return s.obsidianClient.ListVaultFiles(path)
-	case "get_file_content":
-		filename, ok := params["filename"].(string)
-		if !ok {
-			return "", fmt.Errorf("filename is required")
-		}
-		format, _ := params["format"].(string)
-		if format == "" {
-			format = "markdown"
-		}
-		return s.obsidianClient.GetFileContent(filename, format)
-	case "create_or_update_file":
-		filename, ok := params["filename"].(string)
-		if !ok {
-			return "", fmt.Errorf("filename is required")
-		}
-		content, ok := params["content"].(string)
-		if !ok {
-			return "", fmt.Errorf("content is required")
-		}
-		contentType, _ := params["contentType"].(string)
+		{
+			Name:        "set_frontmatter_field",
+			Description: "Set a frontmatter property of a note to a string, number, boolean, list or object, keeping the order of the other properties and YAML comments",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{
+						"type":        "string",
+						"description": "Path to the note relative to vault root",
+					},
+					"field": map[string]any{
+						"type":        "string",
+						"description": "Property to set",
+					},
+					"value": map[string]any{
+						"description": "New value of the property, as any JSON value",
+					},
+				},
+				"required": []string{"filename", "field", "value"},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "delete_frontmatter_field",
+			Description: "Remove a property from the frontmatter of a note",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{
+						"type":        "string",
+						"description": "Path to the note relative to vault root",
+					},
+					"field": map[string]any{
+						"type":        "string",
+						"description": "Property to remove",
+					},
+				},
+				"required": []string{"filename", "field"},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "bulk_update_frontmatter",
+			Description: "Set a frontmatter property on every note matched by a JsonLogic query",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "JsonLogic query selecting the notes, as for search_vault_advanced",
+					},
+					"field": map[string]any{
+						"type":        "string",
+						"description": "Property to set",
+					},
+					"value": map[string]any{
+						"description": "New value of the property, as any JSON value",
+					},
+					"dryRun": map[string]any{
+						"type":        "boolean",
+						"description": "Only report the notes that would be updated (default: false)",
+					},
+				},
+				"required": []string{"query", "field", "value"},
+			},
+			OutputSchema: frontmatterUpdateOutputSchema,
+		},
+		{
+			Name:        "list_tags",
+			Description: "List the tags used in the vault as a nested hierarchy with the number of notes using each",
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{},
+			},
+			OutputSchema: tagListOutputSchema,
+		},
+		{
+			Name:        "find_notes_by_tag",
+			Description: "Find the notes tagged with a tag, inline or in their frontmatter",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"tag": map[string]any{
+						"type":        "string",
+						"description": "Tag to look for, with or without the leading #",
+					},
+					"includeNested": map[string]any{
+						"type":        "boolean",
+						"description": "Also match tags nested below it, such as project/alpha for project (default: true)",
+					},
+				},
+				"required": []string{"tag"},
+			},
+			OutputSchema: taggedNotesOutputSchema,
+		},
+		{
+			Name:        "rename_tag",
+			Description: "Rename a tag, and the tags nested below it, in inline tags and frontmatter tags across the vault",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"tag": map[string]any{
+						"type":        "string",
+						"description": "Tag to rename",
+					},
+					"newTag": map[string]any{
+						"type":        "string",
+						"description": "New name of the tag",
+					},
+					"dryRun": map[string]any{
+						"type":        "boolean",
+						"description": "Only report the changes that would be made (default: false)",
+					},
+				},
+				"required": []string{"tag", "newTag"},
+			},
+			OutputSchema: renameTagsOutputSchema,
+		},
+		{
+			Name:        "merge_tags",
+			Description: "Merge several tags into one, rewriting inline tags and frontmatter tags across the vault",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"tags": map[string]any{
+						"type":        "array",
+						"description": "Tags to merge",
+						"items":       map[string]any{"type": "string"},
+					},
+					"target": map[string]any{
+						"type":        "string",
+						"description": "Tag to merge them into",
+					},
+					"dryRun": map[string]any{
+						"type":        "boolean",
+						"description": "Only report the changes that would be made (default: false)",
+					},
+				},
+				"required": []string{"tags", "target"},
+			},
+			OutputSchema: renameTagsOutputSchema,
+		},
+		{
+			Name:        "replace_in_vault",
+			Description: "Find and replace text across the notes of the vault, optionally limited to a folder, glob or tag",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"pattern": map[string]any{
+						"type":        "string",
+						"description": "Text to find, or a regular expression when regex is set",
+					},
+					"replacement": map[string]any{
+						"type":        "string",
+						"description": "Replacement text. With regex set, $1 or ${name} insert capture groups; write ${1} when letters or digits follow",
+					},
+					"regex": map[string]any{
+						"type":        "boolean",
+						"description": "Treat pattern as a regular expression, in Go RE2 syntax (default: false)",
+					},
+					"folder": map[string]any{
+						"type":        "string",
+						"description": "Only change notes in this folder",
+					},
+					"glob": map[string]any{
+						"type":        "string",
+						"description": "Only change notes whose path matches this glob, where ** matches any number of folders",
+					},
+					"tag": map[string]any{
+						"type":        "string",
+						"description": "Only change notes with this tag or a tag nested below it",
+					},
+					"skipCodeBlocks": map[string]any{
+						"type":        "boolean",
+						"description": "Leave code blocks and inline code untouched (default: false)",
+					},
+					"skipFrontmatter": map[string]any{
+						"type":        "boolean",
+						"description": "Leave the frontmatter untouched (default: false)",
+					},
+					"maxFiles": map[string]any{
+						"type":        "integer",
+						"description": fmt.Sprintf("Change nothing if more notes than this would be changed (default: %d)", defaultReplaceMaxFiles),
+					},
+					"dryRun": map[string]any{
+						"type":        "boolean",
+						"description": "Only return the diff of each note that would be changed (default: false)",
+					},
+				},
+				"required": []string{"pattern", "replacement"},
+			},
+			OutputSchema: replaceOutputSchema,
+		},
+		{
+			Name:        "list_tasks",
+			Description: "List the checkbox tasks of the vault, filtered by status, tag, due date and folder",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"status": map[string]any{
+						"type":        "string",
+						"description": "Status of the tasks to list (default: open)",
+						"enum":        taskStatuses,
+					},
+					"tag": map[string]any{
+						"type":        "string",
+						"description": "Only list tasks with this tag or a tag nested below it",
+					},
+					"dueBefore": map[string]any{
+						"type":        "string",
+						"description": "Only list tasks due on or before this date (YYYY-MM-DD)",
+					},
+					"dueAfter": map[string]any{
+						"type":        "string",
+						"description": "Only list tasks due on or after this date (YYYY-MM-DD)",
+					},
+					"folder": map[string]any{
+						"type":        "string",
+						"description": "Only list tasks of notes in this folder",
+					},
+				},
+			},
+			OutputSchema: taskListOutputSchema,
+		},
+		{
+			Name:        "complete_task",
+			Description: "Check off a task, or uncheck it, identified by its line or block ID",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{
+						"type":        "string",
+						"description": "Path to the note relative to vault root",
+					},
+					"line": map[string]any{
+						"type":        "integer",
+						"description": "1-based line of the task, as returned by list_tasks",
+					},
+					"blockId": map[string]any{
+						"type":        "string",
+						"description": "Block ID of the task",
+					},
+					"done": map[string]any{
+						"type":        "boolean",
+						"description": "Whether the task is done; false reopens it (default: true)",
+					},
+				},
+				"required": []string{"filename"},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "add_task",
+			Description: "Add an open task to a note, below a heading, after a block or at the end of the note",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{
+						"type":        "string",
+						"description": "Path to the note relative to vault root",
+					},
+					"text": map[string]any{
+						"type":        "string",
+						"description": "Task description, which may include #tags",
+					},
+					"due": map[string]any{
+						"type":        "string",
+						"description": "Due date (YYYY-MM-DD), written in the Tasks plugin format",
+					},
+					"heading": map[string]any{
+						"type":        "string",
+						"description": "Heading path to add the task below, delimited by '::'",
+					},
+					"blockId": map[string]any{
+						"type":        "string",
+						"description": "Block ID to add the task after",
+					},
+				},
+				"required": []string{"filename", "text"},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "search_vault_simple",
+			Description: "Simple text search across the vault",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "Search query",
+					},
+					"contextLength": map[string]any{
+						"type":        "integer",
+						"description": "Amount of context to return around matches (default: 100)",
+					},
+				},
+				"required": []string{"query"},
+			},
+			OutputSchema: searchResultsOutputSchema,
+		},
+		{
+			Name:        "search_vault_advanced",
+			Description: "Advanced search using Dataview DQL or JsonLogic queries",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "Search query (DQL or JsonLogic)",
+					},
+					"queryType": map[string]any{
+						"type":        "string",
+						"description": "Query type",
+						"enum":        []string{"dataview", "jsonlogic"},
+					},
+				},
+				"required": []string{"query", "queryType"},
+			},
+			OutputSchema: advancedSearchResultsOutputSchema,
+		},
+		{
+			Name:        "list_commands",
+			Description: "Get a list of available Obsidian commands",
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{},
+			},
+			OutputSchema: commandListOutputSchema,
+		},
+		{
+			Name:        "execute_command",
+			Description: "Execute a specific Obsidian command",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"commandId": map[string]any{
+						"type":        "string",
+						"description": "ID of the command to execute",
+					},
+				},
+				"required": []string{"commandId"},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "open_file",
+			Description: "Open a file in the Obsidian UI",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"filename": map[string]any{
+						"type":        "string",
+						"description": "Path to the file relative to vault root",
+					},
+					"newLeaf": map[string]any{
+						"type":        "boolean",
+						"description": "Open in a new leaf (default: false)",
+					},
+				},
+				"required": []string{"filename"},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "get_periodic_note",
+			Description: "Get the content of a daily, weekly, monthly, quarterly or yearly periodic note",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"period": map[string]any{
+						"type":        "string",
+						"description": "Period of the note (defaults to 'daily')",
+						"enum":        []string{"daily", "weekly", "monthly", "quarterly", "yearly"},
+					},
+					"date": map[string]any{
+						"type":        "string",
+						"description": "Date within the period as YYYY-MM-DD (optional, defaults to the current period)",
+					},
+					"format": map[string]any{
+						"type":        "string",
+						"description": "Response format: 'markdown' (default) or 'json' (includes metadata)",
+						"enum":        []string{"markdown", "json"},
+					},
+				},
+			},
+			OutputSchema: noteSchema,
+		},
+		{
+			Name:        "update_periodic_note",
+			Description: "Replace the content of a periodic note",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"period": map[string]any{
+						"type":        "string",
+						"description": "Period of the note (defaults to 'daily')",
+						"enum":        []string{"daily", "weekly", "monthly", "quarterly", "yearly"},
+					},
+					"date": map[string]any{
+						"type":        "string",
+						"description": "Date within the period as YYYY-MM-DD (optional, defaults to the current period)",
+					},
+					"content": map[string]any{
+						"type":        "string",
+						"description": "Content to write to the note",
+					},
+					"contentType": map[string]any{
+						"type":        "string",
+						"description": "Content type (defaults to 'text/markdown')",
+					},
+				},
+				"required": []string{"content"},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "append_to_periodic_note",
+			Description: "Append content to a periodic note, creating the note if necessary",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"period": map[string]any{
+						"type":        "string",
+						"description": "Period of the note (defaults to 'daily')",
+						"enum":        []string{"daily", "weekly", "monthly", "quarterly", "yearly"},
+					},
+					"date": map[string]any{
+						"type":        "string",
+						"description": "Date within the period as YYYY-MM-DD (optional, defaults to the current period)",
+					},
+					"content": map[string]any{
+						"type":        "string",
+						"description": "Content to append to the note",
+					},
+				},
+				"required": []string{"content"},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "patch_periodic_note",
+			Description: "Insert content into a periodic note relative to headings, blocks, or frontmatter fields",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"period": map[string]any{
+						"type":        "string",
+						"description": "Period of the note (defaults to 'daily')",
+						"enum":        []string{"daily", "weekly", "monthly", "quarterly", "yearly"},
+					},
+					"date": map[string]any{
+						"type":        "string",
+						"description": "Date within the period as YYYY-MM-DD (optional, defaults to the current period)",
+					},
+					"operation": map[string]any{
+						"type":        "string",
+						"description": "Patch operation to perform",
+						"enum":        []string{"append", "prepend", "replace"},
+					},
+					"targetType": map[string]any{
+						"type":        "string",
+						"description": "Type of target to patch",
+						"enum":        []string{"heading", "block", "frontmatter"},
+					},
+					"target": map[string]any{
+						"type":        "string",
+						"description": "Target to patch (heading path, block ID, or frontmatter field)",
+					},
+					"content": map[string]any{
+						"type":        "string",
+						"description": "Content to insert",
+					},
+					"contentType": map[string]any{
+						"type":        "string",
+						"description": "Content type (defaults to 'text/markdown')",
+					},
+					"delimiter": map[string]any{
+						"type":        "string",
+						"description": "Delimiter for nested targets (defaults to '::')",
+					},
+				},
+				"required": []string{"operation", "targetType", "target", "content"},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "delete_periodic_note",
+			Description: "Delete a periodic note",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"period": map[string]any{
+						"type":        "string",
+						"description": "Period of the note (defaults to 'daily')",
+						"enum":        []string{"daily", "weekly", "monthly", "quarterly", "yearly"},
+					},
+					"date": map[string]any{
+						"type":        "string",
+						"description": "Date within the period as YYYY-MM-DD (optional, defaults to the current period)",
+					},
+				},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "get_active_file",
+			Description: "Get the content of the file currently open in Obsidian, supports both markdown and JSON format",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"format": map[string]any{
+						"type":        "string",
+						"description": "Response format: 'markdown' (default) or 'json' (includes metadata)",
+						"enum":        []string{"markdown", "json"},
+					},
+				},
+			},
+			OutputSchema: noteSchema,
+		},
+		{
+			Name:        "update_active_file",
+			Description: "Replace the content of the file currently open in Obsidian",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"content": map[string]any{
+						"type":        "string",
+						"description": "Content to write to the file",
+					},
+					"contentType": map[string]any{
+						"type":        "string",
+						"description": "Content type (defaults to 'text/markdown')",
+					},
+				},
+				"required": []string{"content"},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "append_to_active_file",
+			Description: "Append content to the end of the file currently open in Obsidian",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"content": map[string]any{
+						"type":        "string",
+						"description": "Content to append to the file",
+					},
+				},
+				"required": []string{"content"},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "patch_active_file",
+			Description: "Insert content into the file currently open in Obsidian relative to headings, blocks, or frontmatter fields",
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"operation": map[string]any{
+						"type":        "string",
+						"description": "Patch operation to perform",
+						"enum":        []string{"append", "prepend", "replace"},
+					},
+					"targetType": map[string]any{
+						"type":        "string",
+						"description": "Type of target to patch",
+						"enum":        []string{"heading", "block", "frontmatter"},
+					},
+					"target": map[string]any{
+						"type":        "string",
+						"description": "Target to patch (heading path, block ID, or frontmatter field)",
+					},
+					"content": map[string]any{
+						"type":        "string",
+						"description": "Content to insert",
+					},
+					"contentType": map[string]any{
+						"type":        "string",
+						"description": "Content type (defaults to 'text/markdown')",
+					},
+					"delimiter": map[string]any{
+						"type":        "string",
+						"description": "Delimiter for nested targets (defaults to '::')",
+					},
+				},
+				"required": []string{"operation", "targetType", "target", "content"},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+		{
+			Name:        "delete_active_file",
+			Description: "Delete the file currently open in Obsidian",
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{},
+			},
+			OutputSchema: messageOutputSchema,
+		},
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      request.ID,
+		Result: map[string]any{
+			"tools": s.policy.apply(withPreconditions(tools)),
+		},
+	}
+}
+
+// handleToolsCall handles tool execution requests
+func (s *MCPServer) handleToolsCall(ctx context.Context, request *MCPRequest) *MCPResponse {
+	params, ok := request.Params["arguments"].(map[string]any)
+	if !ok {
+		params = make(map[string]any)
+	}
+
+	name, ok := request.Params["name"].(string)
+	if !ok {
+		return s.createErrorResponse(request.ID, -32602, "Invalid params: missing tool name")
+	}
+
+	if meta, ok := request.Params["_meta"].(map[string]any); ok {
+		if token, ok := meta["progressToken"]; ok && token != nil {
+			ctx = obsidian.WithProgress(ctx, s.progressNotifier(ctx, token))
+		}
+	}
+
+	result, err := s.auditedExecuteTool(ctx, name, params)
+	var unknownToolErr *unknownToolError
+	if errors.As(err, &unknownToolErr) {
+		return s.createErrorResponse(request.ID, -32602, "Invalid params: "+err.Error())
+	}
+	if err != nil {
+		// Tool failures are reported to the model so it can correct itself
+		return &MCPResponse{
+			JSONRPC: "2.0",
+			ID:      request.ID,
+			Result: map[string]any{
+				"content": []map[string]any{
+					{
+						"type": "text",
+						"text": err.Error(),
+					},
+				},
+				"isError": true,
+			},
+		}
+	}
+
+	content := result.content
+	if content == nil {
+		content = []map[string]any{
+			{
+				"type": "text",
+				"text": result.text,
+			},
+		}
+	}
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      request.ID,
+		Result: map[string]any{
+			"content":           content,
+			"structuredContent": result.structured,
+		},
+	}
+}
+
+// progressNotifier returns a progress hook that sends notifications/progress
+// messages for the given progress token to the requesting client
+func (s *MCPServer) progressNotifier(ctx context.Context, token any) obsidian.ProgressFunc {
+	sess := s.sessionFor(ctx)
+	return func(progress, total float64, message string) {
+		params := map[string]any{
+			"progressToken": token,
+			"progress":      progress,
+		}
+		if total > 0 {
+			params["total"] = total
+		}
+		if message != "" {
+			params["message"] = message
+		}
+		if err := sess.notify("notifications/progress", params); err != nil {
+			fmt.Fprintf(s.stderr, "Failed to send progress notification: %v\n", err)
+		}
+	}
+}
+
+// executeTool executes the specified tool with given parameters
+func (s *MCPServer) executeTool(ctx context.Context, name string, params map[string]any) (*toolResult, error) {
+	if err := s.policy.check(name, params); err != nil {
+		return nil, err
+	}
+	if s.policy.DryRun && previewTools[name] {
+		params = maps.Clone(params)
+		params["dryRun"] = true
+	}
+	precondition, err := preconditionFromParams(name, params)
+	if err != nil {
+		return nil, err
+	}
+	if precondition != nil {
+		ctx = obsidian.WithPrecondition(ctx, *precondition)
+	}
+	if s.snapshots != nil && mutatingTools[name] {
+		ctx = obsidian.WithSnapshotOperationID(ctx, rand.Text())
+	}
+
+	switch name {
+	case "get_server_info":
+		return jsonResult(s.obsidianClient.GetServerInfo(ctx))
+	case "list_vault_files":
+		path, _ := params["path"].(string)
+		if recursive, _ := params["recursive"].(bool); recursive {
+			return jsonResult(s.obsidianClient.ListVaultFilesRecursive(ctx, path))
+		}
+		return jsonResult(s.obsidianClient.ListVaultFiles(ctx, path))
+	case "get_file_content":
+		filename, ok := params["filename"].(string)
+		if !ok {
+			return nil, fmt.Errorf("filename is required")
+		}
+		if format, _ := params["format"].(string); format == "json" {
+			return jsonResult(s.obsidianClient.GetNote(ctx, filename))
+		}
+		return fileResult(s.obsidianClient.GetFile(ctx, filename))
+	case "get_multiple_files":
+		rawFilenames, ok := params["filenames"].([]any)
+		if !ok {
+			return nil, fmt.Errorf("filenames is required")
+		}
+		filenames := make([]string, 0, len(rawFilenames))
+		for _, raw := range rawFilenames {
+			filename, ok := raw.(string)
+			if !ok {
+				return nil, fmt.Errorf("filenames must be a list of strings")
+			}
+			filenames = append(filenames, filename)
+		}
+		format, _ := params["format"].(string)
+		if format == "" {
+			format = "markdown"
+		}
+		files, err := s.obsidianClient.GetMultipleFiles(ctx, filenames, format)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResult(map[string]any{"files": files}, nil)
+	case "get_note_outline":
+		filename, ok := params["filename"].(string)
+		if !ok {
+			return nil, fmt.Errorf("filename is required")
+		}
+		content, err := s.obsidianClient.GetFileContent(ctx, filename)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResult(noteOutline{Path: filename, Outline: markdown.ParseOutline(content)}, nil)
+	case "get_note_section":
+		filename, ok := params["filename"].(string)
+		if !ok {
+			return nil, fmt.Errorf("filename is required")
+		}
+		heading, ok := params["heading"].(string)
+		if !ok {
+			return nil, fmt.Errorf("heading is required")
+		}
+		delimiter, _ := params["delimiter"].(string)
+		if delimiter == "" {
+			delimiter = "::"
+		}
+		includeSubsections := true
+		if include, ok := params["includeSubsections"].(bool); ok {
+			includeSubsections = include
+		}
+		includeHeading, _ := params["includeHeading"].(bool)
+		return s.noteSection(ctx, filename, heading, delimiter, includeSubsections, includeHeading)
+	case "create_or_update_file":
+		filename, ok := params["filename"].(string)
+		if !ok {
+			return nil, fmt.Errorf("filename is required")
+		}
+		content, ok := params["content"].(string)
+		if !ok {
+			return nil, fmt.Errorf("content is required")
+		}
+		contentType, _ := params["contentType"].(string)
 		if contentType == "" {
 			contentType = "text/markdown"
 		}
-		return s.obsidianClient.CreateOrUpdateFile(filename, content, contentType)
+		if dryRun, _ := params["dryRun"].(bool); dryRun {
+			return s.previewChange(ctx, filename, func(string, bool) (string, bool, error) {
+				return content, true, nil
+			})
+		}
+		return messageResult(s.obsidianClient.CreateOrUpdateFile(ctx, filename, content, contentType))
 	case "append_to_file":
 		filename, ok := params["filename"].(string)
 		if !ok {
-			return "", fmt.Errorf("filename is required")
+			return nil, fmt.Errorf("filename is required")
 		}
 		content, ok := params["content"].(string)
 		if !ok {
-			return "", fmt.Errorf("content is required")
+			return nil, fmt.Errorf("content is required")
 		}
-		return s.obsidianClient.AppendToFile(filename, content)
+		if dryRun, _ := params["dryRun"].(bool); dryRun {
+			return s.previewChange(ctx, filename, func(before string, _ bool) (string, bool, error) {
+				return before + content, true, nil
+			})
+		}
+		return messageResult(s.obsidianClient.AppendToFile(ctx, filename, content))
 	case "patch_file_content":
 		filename, ok := params["filename"].(string)
 		if !ok {
-			return "", fmt.Errorf("filename is required")
+			return nil, fmt.Errorf("filename is required")
 		}
 		operation, ok := params["operation"].(string)
 		if !ok {
-			return "", fmt.Errorf("operation is required")
+			return nil, fmt.Errorf("operation is required")
 		}
 		targetType, ok := params["targetType"].(string)
 		if !ok {
-			return "", fmt.Errorf("targetType is required")
+			return nil, fmt.Errorf("targetType is required")
 		}
 		target, ok := params["target"].(string)
 		if !ok {
-			return "", fmt.Errorf("target is required")
+			return nil, fmt.Errorf("target is required")
 		}
 		content, ok := params["content"].(string)
 		if !ok {
-			return "", fmt.Errorf("content is required")
+			return nil, fmt.Errorf("content is required")
 		}
 		contentType, _ := params["contentType"].(string)
 		if contentType == "" {
@@ -476,62 +1628,513 @@ func (s *MCPServer) executeTool(name string, params map[string]any) (string, err
 		if delimiter == "" {
 			delimiter = "::"
 		}
-		return s.obsidianClient.PatchFileContent(filename, operation, targetType, target, content, contentType, delimiter)
+		if dryRun, _ := params["dryRun"].(bool); dryRun {
+			return s.previewChange(ctx, filename, requireExisting(filename, func(before string, _ bool) (string, bool, error) {
+				after, err := markdown.ApplyPatch(before, operation, targetType, target, content, contentType, delimiter)
+				return after, true, err
+			}))
+		}
+		return messageResult(s.obsidianClient.PatchFileContent(ctx, filename, operation, targetType, target, content, contentType, delimiter))
 	case "delete_file":
 		filename, ok := params["filename"].(string)
 		if !ok {
-			return "", fmt.Errorf("filename is required")
+			return nil, fmt.Errorf("filename is required")
+		}
+		if dryRun, _ := params["dryRun"].(bool); dryRun {
+			return s.previewChange(ctx, filename, requireExisting(filename, func(string, bool) (string, bool, error) {
+				return "", false, nil
+			}))
+		}
+		return messageResult(s.obsidianClient.DeleteFile(ctx, filename))
+	case "upload_attachment":
+		filename, ok := params["filename"].(string)
+		if !ok {
+			return nil, fmt.Errorf("filename is required")
+		}
+		encoded, ok := params["data"].(string)
+		if !ok {
+			return nil, fmt.Errorf("data is required")
+		}
+		mimeType, _ := params["mimeType"].(string)
+		data, dataURLType, err := decodeAttachment(encoded)
+		if err != nil {
+			return nil, err
+		}
+		if mimeType == "" {
+			mimeType = dataURLType
+		}
+		return messageResult(s.obsidianClient.UploadAttachment(ctx, filename, data, mimeType))
+	case "move_file":
+		source, ok := params["source"].(string)
+		if !ok {
+			return nil, fmt.Errorf("source is required")
+		}
+		destination, ok := params["destination"].(string)
+		if !ok {
+			return nil, fmt.Errorf("destination is required")
+		}
+		dryRun, _ := params["dryRun"].(bool)
+		return jsonResult(s.obsidianClient.MoveFile(ctx, source, destination, dryRun))
+	case "list_snapshots":
+		filename, _ := params["filename"].(string)
+		limit, _ := params["limit"].(float64)
+		snaps, err := s.listSnapshots(filename, int(limit))
+		if err != nil {
+			return nil, err
+		}
+		return jsonResult(map[string]any{"snapshots": snaps}, nil)
+	case "diff_snapshot":
+		id, ok := params["id"].(string)
+		if !ok {
+			return nil, fmt.Errorf("id is required")
+		}
+		return s.diffSnapshot(ctx, id)
+	case "restore_snapshot":
+		id, ok := params["id"].(string)
+		if !ok {
+			return nil, fmt.Errorf("id is required")
+		}
+		return messageResult(s.restoreSnapshot(ctx, id))
+	case "undo_last_change":
+		filename, _ := params["filename"].(string)
+		return messageResult(s.undoLastChange(ctx, filename))
+	case "get_backlinks":
+		filename, ok := params["filename"].(string)
+		if !ok {
+			return nil, fmt.Errorf("filename is required")
+		}
+		graph, err := s.linkGraph(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResult(map[string]any{"file": filename, "backlinks": graph.Backlinks(filename)}, nil)
+	case "get_outgoing_links":
+		filename, ok := params["filename"].(string)
+		if !ok {
+			return nil, fmt.Errorf("filename is required")
+		}
+		graph, err := s.linkGraph(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResult(map[string]any{"file": filename, "links": graph.Outgoing(filename)}, nil)
+	case "find_orphan_notes":
+		graph, err := s.linkGraph(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResult(map[string]any{"orphans": graph.Orphans()}, nil)
+	case "find_broken_links":
+		graph, err := s.linkGraph(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResult(map[string]any{"brokenLinks": graph.Broken()}, nil)
+	case "get_frontmatter":
+		filename, ok := params["filename"].(string)
+		if !ok {
+			return nil, fmt.Errorf("filename is required")
+		}
+		frontmatter, err := s.obsidianClient.GetFrontmatter(ctx, filename)
+		if err != nil {
+			return nil, err
+		}
+		if field, ok := params["field"].(string); ok && field != "" {
+			value, exists := frontmatter[field]
+			return jsonResult(map[string]any{"path": filename, "field": field, "value": value, "exists": exists}, nil)
+		}
+		return jsonResult(map[string]any{"path": filename, "frontmatter": frontmatter}, nil)
+	case "set_frontmatter_field":
+		filename, ok := params["filename"].(string)
+		if !ok {
+			return nil, fmt.Errorf("filename is required")
+		}
+		field, ok := params["field"].(string)
+		if !ok {
+			return nil, fmt.Errorf("field is required")
+		}
+		value, ok := params["value"]
+		if !ok {
+			return nil, fmt.Errorf("value is required")
+		}
+		return messageResult(s.obsidianClient.SetFrontmatterField(ctx, filename, field, value))
+	case "delete_frontmatter_field":
+		filename, ok := params["filename"].(string)
+		if !ok {
+			return nil, fmt.Errorf("filename is required")
+		}
+		field, ok := params["field"].(string)
+		if !ok {
+			return nil, fmt.Errorf("field is required")
+		}
+		return messageResult(s.obsidianClient.DeleteFrontmatterField(ctx, filename, field))
+	case "bulk_update_frontmatter":
+		query, ok := params["query"].(string)
+		if !ok {
+			return nil, fmt.Errorf("query is required")
+		}
+		field, ok := params["field"].(string)
+		if !ok {
+			return nil, fmt.Errorf("field is required")
+		}
+		value, ok := params["value"]
+		if !ok {
+			return nil, fmt.Errorf("value is required")
+		}
+		dryRun, _ := params["dryRun"].(bool)
+		return jsonResult(s.obsidianClient.BulkUpdateFrontmatter(ctx, query, field, value, dryRun))
+	case "list_tags":
+		vault, err := s.index.Refresh(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResult(map[string]any{"tags": tags.Tree(noteTags(vault))}, nil)
+	case "find_notes_by_tag":
+		tag, ok := params["tag"].(string)
+		if !ok {
+			return nil, fmt.Errorf("tag is required")
+		}
+		nested := true
+		if n, ok := params["includeNested"].(bool); ok {
+			nested = n
+		}
+		vault, err := s.index.Refresh(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResult(map[string]any{"tag": tags.Normalize(tag), "notes": tags.Notes(noteTags(vault), tag, nested)}, nil)
+	case "rename_tag":
+		tag, ok := params["tag"].(string)
+		if !ok {
+			return nil, fmt.Errorf("tag is required")
+		}
+		newTag, ok := params["newTag"].(string)
+		if !ok {
+			return nil, fmt.Errorf("newTag is required")
+		}
+		dryRun, _ := params["dryRun"].(bool)
+		return jsonResult(s.renameTags(ctx, []string{tag}, newTag, dryRun))
+	case "merge_tags":
+		rawTags, ok := params["tags"].([]any)
+		if !ok {
+			return nil, fmt.Errorf("tags is required")
+		}
+		sources := make([]string, 0, len(rawTags))
+		for _, raw := range rawTags {
+			tag, ok := raw.(string)
+			if !ok {
+				return nil, fmt.Errorf("tags must be a list of strings")
+			}
+			sources = append(sources, tag)
+		}
+		target, ok := params["target"].(string)
+		if !ok {
+			return nil, fmt.Errorf("target is required")
+		}
+		dryRun, _ := params["dryRun"].(bool)
+		return jsonResult(s.renameTags(ctx, sources, target, dryRun))
+	case "replace_in_vault":
+		req, err := replaceRequestFromParams(params)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResult(s.replaceInVault(ctx, req))
+	case "list_tasks":
+		filter, err := taskFilterFromParams(params)
+		if err != nil {
+			return nil, err
+		}
+		tasks, err := s.listTasks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResult(map[string]any{"tasks": tasks}, nil)
+	case "complete_task":
+		filename, ok := params["filename"].(string)
+		if !ok {
+			return nil, fmt.Errorf("filename is required")
+		}
+		line, _ := params["line"].(float64)
+		blockID, _ := params["blockId"].(string)
+		done := true
+		if d, ok := params["done"].(bool); ok {
+			done = d
+		}
+		return messageResult(s.obsidianClient.CompleteTask(ctx, filename, int(line), blockID, done))
+	case "add_task":
+		filename, ok := params["filename"].(string)
+		if !ok {
+			return nil, fmt.Errorf("filename is required")
+		}
+		text, ok := params["text"].(string)
+		if !ok {
+			return nil, fmt.Errorf("text is required")
 		}
-		return s.obsidianClient.DeleteFile(filename)
+		due, _ := params["due"].(string)
+		heading, _ := params["heading"].(string)
+		blockID, _ := params["blockId"].(string)
+		return messageResult(s.obsidianClient.AddTask(ctx, filename, text, due, heading, blockID))
 	case "search_vault_simple":
 		query, ok := params["query"].(string)
 		if !ok {
-			return "", fmt.Errorf("query is required")
+			return nil, fmt.Errorf("query is required")
 		}
 		contextLength := 100
 		if cl, ok := params["contextLength"].(float64); ok {
 			contextLength = int(cl)
 		}
-		return s.obsidianClient.SearchVaultSimple(query, contextLength)
+		results, err := s.obsidianClient.SearchVaultSimple(ctx, query, contextLength)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResult(map[string]any{"results": results}, nil)
 	case "search_vault_advanced":
 		query, ok := params["query"].(string)
 		if !ok {
-			return "", fmt.Errorf("query is required")
+			return nil, fmt.Errorf("query is required")
 		}
 		queryType, ok := params["queryType"].(string)
 		if !ok {
-			return "", fmt.Errorf("queryType is required")
+			return nil, fmt.Errorf("queryType is required")
 		}
-		return s.obsidianClient.SearchVaultAdvanced(query, queryType)
+		results, err := s.obsidianClient.SearchVaultAdvanced(ctx, query, queryType)
+		if err != nil {
+			return nil, err
+		}
+		return jsonResult(map[string]any{"results": results}, nil)
 	case "list_commands":
-		return s.obsidianClient.ListCommands()
+		return jsonResult(s.obsidianClient.ListCommands(ctx))
 	case "execute_command":
 		commandId, ok := params["commandId"].(string)
 		if !ok {
-			return "", fmt.Errorf("commandId is required")
+			return nil, fmt.Errorf("commandId is required")
 		}
-		return s.obsidianClient.ExecuteCommand(commandId)
+		return messageResult(s.obsidianClient.ExecuteCommand(ctx, commandId))
 	case "open_file":
 		filename, ok := params["filename"].(string)
 		if !ok {
-			return "", fmt.Errorf("filename is required")
+			return nil, fmt.Errorf("filename is required")
 		}
 		newLeaf, _ := params["newLeaf"].(bool)
-		return s.obsidianClient.OpenFile(filename, newLeaf)
+		return messageResult(s.obsidianClient.OpenFile(ctx, filename, newLeaf))
+	case "get_periodic_note":
+		period, _ := params["period"].(string)
+		date, _ := params["date"].(string)
+		if format, _ := params["format"].(string); format == "json" {
+			return jsonResult(s.obsidianClient.GetPeriodicNoteJSON(ctx, period, date))
+		}
+		content, err := s.obsidianClient.GetPeriodicNote(ctx, period, date)
+		return contentResult("", content, err)
+	case "update_periodic_note":
+		period, _ := params["period"].(string)
+		date, _ := params["date"].(string)
+		content, ok := params["content"].(string)
+		if !ok {
+			return nil, fmt.Errorf("content is required")
+		}
+		contentType, _ := params["contentType"].(string)
+		if contentType == "" {
+			contentType = "text/markdown"
+		}
+		return messageResult(s.obsidianClient.UpdatePeriodicNote(ctx, period, date, content, contentType))
+	case "append_to_periodic_note":
+		period, _ := params["period"].(string)
+		date, _ := params["date"].(string)
+		content, ok := params["content"].(string)
+		if !ok {
+			return nil, fmt.Errorf("content is required")
+		}
+		return messageResult(s.obsidianClient.AppendToPeriodicNote(ctx, period, date, content))
+	case "patch_periodic_note":
+		period, _ := params["period"].(string)
+		date, _ := params["date"].(string)
+		operation, ok := params["operation"].(string)
+		if !ok {
+			return nil, fmt.Errorf("operation is required")
+		}
+		targetType, ok := params["targetType"].(string)
+		if !ok {
+			return nil, fmt.Errorf("targetType is required")
+		}
+		target, ok := params["target"].(string)
+		if !ok {
+			return nil, fmt.Errorf("target is required")
+		}
+		content, ok := params["content"].(string)
+		if !ok {
+			return nil, fmt.Errorf("content is required")
+		}
+		contentType, _ := params["contentType"].(string)
+		if contentType == "" {
+			contentType = "text/markdown"
+		}
+		delimiter, _ := params["delimiter"].(string)
+		if delimiter == "" {
+			delimiter = "::"
+		}
+		return messageResult(s.obsidianClient.PatchPeriodicNote(ctx, period, date, operation, targetType, target, content, contentType, delimiter))
+	case "delete_periodic_note":
+		period, _ := params["period"].(string)
+		date, _ := params["date"].(string)
+		return messageResult(s.obsidianClient.DeletePeriodicNote(ctx, period, date))
+	case "get_active_file":
+		if format, _ := params["format"].(string); format == "json" {
+			return jsonResult(s.obsidianClient.GetActiveFileJSON(ctx))
+		}
+		content, err := s.obsidianClient.GetActiveFile(ctx)
+		return contentResult("", content, err)
+	case "update_active_file":
+		content, ok := params["content"].(string)
+		if !ok {
+			return nil, fmt.Errorf("content is required")
+		}
+		contentType, _ := params["contentType"].(string)
+		if contentType == "" {
+			contentType = "text/markdown"
+		}
+		return messageResult(s.obsidianClient.UpdateActiveFile(ctx, content, contentType))
+	case "append_to_active_file":
+		content, ok := params["content"].(string)
+		if !ok {
+			return nil, fmt.Errorf("content is required")
+		}
+		return messageResult(s.obsidianClient.AppendToActiveFile(ctx, content))
+	case "patch_active_file":
+		operation, ok := params["operation"].(string)
+		if !ok {
+			return nil, fmt.Errorf("operation is required")
+		}
+		targetType, ok := params["targetType"].(string)
+		if !ok {
+			return nil, fmt.Errorf("targetType is required")
+		}
+		target, ok := params["target"].(string)
+		if !ok {
+			return nil, fmt.Errorf("target is required")
+		}
+		content, ok := params["content"].(string)
+		if !ok {
+			return nil, fmt.Errorf("content is required")
+		}
+		contentType, _ := params["contentType"].(string)
+		if contentType == "" {
+			contentType = "text/markdown"
+		}
+		delimiter, _ := params["delimiter"].(string)
+		if delimiter == "" {
+			delimiter = "::"
+		}
+		return messageResult(s.obsidianClient.PatchActiveFile(ctx, operation, targetType, target, content, contentType, delimiter))
+	case "delete_active_file":
+		return messageResult(s.obsidianClient.DeleteActiveFile(ctx))
 	default:
-		return "", fmt.Errorf("unknown tool: %s", name)
+		return nil, &unknownToolError{name: name}
 	}
 }
 
-// sendResponse sends a response to stdout
-func (s *MCPServer) sendResponse(response *MCPResponse) error {
-	data, err := json.Marshal(response)
+// unknownToolError is returned when a tool that does not exist is called
+type unknownToolError struct {
+	name string
+}
+
+func (e *unknownToolError) Error() string {
+	return "unknown tool: " + e.name
+}
+
+// toolResult is the outcome of a successful tool call: the text shown to the
+// model and the structured value matching the tool's outputSchema. Tools
+// returning other kinds of content, such as images, set content instead of
+// text.
+type toolResult struct {
+	text       string
+	content    []map[string]any
+	structured any
+}
+
+// jsonResult returns a typed client result as structured content, rendered
+// as indented JSON for the text content
+func jsonResult(value any, err error) (*toolResult, error) {
 	if err != nil {
-		return err
+		return nil, err
+	}
+	text, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return &toolResult{text: string(text), structured: value}, nil
+}
+
+// decodeAttachment decodes base64 attachment data, either raw or as a data:
+// URL, returning the media type of a data URL
+func decodeAttachment(encoded string) ([]byte, string, error) {
+	var mimeType string
+	if rest, ok := strings.CutPrefix(strings.TrimSpace(encoded), "data:"); ok {
+		header, payload, found := strings.Cut(rest, ",")
+		if !found || !strings.HasSuffix(header, ";base64") {
+			return nil, "", fmt.Errorf("data URLs must be base64 encoded")
+		}
+		mimeType, encoded = strings.TrimSuffix(header, ";base64"), payload
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid base64 data: %w", err)
+	}
+	return data, mimeType, nil
+}
+
+// fileResult returns a vault file as text, or as an image or embedded
+// resource when it is binary
+func fileResult(file *obsidian.File, err error) (*toolResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	if !file.IsBinary() {
+		return contentResult(file.Path, string(file.Data), nil)
 	}
 
-	_, err = s.stdout.Write(append(data, '\n'))
-	return err
+	data := base64.StdEncoding.EncodeToString(file.Data)
+	block := map[string]any{
+		"type": "resource",
+		"resource": map[string]any{
+			"uri":      vaultFileURI(file.Path),
+			"mimeType": file.MimeType,
+			"blob":     data,
+		},
+	}
+	if file.IsImage() {
+		block = map[string]any{"type": "image", "data": data, "mimeType": file.MimeType}
+	}
+	return &toolResult{
+		content:    []map[string]any{block},
+		structured: map[string]any{"path": file.Path, "mimeType": file.MimeType, "size": len(file.Data)},
+	}, nil
+}
+
+// contentResult returns markdown content as text, with the note path and
+// content as structured content
+func contentResult(path, content string, err error) (*toolResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	structured := map[string]any{"content": content}
+	if path != "" {
+		structured["path"] = path
+	}
+	return &toolResult{text: content, structured: structured}, nil
+}
+
+// messageResult returns the confirmation message of a tool that modifies the vault
+func messageResult(message string, err error) (*toolResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &toolResult{text: message, structured: map[string]any{"message": message}}, nil
+}
+
+// sendResponse sends a response to stdout
+func (s *MCPServer) sendResponse(response *MCPResponse) error {
+	return s.stdio.send(response)
 }
 
 // sendError sends an error response
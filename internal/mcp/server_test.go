@@ -2,10 +2,16 @@ package mcp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -28,7 +34,7 @@ func TestHandleInitialize(t *testing.T) {
 		Params:  make(map[string]any),
 	}
 
-	response := server.handleRequest(request)
+	response := server.handleRequest(context.Background(), request)
 	require.NotNil(t, response)
 
 	assert.Equal(t, "2.0", response.JSONRPC)
@@ -58,7 +64,7 @@ func TestHandleToolsList(t *testing.T) {
 		Method:  "tools/list",
 	}
 
-	response := server.handleRequest(request)
+	response := server.handleRequest(context.Background(), request)
 	require.NotNil(t, response)
 
 	assert.Equal(t, "2.0", response.JSONRPC)
@@ -82,15 +88,50 @@ func TestHandleToolsList(t *testing.T) {
 		"get_server_info",
 		"list_vault_files",
 		"get_file_content",
+		"get_multiple_files",
+		"get_note_outline",
+		"get_note_section",
 		"create_or_update_file",
 		"append_to_file",
 		"patch_file_content",
 		"delete_file",
+		"upload_attachment",
+		"move_file",
+		"list_snapshots",
+		"diff_snapshot",
+		"restore_snapshot",
+		"undo_last_change",
+		"get_backlinks",
+		"get_outgoing_links",
+		"find_orphan_notes",
+		"find_broken_links",
+		"get_frontmatter",
+		"set_frontmatter_field",
+		"delete_frontmatter_field",
+		"bulk_update_frontmatter",
+		"list_tags",
+		"find_notes_by_tag",
+		"rename_tag",
+		"merge_tags",
+		"replace_in_vault",
+		"list_tasks",
+		"complete_task",
+		"add_task",
 		"search_vault_simple",
 		"search_vault_advanced",
 		"list_commands",
 		"execute_command",
 		"open_file",
+		"get_periodic_note",
+		"update_periodic_note",
+		"append_to_periodic_note",
+		"patch_periodic_note",
+		"delete_periodic_note",
+		"get_active_file",
+		"update_active_file",
+		"append_to_active_file",
+		"patch_active_file",
+		"delete_active_file",
 	}
 
 	for _, expectedTool := range expectedTools {
@@ -112,13 +153,13 @@ func TestHandleToolsCallMissingTool(t *testing.T) {
 		},
 	}
 
-	response := server.handleRequest(request)
+	response := server.handleRequest(context.Background(), request)
 	require.NotNil(t, response)
 
 	assert.Equal(t, "2.0", response.JSONRPC)
 	assert.Equal(t, "test-5", response.ID)
-	assert.NotNil(t, response.Error)
-	assert.Equal(t, -32603, response.Error.Code)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, -32602, response.Error.Code)
 	assert.Contains(t, response.Error.Message, "unknown tool")
 }
 
@@ -138,14 +179,45 @@ func TestHandleToolsCallMissingParameters(t *testing.T) {
 		},
 	}
 
-	response := server.handleRequest(request)
+	response := server.handleRequest(context.Background(), request)
 	require.NotNil(t, response)
 
 	assert.Equal(t, "2.0", response.JSONRPC)
 	assert.Equal(t, "test-6", response.ID)
-	assert.NotNil(t, response.Error)
-	assert.Equal(t, -32603, response.Error.Code)
-	assert.Contains(t, response.Error.Message, "filename is required")
+	assert.Nil(t, response.Error)
+
+	result, ok := response.Result.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, true, result["isError"])
+	assert.Contains(t, result["content"].([]map[string]any)[0]["text"], "filename is required")
+}
+
+// TestHandleToolsCallAPIError tests that Obsidian API failures are reported as tool errors
+func TestHandleToolsCallAPIError(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errorCode": 40400, "message": "File not found"}`))
+	}))
+	defer vault.Close()
+
+	server := NewMCPServer("test-token", vault.URL)
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params: map[string]any{
+			"name":      "get_file_content",
+			"arguments": map[string]any{"filename": "missing.md"},
+		},
+	})
+	require.NotNil(t, response)
+	assert.Nil(t, response.Error)
+
+	result := response.Result.(map[string]any)
+	assert.Equal(t, true, result["isError"])
+	assert.NotContains(t, result, "structuredContent")
+	assert.Equal(t, "API error (status 404): File not found (error code 40400)", result["content"].([]map[string]any)[0]["text"])
 }
 
 // TestHandlePing tests ping request handling
@@ -158,7 +230,7 @@ func TestHandlePing(t *testing.T) {
 		Method:  "ping",
 	}
 
-	response := server.handleRequest(request)
+	response := server.handleRequest(context.Background(), request)
 	require.NotNil(t, response)
 
 	assert.Equal(t, "2.0", response.JSONRPC)
@@ -180,7 +252,7 @@ func TestHandleUnknownMethod(t *testing.T) {
 		Method:  "unknown_method",
 	}
 
-	response := server.handleRequest(request)
+	response := server.handleRequest(context.Background(), request)
 	require.NotNil(t, response)
 
 	assert.Equal(t, "2.0", response.JSONRPC)
@@ -201,7 +273,7 @@ func TestJSONSerialization(t *testing.T) {
 		Method:  "initialize",
 	}
 
-	response := server.handleRequest(request)
+	response := server.handleRequest(context.Background(), request)
 	require.NotNil(t, response)
 
 	// Test that the response can be marshaled to JSON
@@ -243,3 +315,419 @@ func TestSendResponse(t *testing.T) {
 	assert.Equal(t, response.JSONRPC, parsed.JSONRPC)
 	assert.Equal(t, response.ID, parsed.ID)
 }
+
+// TestHandleNotification tests that notifications are never answered
+func TestHandleNotification(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123")
+
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		Method:  "notifications/initialized",
+	})
+	assert.Nil(t, response)
+}
+
+// startRun runs the server over in-memory pipes and returns the input writer
+// and the captured output
+func startRun(t *testing.T, server *MCPServer) (*io.PipeWriter, *syncBuffer) {
+	t.Helper()
+	stdin, input := io.Pipe()
+	output := &syncBuffer{}
+	server.stdin = stdin
+	server.stdout = output
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Run()
+	}()
+	t.Cleanup(func() {
+		_ = input.Close()
+		require.NoError(t, <-done)
+	})
+	return input, output
+}
+
+// TestRunHandlesRequestsConcurrently tests that a slow tool call does not block other requests
+func TestRunHandlesRequestsConcurrently(t *testing.T) {
+	release := make(chan struct{})
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write([]byte("# Slow"))
+	}))
+	defer vault.Close()
+	defer close(release)
+
+	input, output := startRun(t, NewMCPServer("test-token", vault.URL))
+
+	_, err := io.WriteString(input, `{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "get_file_content", "arguments": {"filename": "slow.md"}}}`+"\n")
+	require.NoError(t, err)
+	_, err = io.WriteString(input, `{"jsonrpc": "2.0", "id": 2, "method": "ping"}`+"\n")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(output.messages(t)) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, float64(2), output.messages(t)[0]["id"])
+
+	release <- struct{}{}
+	require.Eventually(t, func() bool {
+		return len(output.messages(t)) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, float64(1), output.messages(t)[1]["id"])
+}
+
+// TestRunCancelsRequests tests that notifications/cancelled aborts an in-flight request
+func TestRunCancelsRequests(t *testing.T) {
+	cancelled := make(chan struct{})
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(cancelled)
+	}))
+	defer vault.Close()
+
+	input, output := startRun(t, NewMCPServer("test-token", vault.URL))
+
+	_, err := io.WriteString(input, `{"jsonrpc": "2.0", "id": "slow", "method": "tools/call", "params": {"name": "get_file_content", "arguments": {"filename": "slow.md"}}}`+"\n")
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	_, err = io.WriteString(input, `{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {"requestId": "slow", "reason": "user abort"}}`+"\n")
+	require.NoError(t, err)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("request to Obsidian was not cancelled")
+	}
+
+	_, err = io.WriteString(input, `{"jsonrpc": "2.0", "id": "ping", "method": "ping"}`+"\n")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(output.messages(t)) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// The cancelled request must not be answered
+	time.Sleep(20 * time.Millisecond)
+	messages := output.messages(t)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "ping", messages[0]["id"])
+}
+
+// TestRunInvalidRequestIDs tests that object and array IDs are rejected and
+// cancellations naming them are ignored instead of crashing the server
+func TestRunInvalidRequestIDs(t *testing.T) {
+	input, output := startRun(t, NewMCPServer("test-token", "http://localhost:27123"))
+
+	for _, line := range []string{
+		`{"jsonrpc": "2.0", "id": {"a": 1}, "method": "ping"}`,
+		`{"jsonrpc": "2.0", "id": [1], "method": "ping"}`,
+		`{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {"requestId": {"a": 1}}}`,
+		`{"jsonrpc": "2.0", "id": 7, "method": "ping"}`,
+	} {
+		_, err := io.WriteString(input, line+"\n")
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool {
+		return len(output.messages(t)) == 3
+	}, time.Second, 5*time.Millisecond)
+
+	var invalid int
+	for _, message := range output.messages(t) {
+		if message["error"] != nil {
+			assert.Equal(t, float64(-32600), message["error"].(map[string]any)["code"])
+			invalid++
+		} else {
+			assert.Equal(t, float64(7), message["id"])
+		}
+	}
+	assert.Equal(t, 2, invalid)
+}
+
+// TestRunSendsProgressNotifications tests that a tool call carrying a progress
+// token reports progress before its response
+func TestRunSendsProgressNotifications(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("# " + r.URL.Path))
+	}))
+	defer vault.Close()
+
+	input, output := startRun(t, NewMCPServer("test-token", vault.URL))
+
+	_, err := io.WriteString(input, `{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "get_multiple_files", "arguments": {"filenames": ["a.md", "b.md"]}, "_meta": {"progressToken": "tok"}}}`+"\n")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(output.messages(t)) == 3
+	}, time.Second, 5*time.Millisecond)
+
+	messages := output.messages(t)
+	for i, message := range messages[:2] {
+		assert.Equal(t, "notifications/progress", message["method"])
+		params := message["params"].(map[string]any)
+		assert.Equal(t, "tok", params["progressToken"])
+		assert.Equal(t, float64(i+1), params["progress"])
+		assert.Equal(t, float64(2), params["total"])
+	}
+	assert.Equal(t, float64(1), messages[2]["id"])
+	assert.Nil(t, messages[2]["error"])
+}
+
+// TestToolsCallWithoutProgressToken tests that no progress is reported unless requested
+func TestToolsCallWithoutProgressToken(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("# Note"))
+	}))
+	defer vault.Close()
+
+	input, output := startRun(t, NewMCPServer("test-token", vault.URL))
+
+	_, err := io.WriteString(input, `{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "get_multiple_files", "arguments": {"filenames": ["a.md", "b.md"]}}}`+"\n")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(output.messages(t)) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, float64(1), output.messages(t)[0]["id"])
+}
+
+// TestHandleInitializeNegotiatesVersion tests that a supported protocol version is echoed back
+func TestHandleInitializeNegotiatesVersion(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123")
+
+	for requested, expected := range map[string]string{
+		"2025-06-18": "2025-06-18",
+		"2024-11-05": "2024-11-05",
+		"1999-01-01": defaultProtocolVersion,
+	} {
+		response := server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "initialize",
+			Params:  map[string]any{"protocolVersion": requested},
+		})
+		require.NotNil(t, response)
+		assert.Equal(t, expected, response.Result.(map[string]any)["protocolVersion"], requested)
+	}
+}
+
+// TestToolsDeclareOutputSchema tests that every tool describes its structured output
+func TestToolsDeclareOutputSchema(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123")
+
+	response := server.handleRequest(context.Background(), &MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	require.NotNil(t, response)
+
+	for _, tool := range response.Result.(map[string]any)["tools"].([]ToolInfo) {
+		schema, ok := tool.OutputSchema.(map[string]any)
+		require.True(t, ok, tool.Name)
+		assert.Equal(t, "object", schema["type"], tool.Name)
+	}
+}
+
+// TestHandleToolsCallStructuredContent tests that typed results are returned as structured content
+func TestHandleToolsCallStructuredContent(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search/simple/", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"filename": "a.md", "score": 1.5, "matches": [{"match": {"start": 0, "end": 4}, "context": "test"}]}]`))
+	}))
+	defer vault.Close()
+
+	server := NewMCPServer("test-token", vault.URL)
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params: map[string]any{
+			"name":      "search_vault_simple",
+			"arguments": map[string]any{"query": "test"},
+		},
+	})
+	require.NotNil(t, response)
+	require.Nil(t, response.Error)
+
+	result := response.Result.(map[string]any)
+	structured, ok := result["structuredContent"].(map[string]any)
+	require.True(t, ok)
+	results, ok := structured["results"].([]obsidian.SearchResult)
+	require.True(t, ok)
+	require.Len(t, results, 1)
+	assert.Equal(t, "a.md", results[0].Filename)
+
+	// The text content carries the same value for clients without structured output support
+	text := result["content"].([]map[string]any)[0]["text"].(string)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal([]byte(text), &decoded))
+	assert.Contains(t, decoded, "results")
+}
+
+// TestHandleToolsCallMarkdownContent tests that markdown is returned as plain text
+func TestHandleToolsCallMarkdownContent(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("# Note"))
+	}))
+	defer vault.Close()
+
+	server := NewMCPServer("test-token", vault.URL)
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params: map[string]any{
+			"name":      "get_file_content",
+			"arguments": map[string]any{"filename": "note.md"},
+		},
+	})
+	require.NotNil(t, response)
+	require.Nil(t, response.Error)
+
+	result := response.Result.(map[string]any)
+	assert.Equal(t, "# Note", result["content"].([]map[string]any)[0]["text"])
+	assert.Equal(t, map[string]any{"path": "note.md", "content": "# Note"}, result["structuredContent"])
+}
+
+// TestHandleToolsCallGetFrontmatterField tests reading a single frontmatter property
+func TestHandleToolsCallGetFrontmatterField(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/vnd.olrapi.note+json", r.Header.Get("Accept"))
+		_, _ = w.Write([]byte(`{"path": "a.md", "content": "", "frontmatter": {"rating": 4, "tags": ["x"]}}`))
+	}))
+	defer vault.Close()
+
+	server := NewMCPServer("test-token", vault.URL)
+	call := func(args map[string]any) map[string]any {
+		t.Helper()
+		response := server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "tools/call",
+			Params:  map[string]any{"name": "get_frontmatter", "arguments": args},
+		})
+		require.NotNil(t, response)
+		require.Nil(t, response.Error)
+		return response.Result.(map[string]any)["structuredContent"].(map[string]any)
+	}
+
+	structured := call(map[string]any{"filename": "a.md", "field": "rating"})
+	assert.Equal(t, float64(4), structured["value"])
+	assert.Equal(t, true, structured["exists"])
+
+	structured = call(map[string]any{"filename": "a.md", "field": "missing"})
+	assert.Nil(t, structured["value"])
+	assert.Equal(t, false, structured["exists"])
+
+	structured = call(map[string]any{"filename": "a.md"})
+	assert.Equal(t, map[string]any{"rating": float64(4), "tags": []any{"x"}}, structured["frontmatter"])
+}
+
+// TestHandleToolsCallGetNoteSection tests reading the section below a heading path
+func TestHandleToolsCallGetNoteSection(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("# Projects\nIntro\n## Alpha\nAlpha notes\n### Tasks\n- [ ] one\n## Beta\nBeta notes\n"))
+	}))
+	defer vault.Close()
+
+	server := NewMCPServer("test-token", vault.URL)
+	call := func(args map[string]any) map[string]any {
+		t.Helper()
+		response := server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "tools/call",
+			Params:  map[string]any{"name": "get_note_section", "arguments": args},
+		})
+		require.NotNil(t, response)
+		require.Nil(t, response.Error)
+		return response.Result.(map[string]any)
+	}
+
+	result := call(map[string]any{"filename": "a.md", "heading": "Projects::Alpha"})
+	assert.Equal(t, "Alpha notes\n### Tasks\n- [ ] one\n", result["content"].([]map[string]any)[0]["text"])
+	structured := result["structuredContent"].(map[string]any)
+	assert.Equal(t, 4, structured["startLine"])
+	assert.Equal(t, 6, structured["endLine"])
+
+	result = call(map[string]any{"filename": "a.md", "heading": "Projects/Alpha", "delimiter": "/", "includeSubsections": false, "includeHeading": true})
+	assert.Equal(t, "## Alpha\nAlpha notes\n", result["content"].([]map[string]any)[0]["text"])
+	assert.Equal(t, 3, result["structuredContent"].(map[string]any)["startLine"])
+
+	result = call(map[string]any{"filename": "a.md", "heading": "Alpha"})
+	assert.Equal(t, true, result["isError"])
+}
+
+// TestHandleToolsCallBinaryContent tests that binary files are returned as
+// image or embedded resource content
+func TestHandleToolsCallBinaryContent(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/assets/shot.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte{0x89, 'P', 'N', 'G'})
+		case "/vault/docs/My File.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write([]byte("%PDF"))
+		}
+	}))
+	defer vault.Close()
+
+	server := NewMCPServer("test-token", vault.URL)
+	call := func(filename string) map[string]any {
+		t.Helper()
+		response := server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "tools/call",
+			Params:  map[string]any{"name": "get_file_content", "arguments": map[string]any{"filename": filename}},
+		})
+		require.NotNil(t, response)
+		require.Nil(t, response.Error)
+		return response.Result.(map[string]any)
+	}
+
+	result := call("assets/shot.png")
+	assert.Equal(t, []map[string]any{{"type": "image", "data": "iVBORw==", "mimeType": "image/png"}}, result["content"])
+	assert.Equal(t, map[string]any{"path": "assets/shot.png", "mimeType": "image/png", "size": 4}, result["structuredContent"])
+
+	result = call("docs/My File.pdf")
+	assert.Equal(t, []map[string]any{{
+		"type": "resource",
+		"resource": map[string]any{
+			"uri":      "obsidian://vault/docs/My%20File.pdf",
+			"mimeType": "application/pdf",
+			"blob":     "JVBERg==",
+		},
+	}}, result["content"])
+}
+
+// TestHandleToolsCallUploadAttachment tests uploading base64 attachments
+func TestHandleToolsCallUploadAttachment(t *testing.T) {
+	var uploaded []string
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		uploaded = append(uploaded, r.Header.Get("Content-Type")+" "+string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer vault.Close()
+
+	server := NewMCPServer("test-token", vault.URL)
+	call := func(args map[string]any) map[string]any {
+		t.Helper()
+		response := server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "tools/call",
+			Params:  map[string]any{"name": "upload_attachment", "arguments": args},
+		})
+		require.NotNil(t, response)
+		require.Nil(t, response.Error)
+		return response.Result.(map[string]any)
+	}
+
+	result := call(map[string]any{"filename": "a.png", "data": "aGVs\nbG8="})
+	assert.NotContains(t, result, "isError")
+	result = call(map[string]any{"filename": "b", "data": "data:image/gif;base64,aGVsbG8="})
+	assert.NotContains(t, result, "isError")
+	assert.Equal(t, []string{"image/png hello", "image/gif hello"}, uploaded)
+
+	result = call(map[string]any{"filename": "c.png", "data": "not base64!"})
+	assert.Equal(t, true, result["isError"])
+}
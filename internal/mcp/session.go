@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// session holds the state of a single connected MCP client. The stdio
+// transport has exactly one session, the HTTP transport one per client.
+type session struct {
+	id string
+
+	// write delivers a single encoded JSON-RPC message to the client
+	write   func(data []byte) error
+	writeMu sync.Mutex
+
+	subscriptions *subscriptions
+
+	// inflight holds the cancel functions of requests still being handled,
+	// keyed by request ID
+	inflight   map[any]context.CancelFunc
+	inflightMu sync.Mutex
+
+	// clientInfo is the clientInfo the client sent in initialize
+	clientInfo   map[string]any
+	clientInfoMu sync.Mutex
+}
+
+// newSession creates a session that delivers messages through write
+func newSession(id string, write func(data []byte) error) *session {
+	return &session{
+		id:            id,
+		write:         write,
+		subscriptions: newSubscriptions(),
+		inflight:      make(map[any]context.CancelFunc),
+	}
+}
+
+// track derives a cancellable context for a request so that it can be
+// aborted by a notifications/cancelled message. The returned function must
+// be called once the request has been handled.
+func (sess *session) track(ctx context.Context, id any) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	sess.inflightMu.Lock()
+	sess.inflight[id] = cancel
+	sess.inflightMu.Unlock()
+
+	return ctx, func() {
+		sess.inflightMu.Lock()
+		delete(sess.inflight, id)
+		sess.inflightMu.Unlock()
+		cancel()
+	}
+}
+
+// cancel aborts an in-flight request. Unknown or finished requests are ignored.
+func (sess *session) cancel(id any) {
+	sess.inflightMu.Lock()
+	defer sess.inflightMu.Unlock()
+	if cancel, ok := sess.inflight[id]; ok {
+		cancel()
+	}
+}
+
+// setClientInfo records the clientInfo sent in initialize
+func (sess *session) setClientInfo(info map[string]any) {
+	sess.clientInfoMu.Lock()
+	defer sess.clientInfoMu.Unlock()
+	sess.clientInfo = info
+}
+
+// getClientInfo returns the clientInfo sent in initialize, if any
+func (sess *session) getClientInfo() map[string]any {
+	sess.clientInfoMu.Lock()
+	defer sess.clientInfoMu.Unlock()
+	return sess.clientInfo
+}
+
+// send writes a JSON-RPC message to the client. Writes are serialized so
+// responses and notifications from concurrent goroutines never interleave.
+func (sess *session) send(message any) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	sess.writeMu.Lock()
+	defer sess.writeMu.Unlock()
+	return sess.write(data)
+}
+
+// notify sends a notification to the client
+func (sess *session) notify(method string, params any) error {
+	return sess.send(&MCPNotification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+}
+
+// sessionContextKey is the context key under which the current session is stored
+type sessionContextKey struct{}
+
+// withSession returns a context carrying the given session
+func withSession(ctx context.Context, sess *session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, sess)
+}
+
+// sessionFor returns the session a request belongs to, defaulting to the
+// stdio session when the context carries none
+func (s *MCPServer) sessionFor(ctx context.Context) *session {
+	if sess, ok := ctx.Value(sessionContextKey{}).(*session); ok {
+		return sess
+	}
+	return s.stdio
+}
+
+// addSession registers a session so it takes part in subscription polling
+func (s *MCPServer) addSession(sess *session) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	s.sessions[sess.id] = sess
+}
+
+// removeSession unregisters a session
+func (s *MCPServer) removeSession(id string) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	delete(s.sessions, id)
+}
+
+// allSessions returns every registered session
+func (s *MCPServer) allSessions() []*session {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	sessions := make([]*session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	return sessions
+}
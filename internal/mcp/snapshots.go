@@ -0,0 +1,174 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/diff"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/snapshot"
+)
+
+// defaultSnapshotLimit is the number of snapshots list_snapshots returns
+const defaultSnapshotLimit = 50
+
+// errSnapshotsDisabled is returned by the snapshot tools without a store
+var errSnapshotsDisabled = errors.New("snapshots are disabled, start the server with -snapshot-dir to enable them")
+
+// WithSnapshots makes the server snapshot files before they are changed and
+// enables the snapshot tools
+func WithSnapshots(store *snapshot.Store) Option {
+	return func(s *MCPServer) {
+		s.snapshots = store
+	}
+}
+
+// listSnapshots returns the newest snapshots of a file, or of every file
+// when filename is empty
+func (s *MCPServer) listSnapshots(filename string, limit int) ([]snapshot.Snapshot, error) {
+	if s.snapshots == nil {
+		return nil, errSnapshotsDisabled
+	}
+	snaps, err := s.snapshots.List(filename)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultSnapshotLimit
+	}
+	return snaps[:min(limit, len(snaps))], nil
+}
+
+// diffSnapshot returns the unified diff from a snapshot to the current
+// content of its file
+func (s *MCPServer) diffSnapshot(ctx context.Context, id string) (*toolResult, error) {
+	if s.snapshots == nil {
+		return nil, errSnapshotsDisabled
+	}
+	snap, old, err := s.snapshots.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	current, exists, err := s.currentContent(ctx, snap.Path)
+	if err != nil {
+		return nil, err
+	}
+	for _, data := range [][]byte{old, current} {
+		if (&obsidian.File{Path: snap.Path, MimeType: "application/octet-stream", Data: data}).IsBinary() {
+			return nil, fmt.Errorf("cannot diff binary file %s", snap.Path)
+		}
+	}
+
+	oldName, newName := snap.Path+"@"+snap.ID, snap.Path
+	if !snap.Exists {
+		oldName = "/dev/null"
+	}
+	if !exists {
+		newName = "/dev/null"
+	}
+	unified := diff.Unified(oldName, newName, string(old), string(current))
+	text := unified
+	if text == "" {
+		text = fmt.Sprintf("%s is unchanged since snapshot %s", snap.Path, snap.ID)
+	}
+	return &toolResult{
+		text: text,
+		structured: map[string]any{
+			"id":      snap.ID,
+			"path":    snap.Path,
+			"changed": unified != "" || snap.Exists != exists,
+			"diff":    unified,
+		},
+	}, nil
+}
+
+// undoLastChange restores the snapshots taken before the newest change that
+// has not been undone yet, to one file or to any file when filename is
+// empty. Every file the tool call behind the change touched is restored.
+func (s *MCPServer) undoLastChange(ctx context.Context, filename string) (string, error) {
+	if s.snapshots == nil {
+		return "", errSnapshotsDisabled
+	}
+	snaps, err := s.snapshots.Latest(filename)
+	if errors.Is(err, snapshot.ErrNotFound) {
+		if filename != "" {
+			return "", fmt.Errorf("no changes to %s can be undone", filename)
+		}
+		return "", fmt.Errorf("no changes can be undone")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Restoring newest first leaves a file touched twice at its oldest content
+	var paths, messages []string
+	for _, snap := range snaps {
+		message, err := s.restoreSnapshot(ctx, snap.ID)
+		if err != nil {
+			return "", err
+		}
+		if !slices.Contains(paths, snap.Path) {
+			paths = append(paths, snap.Path)
+		}
+		messages = append(messages, message)
+	}
+
+	latest := snaps[0]
+	if len(snaps) == 1 {
+		return fmt.Sprintf("Undid %s of %s made at %s. %s", latest.Operation, latest.Path, latest.Time.Format(time.RFC3339), messages[0]), nil
+	}
+	return fmt.Sprintf("Undid the changes to %s made at %s. %s", strings.Join(paths, ", "), latest.Time.Format(time.RFC3339), strings.Join(messages, ". ")), nil
+}
+
+// restoreSnapshot writes the content of a snapshot back to its file, or
+// deletes the file when it did not exist at the time. The file is itself
+// snapshotted first, so a restore can be rolled back with restore_snapshot.
+func (s *MCPServer) restoreSnapshot(ctx context.Context, id string) (string, error) {
+	if s.snapshots == nil {
+		return "", errSnapshotsDisabled
+	}
+	snap, content, err := s.snapshots.Get(id)
+	if err != nil {
+		return "", err
+	}
+
+	ctx = obsidian.WithSnapshotOperation(ctx, snapshot.OperationRestore)
+	var message string
+	if snap.Exists {
+		if _, err := s.obsidianClient.UploadAttachment(ctx, snap.Path, content, ""); err != nil {
+			return "", err
+		}
+		message = fmt.Sprintf("Restored %s from snapshot %s", snap.Path, snap.ID)
+	} else {
+		_, err := s.obsidianClient.DeleteFile(ctx, snap.Path)
+		var apiErr *obsidian.APIError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.NotFound()) {
+			return "", err
+		}
+		message = fmt.Sprintf("Deleted %s, which did not exist at snapshot %s", snap.Path, snap.ID)
+	}
+
+	if err := s.snapshots.MarkUndone(snap.ID); err != nil {
+		return "", err
+	}
+	return message, nil
+}
+
+// currentContent reads the raw content of a vault file, reporting whether it
+// exists
+func (s *MCPServer) currentContent(ctx context.Context, filename string) ([]byte, bool, error) {
+	file, err := s.obsidianClient.GetFile(ctx, filename)
+	var apiErr *obsidian.APIError
+	if errors.As(err, &apiErr) && apiErr.NotFound() {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return file.Data, true, nil
+}
@@ -0,0 +1,169 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSnapshotTools tests that changes are snapshotted and can be diffed,
+// undone and restored
+func TestSnapshotTools(t *testing.T) {
+	var mu sync.Mutex
+	files := map[string]string{"a.md": "one\n"}
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		file := strings.TrimPrefix(r.URL.Path, "/vault/")
+		content, exists := files[file]
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.Method == http.MethodPut:
+			files[file] = string(body)
+		case r.Method == http.MethodPost:
+			files[file] = content + string(body)
+		case !exists:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case r.Method == http.MethodDelete:
+			delete(files, file)
+		default:
+			w.Header().Set("Content-Type", "text/markdown")
+			_, _ = w.Write([]byte(content))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer vault.Close()
+
+	store, err := snapshot.Open(t.TempDir(), snapshot.Retention{})
+	require.NoError(t, err)
+	server := NewMCPServer("test-token", vault.URL, WithSnapshots(store))
+	call := func(name string, args map[string]any) map[string]any {
+		t.Helper()
+		response := server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      name,
+			Method:  "tools/call",
+			Params:  map[string]any{"name": name, "arguments": args},
+		})
+		require.NotNil(t, response)
+		require.Nil(t, response.Error)
+		result := response.Result.(map[string]any)
+		require.NotContains(t, result, "isError", result["content"])
+		return result
+	}
+
+	call("append_to_file", map[string]any{"filename": "a.md", "content": "two\n"})
+	call("create_or_update_file", map[string]any{"filename": "b.md", "content": "new\n"})
+
+	snaps := call("list_snapshots", map[string]any{})["structuredContent"].(map[string]any)["snapshots"].([]snapshot.Snapshot)
+	require.Len(t, snaps, 2)
+	assert.Equal(t, "b.md", snaps[0].Path)
+	assert.False(t, snaps[0].Exists)
+	assert.Equal(t, "append", snaps[1].Operation)
+	assert.NotEmpty(t, snaps[0].OperationID)
+	assert.NotEqual(t, snaps[0].OperationID, snaps[1].OperationID)
+
+	diff := call("diff_snapshot", map[string]any{"id": snaps[1].ID})["structuredContent"].(map[string]any)
+	assert.Equal(t, true, diff["changed"])
+	assert.Equal(t, "--- a.md@"+snaps[1].ID+"\n+++ a.md\n@@ -1 +1,2 @@\n one\n+two\n", diff["diff"])
+
+	// Undo rolls back the creation of b.md, then the append to a.md
+	call("undo_last_change", map[string]any{})
+	assert.NotContains(t, files, "b.md")
+	result := call("undo_last_change", map[string]any{})
+	assert.Equal(t, "one\n", files["a.md"])
+	assert.Contains(t, result["content"].([]map[string]any)[0]["text"], "Undid append of a.md")
+
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  map[string]any{"name": "undo_last_change", "arguments": map[string]any{}},
+	})
+	assert.Equal(t, "no changes can be undone", response.Result.(map[string]any)["content"].([]map[string]any)[0]["text"])
+
+	// The undo itself was snapshotted and can be restored explicitly
+	snaps, err = store.List("a.md")
+	require.NoError(t, err)
+	require.Equal(t, snapshot.OperationRestore, snaps[0].Operation)
+	call("restore_snapshot", map[string]any{"id": snaps[0].ID})
+	assert.Equal(t, "one\ntwo\n", files["a.md"])
+}
+
+// TestUndoLastChangeOperation tests that undo rolls back every file changed
+// by the newest tool call
+func TestUndoLastChangeOperation(t *testing.T) {
+	var mu sync.Mutex
+	files := map[string]string{"new.md": "moved\n", "c.md": "c2\n"}
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		file := strings.TrimPrefix(r.URL.Path, "/vault/")
+		content, exists := files[file]
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.Method == http.MethodPut:
+			files[file] = string(body)
+		case !exists:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case r.Method == http.MethodDelete:
+			delete(files, file)
+		default:
+			w.Header().Set("Content-Type", "text/markdown")
+			_, _ = w.Write([]byte(content))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer vault.Close()
+
+	store, err := snapshot.Open(t.TempDir(), snapshot.Retention{})
+	require.NoError(t, err)
+	require.NoError(t, store.Save("c.md", "update", "op1", []byte("c1\n"), true))
+	// A move creates the destination and deletes the source
+	require.NoError(t, store.Save("new.md", "update", "op2", nil, false))
+	require.NoError(t, store.Save("old.md", "delete", "op2", []byte("moved\n"), true))
+
+	server := NewMCPServer("test-token", vault.URL, WithSnapshots(store))
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  map[string]any{"name": "undo_last_change", "arguments": map[string]any{"filename": "new.md"}},
+	})
+	require.Nil(t, response.Error)
+	result := response.Result.(map[string]any)
+	require.NotContains(t, result, "isError", result["content"])
+	assert.Contains(t, result["content"].([]map[string]any)[0]["text"], "Undid the changes to old.md, new.md")
+	assert.Equal(t, map[string]string{"old.md": "moved\n", "c.md": "c2\n"}, files)
+
+	latest, err := store.Latest("")
+	require.NoError(t, err)
+	require.Len(t, latest, 1)
+	assert.Equal(t, "c.md", latest[0].Path)
+}
+
+// TestSnapshotToolsDisabled tests the error returned without a store
+func TestSnapshotToolsDisabled(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123")
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  map[string]any{"name": "list_snapshots", "arguments": map[string]any{}},
+	})
+	result := response.Result.(map[string]any)
+	assert.Equal(t, true, result["isError"])
+	assert.Contains(t, result["content"].([]map[string]any)[0]["text"], "-snapshot-dir")
+}
@@ -0,0 +1,178 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+)
+
+// subscriptions tracks subscribed resource URIs and the last version seen for
+// each of them
+type subscriptions struct {
+	mu       sync.Mutex
+	versions map[string]string
+}
+
+// newSubscriptions creates an empty subscription set
+func newSubscriptions() *subscriptions {
+	return &subscriptions{
+		versions: make(map[string]string),
+	}
+}
+
+// add subscribes to a URI with its current version
+func (s *subscriptions) add(uri, version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.versions[uri] = version
+}
+
+// remove unsubscribes from a URI
+func (s *subscriptions) remove(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.versions, uri)
+}
+
+// uris returns the currently subscribed URIs
+func (s *subscriptions) uris() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Sorted(maps.Keys(s.versions))
+}
+
+// update records a new version for a URI and reports whether it differs
+// from the previous one. URIs unsubscribed in the meantime are ignored.
+func (s *subscriptions) update(uri, version string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	last, ok := s.versions[uri]
+	if !ok || last == version {
+		return false
+	}
+	s.versions[uri] = version
+	return true
+}
+
+// handleResourcesSubscribe subscribes to change notifications for a vault note
+func (s *MCPServer) handleResourcesSubscribe(ctx context.Context, request *MCPRequest) *MCPResponse {
+	uri, ok := request.Params["uri"].(string)
+	if !ok || uri == "" {
+		return s.createErrorResponse(request.ID, -32602, "Invalid params: missing uri")
+	}
+
+	filename, ok := vaultFileFromURI(uri)
+	if !ok {
+		return s.createErrorResponse(request.ID, -32602, fmt.Sprintf("Invalid params: resource does not support subscriptions: %s", uri))
+	}
+
+	version, err := s.resourceVersion(ctx, filename)
+	if err != nil {
+		return s.createErrorResponse(request.ID, -32603, err.Error())
+	}
+	s.sessionFor(ctx).subscriptions.add(uri, version)
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      request.ID,
+		Result:  map[string]any{},
+	}
+}
+
+// handleResourcesUnsubscribe cancels a resource subscription
+func (s *MCPServer) handleResourcesUnsubscribe(ctx context.Context, request *MCPRequest) *MCPResponse {
+	uri, ok := request.Params["uri"].(string)
+	if !ok || uri == "" {
+		return s.createErrorResponse(request.ID, -32602, "Invalid params: missing uri")
+	}
+
+	s.sessionFor(ctx).subscriptions.remove(uri)
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      request.ID,
+		Result:  map[string]any{},
+	}
+}
+
+// pollSubscriptions periodically checks subscribed notes for changes until
+// the context is cancelled. The Local REST API has no push channel, so
+// changes are detected by comparing the note's mtime with the last one seen.
+func (s *MCPServer) pollSubscriptions(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkSubscriptions(ctx)
+		}
+	}
+}
+
+// checkSubscriptions fetches every subscribed file once and notifies the
+// clients subscribed to the ones that changed. A deleted file is reported
+// once and its subscription dropped.
+func (s *MCPServer) checkSubscriptions(ctx context.Context) {
+	for _, sess := range s.allSessions() {
+		for _, uri := range sess.subscriptions.uris() {
+			if ctx.Err() != nil {
+				return
+			}
+
+			filename, _ := vaultFileFromURI(uri)
+			version, err := s.resourceVersion(ctx, filename)
+			var apiErr *obsidian.APIError
+			switch {
+			case errors.As(err, &apiErr) && apiErr.NotFound():
+				sess.subscriptions.remove(uri)
+			case err != nil:
+				fmt.Fprintf(s.stderr, "Failed to poll subscribed resource %s: %v\n", uri, err)
+				continue
+			case !sess.subscriptions.update(uri, version):
+				continue
+			}
+
+			if err := sess.notify("notifications/resources/updated", map[string]any{"uri": uri}); err != nil {
+				fmt.Fprintf(s.stderr, "Failed to send resource update notification: %v\n", err)
+			}
+		}
+	}
+}
+
+// resourceVersion returns a value that changes whenever a subscribed vault
+// file does: the mtime of markdown notes, which have a NoteJson
+// representation, and otherwise the modification time and size reported by a
+// metadata request. Files whose metadata carries neither are hashed.
+func (s *MCPServer) resourceVersion(ctx context.Context, filename string) (string, error) {
+	if path.Ext(filename) == ".md" {
+		note, err := s.obsidianClient.GetNote(ctx, filename)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(note.Stat.Mtime, 10), nil
+	}
+
+	stat, err := s.obsidianClient.StatFile(ctx, filename)
+	if err != nil {
+		return "", err
+	}
+	if stat.Mtime != 0 || stat.Size >= 0 {
+		return fmt.Sprintf("%d/%d", stat.Mtime, stat.Size), nil
+	}
+	file, err := s.obsidianClient.GetFile(ctx, filename)
+	if err != nil {
+		return "", err
+	}
+	return obsidian.ContentHash(string(file.Data)), nil
+}
@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// messages decodes every JSON-RPC message written so far
+func (b *syncBuffer) messages(t *testing.T) []map[string]any {
+	t.Helper()
+	var messages []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var message map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &message))
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// TestSubscriptionsUpdate tests change detection in the subscription set
+func TestSubscriptionsUpdate(t *testing.T) {
+	subs := newSubscriptions()
+	subs.add("obsidian://vault/a.md", "100")
+
+	assert.False(t, subs.update("obsidian://vault/a.md", "100"))
+	assert.True(t, subs.update("obsidian://vault/a.md", "200"))
+	assert.False(t, subs.update("obsidian://vault/b.md", "300"))
+
+	subs.remove("obsidian://vault/a.md")
+	assert.Empty(t, subs.uris())
+}
+
+// TestHandleResourcesSubscribeInvalidURI tests subscribing to an unsupported URI
+func TestHandleResourcesSubscribeInvalidURI(t *testing.T) {
+	server := NewMCPServer("test-token", "http://localhost:27123")
+
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      "sub",
+		Method:  "resources/subscribe",
+		Params:  map[string]any{"uri": "obsidian://periodic/daily"},
+	})
+	require.NotNil(t, response.Error)
+	assert.Equal(t, -32602, response.Error.Code)
+	assert.Empty(t, server.stdio.subscriptions.uris())
+}
+
+// TestResourceSubscriptionNotifications tests that Run emits update
+// notifications when a subscribed note's mtime changes
+func TestResourceSubscriptionNotifications(t *testing.T) {
+	var mtime atomic.Int64
+	mtime.Store(1000)
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/vault/note.md", r.URL.Path)
+		w.Header().Set("Content-Type", "application/vnd.olrapi.note+json")
+		_, _ = fmt.Fprintf(w, `{"content": "", "path": "note.md", "tags": [], "frontmatter": {}, "stat": {"ctime": 1, "mtime": %d, "size": 0}}`, mtime.Load())
+	}))
+	defer vault.Close()
+
+	stdin, input := io.Pipe()
+	var output syncBuffer
+	server := NewMCPServer("test-token", vault.URL, WithPollInterval(10*time.Millisecond))
+	server.stdin = stdin
+	server.stdout = &output
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Run()
+	}()
+
+	_, err := io.WriteString(input, `{"jsonrpc": "2.0", "id": 1, "method": "resources/subscribe", "params": {"uri": "obsidian://vault/note.md"}}`+"\n")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(output.messages(t)) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Nil(t, output.messages(t)[0]["error"])
+
+	// Unchanged notes must not produce notifications
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, output.messages(t), 1)
+
+	mtime.Store(2000)
+	require.Eventually(t, func() bool {
+		return len(output.messages(t)) == 2
+	}, time.Second, 5*time.Millisecond)
+
+	notification := output.messages(t)[1]
+	assert.Equal(t, "notifications/resources/updated", notification["method"])
+	assert.Equal(t, map[string]any{"uri": "obsidian://vault/note.md"}, notification["params"])
+
+	require.NoError(t, input.Close())
+	require.NoError(t, <-done)
+}
+
+// TestCheckSubscriptionsDeletedAndAttachments tests that a deleted note is
+// reported once and attachments are polled with HEAD requests, or by hashing
+// them when the response carries no metadata
+func TestCheckSubscriptionsDeletedAndAttachments(t *testing.T) {
+	modified := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	drawing := "v1"
+	var requests []string
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/vault/image.png" && r.Method == http.MethodHead:
+			w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+			w.Header().Set("Content-Length", "4")
+		case r.URL.Path == "/vault/drawing.excalidraw" && r.Method == http.MethodHead:
+			// Neither Last-Modified nor Content-Length
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/vault/drawing.excalidraw":
+			_, _ = io.WriteString(w, drawing)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+
+	var output syncBuffer
+	server := NewMCPServer("test-token", vault.URL)
+	server.stdout = &output
+	ctx := context.Background()
+	server.stdio.subscriptions.add("obsidian://vault/gone.md", "1000")
+	for _, file := range []string{"image.png", "drawing.excalidraw"} {
+		version, err := server.resourceVersion(ctx, file)
+		require.NoError(t, err)
+		server.stdio.subscriptions.add(vaultFileURI(file), version)
+	}
+
+	server.checkSubscriptions(ctx)
+	require.Len(t, output.messages(t), 1)
+	assert.Equal(t, map[string]any{"uri": "obsidian://vault/gone.md"}, output.messages(t)[0]["params"])
+	assert.Equal(t, []string{"obsidian://vault/drawing.excalidraw", "obsidian://vault/image.png"}, server.stdio.subscriptions.uris())
+
+	modified = modified.Add(time.Minute)
+	requests = nil
+	server.checkSubscriptions(ctx)
+	assert.ElementsMatch(t, []string{"HEAD /vault/image.png", "HEAD /vault/drawing.excalidraw", "GET /vault/drawing.excalidraw"}, requests)
+	require.Len(t, output.messages(t), 2)
+	assert.Equal(t, map[string]any{"uri": "obsidian://vault/image.png"}, output.messages(t)[1]["params"])
+
+	drawing = "v2"
+	server.checkSubscriptions(ctx)
+	require.Len(t, output.messages(t), 3)
+	assert.Equal(t, map[string]any{"uri": "obsidian://vault/drawing.excalidraw"}, output.messages(t)[2]["params"])
+}
@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/index"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/markdown"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/tags"
+)
+
+// noteTags returns the tags Obsidian reports for every note of the vault
+func noteTags(vault *index.Vault) map[string][]string {
+	byNote := make(map[string][]string, len(vault.Notes))
+	for file, note := range vault.Notes {
+		byNote[file] = note.Tags
+	}
+	return byNote
+}
+
+// renameTags renames the source tags, and the tags nested below them, to
+// target in every note using them. Each note is only written if it has not
+// changed since the index read it; the others are reported in Errors. With
+// dryRun set nothing is written.
+func (s *MCPServer) renameTags(ctx context.Context, sources []string, target string, dryRun bool) (*tags.RenameResult, error) {
+	target = tags.Normalize(target)
+	if !markdown.IsValidTag(target) {
+		return nil, fmt.Errorf("invalid tag: %s", target)
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("at least one tag to rename is required")
+	}
+	for i, source := range sources {
+		sources[i] = tags.Normalize(source)
+		if sources[i] == "" {
+			return nil, fmt.Errorf("tags to rename cannot be empty")
+		}
+		if tags.Matches(target, sources[i], true) {
+			return nil, fmt.Errorf("cannot rename %s to %s: the new tag is nested below it", sources[i], target)
+		}
+	}
+
+	vault, err := s.index.Refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for file, note := range vault.Notes {
+		if slices.ContainsFunc(sources, func(source string) bool {
+			return slices.ContainsFunc(note.Tags, func(tag string) bool { return tags.Matches(tag, source, true) })
+		}) {
+			candidates = append(candidates, file)
+		}
+	}
+	slices.Sort(candidates)
+
+	result := &tags.RenameResult{
+		Tags:         sources,
+		Target:       target,
+		DryRun:       dryRun,
+		UpdatedFiles: []tags.FileChange{},
+	}
+	rename := tags.Renamer(sources, target)
+	for i, file := range candidates {
+		obsidian.ReportProgress(ctx, float64(i+1), float64(len(candidates)), "Scanned "+file)
+		note := vault.Notes[file]
+		updated := markdown.ReplaceTags(note.Content, rename)
+		if updated != note.Content {
+			if !dryRun {
+				guarded := obsidian.WithPrecondition(ctx, obsidian.Precondition{Path: file, Mtime: note.Stat.Mtime})
+				if _, err := s.obsidianClient.CreateOrUpdateFile(guarded, file, updated, "text/markdown"); err != nil {
+					if result.Errors == nil {
+						result.Errors = make(map[string]string)
+					}
+					result.Errors[file] = err.Error()
+					continue
+				}
+			}
+			result.UpdatedFiles = append(result.UpdatedFiles, tags.FileChange{Path: file, Changes: tags.LineChanges(note.Content, updated)})
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/tags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTagTools tests listing, finding and renaming tags
+func TestTagTools(t *testing.T) {
+	var mu sync.Mutex
+	notes := map[string]*obsidian.Note{
+		"A.md": {Content: "---\ntags: [project/alpha]\n---\n#todo", Tags: []string{"#project/alpha", "#todo"}},
+		"B.md": {Content: "#project and #Todo", Tags: []string{"#project", "#Todo"}},
+		"C.md": {Content: "Untagged", Tags: []string{}},
+	}
+	written := make(map[string]string)
+	// changing is a note whose mtime advances each time it is read
+	var changing string
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		file := strings.TrimPrefix(r.URL.Path, "/vault/")
+		switch {
+		case r.URL.Path == "/vault/":
+			_, _ = w.Write([]byte(`{"files": ["A.md", "B.md", "C.md"]}`))
+		case r.URL.Path == "/search/":
+			w.WriteHeader(http.StatusBadRequest)
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			written[file] = string(body)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			if file == changing {
+				notes[file].Stat.Mtime++
+			}
+			_ = json.NewEncoder(w).Encode(notes[file])
+		}
+	}))
+	defer vault.Close()
+	server := NewMCPServer("test-token", vault.URL)
+
+	call := func(name string, args map[string]any) any {
+		t.Helper()
+		response := server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      name,
+			Method:  "tools/call",
+			Params:  map[string]any{"name": name, "arguments": args},
+		})
+		require.NotNil(t, response)
+		require.Nil(t, response.Error)
+		result := response.Result.(map[string]any)
+		require.NotContains(t, result, "isError")
+		return result["structuredContent"]
+	}
+
+	tree := call("list_tags", nil).(map[string]any)["tags"].([]*tags.Node)
+	require.Len(t, tree, 2)
+	assert.Equal(t, "project", tree[0].Tag)
+	assert.Equal(t, 2, tree[0].Total)
+	assert.Equal(t, 2, tree[1].Count)
+
+	assert.Equal(t, []string{"A.md", "B.md"}, call("find_notes_by_tag", map[string]any{"tag": "#project"}).(map[string]any)["notes"])
+	assert.Equal(t, []string{"B.md"}, call("find_notes_by_tag", map[string]any{"tag": "project", "includeNested": false}).(map[string]any)["notes"])
+
+	preview := call("rename_tag", map[string]any{"tag": "project", "newTag": "work", "dryRun": true}).(*tags.RenameResult)
+	assert.Empty(t, written)
+	assert.True(t, preview.DryRun)
+	assert.Equal(t, []tags.FileChange{
+		{Path: "A.md", Changes: []tags.LineChange{{Line: 2, Old: "tags: [project/alpha]", New: "tags: [work/alpha]"}}},
+		{Path: "B.md", Changes: []tags.LineChange{{Line: 1, Old: "#project and #Todo", New: "#work and #Todo"}}},
+	}, preview.UpdatedFiles)
+
+	call("rename_tag", map[string]any{"tag": "project", "newTag": "work"})
+	assert.Equal(t, map[string]string{
+		"A.md": "---\ntags: [work/alpha]\n---\n#todo",
+		"B.md": "#work and #Todo",
+	}, written)
+
+	clear(written)
+	call("merge_tags", map[string]any{"tags": []any{"todo", "project/alpha"}, "target": "next"})
+	assert.Equal(t, map[string]string{
+		"A.md": "---\ntags: [next]\n---\n#next",
+		"B.md": "#project and #next",
+	}, written)
+
+	// Notes changed since the index read them are reported, not overwritten
+	clear(written)
+	changing = "B.md"
+	result := call("rename_tag", map[string]any{"tag": "project", "newTag": "work"}).(*tags.RenameResult)
+	assert.Equal(t, map[string]string{"A.md": "---\ntags: [work/alpha]\n---\n#todo"}, written)
+	require.Len(t, result.UpdatedFiles, 1)
+	assert.Equal(t, "A.md", result.UpdatedFiles[0].Path)
+	assert.Contains(t, result.Errors["B.md"], "conflict: B.md has changed since it was read")
+}
@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/markdown"
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/tags"
+)
+
+// taskStatuses are the statuses list_tasks can filter by
+var taskStatuses = []string{markdown.TaskOpen, markdown.TaskDone, markdown.TaskInProgress, markdown.TaskCancelled, markdown.TaskOther, "all"}
+
+// vaultTask is a task together with the note it belongs to
+type vaultTask struct {
+	Path string `json:"path"`
+	markdown.Task
+}
+
+// taskFilter selects the tasks returned by list_tasks. Empty fields match
+// every task.
+type taskFilter struct {
+	// status is a task status or "all"
+	status string
+	tag    string
+	folder string
+	// dueBefore and dueAfter are inclusive YYYY-MM-DD bounds. Tasks without a
+	// due date never match them.
+	dueBefore string
+	dueAfter  string
+}
+
+// taskFilterFromParams reads the list_tasks arguments
+func taskFilterFromParams(params map[string]any) (taskFilter, error) {
+	filter := taskFilter{status: markdown.TaskOpen}
+	if status, ok := params["status"].(string); ok && status != "" {
+		if !slices.Contains(taskStatuses, status) {
+			return taskFilter{}, fmt.Errorf("invalid status %q, expected one of %s", status, strings.Join(taskStatuses, ", "))
+		}
+		filter.status = status
+	}
+	filter.tag, _ = params["tag"].(string)
+	filter.folder, _ = params["folder"].(string)
+	filter.folder = strings.Trim(filter.folder, "/")
+
+	for name, value := range map[string]*string{"dueBefore": &filter.dueBefore, "dueAfter": &filter.dueAfter} {
+		*value, _ = params[name].(string)
+		if *value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, *value); err != nil {
+			return taskFilter{}, fmt.Errorf("invalid %s %q, expected YYYY-MM-DD", name, *value)
+		}
+	}
+	return filter, nil
+}
+
+// matches reports whether a task of the note at path passes the filter
+func (f taskFilter) matches(path string, task markdown.Task) bool {
+	if f.status != "all" && task.Status != f.status {
+		return false
+	}
+	if f.folder != "" && !strings.HasPrefix(path, f.folder+"/") {
+		return false
+	}
+	if f.tag != "" && !slices.ContainsFunc(task.Tags, func(tag string) bool { return tags.Matches(tag, f.tag, true) }) {
+		return false
+	}
+	if (f.dueBefore != "" || f.dueAfter != "") && task.Due == "" {
+		return false
+	}
+	if f.dueBefore != "" && task.Due > f.dueBefore {
+		return false
+	}
+	return f.dueAfter == "" || task.Due >= f.dueAfter
+}
+
+// listTasks returns the tasks of every note in the vault that pass the
+// filter, ordered by note and line
+func (s *MCPServer) listTasks(ctx context.Context, filter taskFilter) ([]vaultTask, error) {
+	vault, err := s.index.Refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(vault.Notes))
+	for file := range vault.Notes {
+		files = append(files, file)
+	}
+	slices.Sort(files)
+
+	tasks := []vaultTask{}
+	for _, file := range files {
+		for _, task := range markdown.ParseTasks(vault.Notes[file].Content) {
+			if filter.matches(file, task) {
+				tasks = append(tasks, vaultTask{Path: file, Task: task})
+			}
+		}
+	}
+	return tasks, nil
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/obsidian"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestListTasks tests filtering the tasks of the vault
+func TestListTasks(t *testing.T) {
+	notes := map[string]string{
+		"Inbox.md":        "- [ ] Call Bob #work 📅 2024-05-01\n- [x] Buy milk\n",
+		"Projects/One.md": "- [ ] Draft spec #work/spec [due:: 2024-06-15]\n- [ ] Someday\n",
+	}
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/":
+			_, _ = w.Write([]byte(`{"files": ["Inbox.md", "Projects/"]}`))
+		case "/vault/Projects/":
+			_, _ = w.Write([]byte(`{"files": ["One.md"]}`))
+		case "/search/":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			file := strings.TrimPrefix(r.URL.Path, "/vault/")
+			_ = json.NewEncoder(w).Encode(obsidian.Note{Path: file, Content: notes[file]})
+		}
+	}))
+	defer vault.Close()
+	server := NewMCPServer("test-token", vault.URL)
+
+	list := func(args map[string]any) []vaultTask {
+		t.Helper()
+		response := server.handleRequest(context.Background(), &MCPRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "tools/call",
+			Params:  map[string]any{"name": "list_tasks", "arguments": args},
+		})
+		require.NotNil(t, response)
+		require.Nil(t, response.Error)
+		result := response.Result.(map[string]any)
+		require.NotContains(t, result, "isError")
+		return result["structuredContent"].(map[string]any)["tasks"].([]vaultTask)
+	}
+	texts := func(tasks []vaultTask) []string {
+		var texts []string
+		for _, task := range tasks {
+			texts = append(texts, task.Path+":"+task.Text)
+		}
+		return texts
+	}
+
+	tasks := list(nil)
+	require.Len(t, tasks, 3)
+	assert.Equal(t, "Inbox.md", tasks[0].Path)
+	assert.Equal(t, 1, tasks[0].Line)
+	assert.Equal(t, "2024-05-01", tasks[0].Due)
+
+	assert.Equal(t, []string{"Inbox.md:Buy milk"}, texts(list(map[string]any{"status": "done"})))
+	assert.Len(t, list(map[string]any{"status": "all"}), 4)
+	assert.Equal(t, []string{"Inbox.md:Call Bob #work 📅 2024-05-01", "Projects/One.md:Draft spec #work/spec [due:: 2024-06-15]"}, texts(list(map[string]any{"tag": "#work"})))
+	assert.Equal(t, []string{"Inbox.md:Call Bob #work 📅 2024-05-01"}, texts(list(map[string]any{"dueBefore": "2024-05-31"})))
+	assert.Equal(t, []string{"Projects/One.md:Draft spec #work/spec [due:: 2024-06-15]"}, texts(list(map[string]any{"dueAfter": "2024-05-02"})))
+	assert.Equal(t, []string{"Projects/One.md:Draft spec #work/spec [due:: 2024-06-15]", "Projects/One.md:Someday"}, texts(list(map[string]any{"folder": "Projects/"})))
+
+	response := server.handleRequest(context.Background(), &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      2,
+		Method:  "tools/call",
+		Params:  map[string]any{"name": "list_tasks", "arguments": map[string]any{"status": "finished"}},
+	})
+	assert.Equal(t, true, response.Result.(map[string]any)["isError"])
+}
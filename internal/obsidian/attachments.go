@@ -0,0 +1,123 @@
+package obsidian
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// File is the raw content of a vault file together with its media type
+type File struct {
+	Path string
+	// MimeType is the media type without parameters such as charset
+	MimeType string
+	Data     []byte
+}
+
+// IsBinary reports whether the file cannot be returned as text. The media
+// type decides when it is specific; otherwise the content is checked.
+func (f *File) IsBinary() bool {
+	if isTextMimeType(f.MimeType) {
+		return false
+	}
+	if f.MimeType != "application/octet-stream" {
+		return true
+	}
+	return !utf8.Valid(f.Data) || bytes.IndexByte(f.Data, 0) >= 0
+}
+
+// IsImage reports whether the file is a raster image clients can display
+func (f *File) IsImage() bool {
+	return strings.HasPrefix(f.MimeType, "image/") && f.IsBinary()
+}
+
+// mimeTypeOverrides holds media types for extensions common in vaults that
+// the standard library does not know about
+var mimeTypeOverrides = map[string]string{
+	".md":     "text/markdown",
+	".canvas": "application/json",
+}
+
+// MimeTypeForPath infers the media type of a vault file from its extension,
+// without parameters such as charset
+func MimeTypeForPath(filename string) string {
+	ext := strings.ToLower(path.Ext(filename))
+	if mimeType, ok := mimeTypeOverrides[ext]; ok {
+		return mimeType
+	}
+	if mimeType := mime.TypeByExtension(ext); mimeType != "" {
+		mimeType, _, _ = strings.Cut(mimeType, ";")
+		return mimeType
+	}
+	return "application/octet-stream"
+}
+
+// isTextMimeType reports whether content of the media type is text
+func isTextMimeType(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "text/") ||
+		mimeType == "application/json" ||
+		mimeType == "application/xml" ||
+		mimeType == "image/svg+xml" ||
+		strings.HasSuffix(mimeType, "+json") ||
+		strings.HasSuffix(mimeType, "+xml")
+}
+
+// GetFile gets the raw content of any vault file, including binary
+// attachments such as images and PDFs. The media type comes from the response
+// and falls back to the file extension when the server does not report one.
+func (c *Client) GetFile(ctx context.Context, filename string) (*File, error) {
+	apiPath := "/vault/" + strings.TrimPrefix(filename, "/")
+
+	data, header, err := c.makeRawRequest(ctx, "GET", apiPath, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	mimeType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = MimeTypeForPath(filename)
+	}
+
+	return &File{Path: strings.TrimPrefix(filename, "/"), MimeType: mimeType, Data: data}, nil
+}
+
+// StatFile gets the size and modification time of any vault file from the
+// headers of a HEAD request, without downloading it. Mtime is zero when the
+// server does not report a Last-Modified time and Size is -1 without a
+// Content-Length.
+func (c *Client) StatFile(ctx context.Context, filename string) (*NoteStat, error) {
+	apiPath := "/vault/" + strings.TrimPrefix(filename, "/")
+
+	_, header, err := c.makeRawRequest(ctx, "HEAD", apiPath, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	stat := &NoteStat{Size: -1}
+	if size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil {
+		stat.Size = size
+	}
+	if modified, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		stat.Mtime = modified.UnixMilli()
+	}
+	return stat, nil
+}
+
+// UploadAttachment creates or replaces a vault file with binary content. An
+// empty contentType is derived from the file extension.
+func (c *Client) UploadAttachment(ctx context.Context, filename string, data []byte, contentType string) (string, error) {
+	if contentType == "" {
+		contentType = MimeTypeForPath(filename)
+	}
+
+	if _, err := c.CreateOrUpdateFile(ctx, filename, string(data), contentType); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Successfully uploaded %s (%d bytes, %s)", filename, len(data), contentType), nil
+}
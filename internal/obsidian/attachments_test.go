@@ -0,0 +1,97 @@
+package obsidian
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetFile tests reading files with their media type
+func TestGetFile(t *testing.T) {
+	png := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0x00}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/note.md":
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			_, _ = w.Write([]byte("# Note"))
+		case "/vault/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(png)
+		case "/vault/doc.pdf":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte("%PDF-1.7"))
+		case "/vault/data.unknown":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte{0x00, 0x01})
+		case "/vault/notes.unknown":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte("plain text"))
+		}
+	}))
+	defer server.Close()
+	client := NewClient("test-token", server.URL)
+
+	tests := []struct {
+		filename string
+		mimeType string
+		binary   bool
+		image    bool
+	}{
+		{filename: "note.md", mimeType: "text/markdown"},
+		{filename: "image.png", mimeType: "image/png", binary: true, image: true},
+		{filename: "doc.pdf", mimeType: "application/pdf", binary: true},
+		{filename: "data.unknown", mimeType: "application/octet-stream", binary: true},
+		{filename: "notes.unknown", mimeType: "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			file, err := client.GetFile(context.Background(), "/"+tt.filename)
+			require.NoError(t, err)
+			assert.Equal(t, tt.filename, file.Path)
+			assert.Equal(t, tt.mimeType, file.MimeType)
+			assert.Equal(t, tt.binary, file.IsBinary())
+			assert.Equal(t, tt.image, file.IsImage())
+		})
+	}
+
+	file, err := client.GetFile(context.Background(), "image.png")
+	require.NoError(t, err)
+	assert.Equal(t, png, file.Data)
+}
+
+// TestUploadAttachment tests uploading binary content with its content type
+func TestUploadAttachment(t *testing.T) {
+	data := []byte{0xff, 0xd8, 0xff, 0x00}
+	var contentTypes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, data, body)
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client := NewClient("test-token", server.URL)
+
+	message, err := client.UploadAttachment(context.Background(), "assets/photo.jpg", data, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully uploaded assets/photo.jpg (4 bytes, image/jpeg)", message)
+
+	_, err = client.UploadAttachment(context.Background(), "assets/photo", data, "image/webp")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"image/jpeg", "image/webp"}, contentTypes)
+}
+
+// TestMimeTypeForPath tests media type inference from file extensions
+func TestMimeTypeForPath(t *testing.T) {
+	assert.Equal(t, "text/markdown", MimeTypeForPath("notes/Note.md"))
+	assert.Equal(t, "application/json", MimeTypeForPath("board.canvas"))
+	assert.Equal(t, "image/png", MimeTypeForPath("attachments/shot.PNG"))
+	assert.Equal(t, "application/pdf", MimeTypeForPath("paper.pdf"))
+	assert.Equal(t, "application/octet-stream", MimeTypeForPath("archive.unknownext"))
+}
@@ -8,9 +8,12 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/markdown"
 	"github.com/obsidian-mcp-server/obsidian-mcp-server/pkg/obsidian"
 )
 
@@ -20,10 +23,12 @@ type Client struct {
 	baseURL    string
 	apiToken   string
 	httpClient *http.Client
+	paths      PathRules
+	snapshots  Snapshotter
 }
 
 // NewClient creates a new Obsidian API client
-func NewClient(apiToken, baseURL string) *Client {
+func NewClient(apiToken, baseURL string, opts ...ClientOption) *Client {
 	httpClient := &http.Client{}
 
 	// Create the generated client
@@ -34,21 +39,42 @@ func NewClient(apiToken, baseURL string) *Client {
 			return nil
 		}))
 
-	return &Client{
+	c := &Client{
 		apiClient:  apiClient,
 		baseURL:    strings.TrimSuffix(baseURL, "/"),
 		apiToken:   apiToken,
 		httpClient: httpClient,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // makeRequest makes an HTTP request to the Obsidian API
-func (c *Client) makeRequest(method, path string, headers map[string]string, body io.Reader) ([]byte, error) {
+func (c *Client) makeRequest(ctx context.Context, method, path string, headers map[string]string, body io.Reader) ([]byte, error) {
+	data, _, err := c.makeRawRequest(ctx, method, path, headers, body)
+	return data, err
+}
+
+// makeRawRequest makes an HTTP request to the Obsidian API and also returns
+// the response headers. Requests for paths excluded by the path rules fail
+// with a PathDeniedError.
+func (c *Client) makeRawRequest(ctx context.Context, method, path string, headers map[string]string, body io.Reader) ([]byte, http.Header, error) {
+	if err := c.checkPath(ctx, path); err != nil {
+		return nil, nil, err
+	}
+	return c.send(ctx, method, path, headers, body)
+}
+
+// send makes an HTTP request to the Obsidian API without checking the path
+// rules
+func (c *Client) send(ctx context.Context, method, path string, headers map[string]string, body io.Reader) ([]byte, http.Header, error) {
 	url := c.baseURL + path
 
-	req, err := http.NewRequest(method, url, body)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set authorization header
@@ -61,7 +87,7 @@ func (c *Client) makeRequest(method, path string, headers map[string]string, bod
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil {
@@ -72,104 +98,221 @@ func (c *Client) makeRequest(method, path string, headers map[string]string, bod
 
 	responseBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(responseBody))
+		return nil, nil, newAPIError(resp.StatusCode, responseBody)
 	}
 
-	return responseBody, nil
+	return responseBody, resp.Header, nil
 }
 
 // GetServerInfo gets basic server information
-func (c *Client) GetServerInfo() (string, error) {
-	data, err := c.makeRequest("GET", "/", nil, nil)
+func (c *Client) GetServerInfo(ctx context.Context) (*ServerStatus, error) {
+	data, err := c.makeRequest(ctx, "GET", "/", nil, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	var result map[string]interface{}
+	var result ServerStatus
 	if err := json.Unmarshal(data, &result); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	output, _ := json.MarshalIndent(result, "", "  ")
-	return string(output), nil
+	return &result, nil
 }
 
-// ListVaultFiles lists files in the vault
-func (c *Client) ListVaultFiles(path string) (string, error) {
+// ListVaultFiles lists the entries of a single vault directory. Entries are
+// relative to the directory and sub-directories carry a trailing slash.
+func (c *Client) ListVaultFiles(ctx context.Context, path string) (*FileList, error) {
 	apiPath := "/vault/"
 	if path != "" {
 		apiPath = "/vault/" + strings.Trim(path, "/") + "/"
 	}
 
-	data, err := c.makeRequest("GET", apiPath, nil, nil)
+	data, err := c.makeRequest(ctx, "GET", apiPath, nil, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	var result map[string]interface{}
+	var result FileList
 	if err := json.Unmarshal(data, &result); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
+	if result.Files == nil {
+		result.Files = []string{}
+	}
+	result.Files = c.paths.filterFiles(path, result.Files)
 
-	output, _ := json.MarshalIndent(result, "", "  ")
-	return string(output), nil
+	return &result, nil
 }
 
-// GetFileContent gets the content of a specific file
-func (c *Client) GetFileContent(filename, format string) (string, error) {
-	apiPath := "/vault/" + strings.TrimPrefix(filename, "/")
-	headers := make(map[string]string)
+// ListAllVaultFiles recursively lists every file below a vault directory,
+// returning paths relative to the vault root in sorted order
+func (c *Client) ListAllVaultFiles(ctx context.Context, path string) ([]string, error) {
+	var files []string
+	pending := []string{strings.Trim(path, "/")}
+	listed := 0
+
+	for len(pending) > 0 {
+		dir := pending[0]
+		pending = pending[1:]
+
+		list, err := c.ListVaultFiles(ctx, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range list.Files {
+			full := entry
+			if dir != "" {
+				full = dir + "/" + entry
+			}
+			if strings.HasSuffix(full, "/") {
+				pending = append(pending, strings.TrimSuffix(full, "/"))
+				continue
+			}
+			files = append(files, full)
+		}
 
-	if format == "json" {
-		headers["Accept"] = "application/vnd.olrapi.note+json"
+		listed++
+		ReportProgress(ctx, float64(listed), float64(listed+len(pending)), "Listed /"+dir)
 	}
 
-	data, err := c.makeRequest("GET", apiPath, headers, nil)
+	slices.Sort(files)
+	return files, nil
+}
+
+// ListVaultFilesRecursive lists every file below a vault directory
+func (c *Client) ListVaultFilesRecursive(ctx context.Context, path string) (*FileList, error) {
+	files, err := c.ListAllVaultFiles(ctx, path)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if files == nil {
+		files = []string{}
 	}
 
-	if format == "json" {
-		var result map[string]interface{}
-		if err := json.Unmarshal(data, &result); err != nil {
-			return "", fmt.Errorf("failed to parse JSON response: %w", err)
+	return &FileList{Files: files}, nil
+}
+
+// GetMultipleFiles gets the content of several files at once, as markdown or
+// in the NoteJson representation when format is "json". Files that cannot be
+// read are reported with their error instead of failing the call.
+func (c *Client) GetMultipleFiles(ctx context.Context, filenames []string, format string) ([]FileResult, error) {
+	results := make([]FileResult, 0, len(filenames))
+	for i, filename := range filenames {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result := FileResult{Filename: filename}
+		var err error
+		if format == "json" {
+			result.Note, err = c.GetNote(ctx, filename)
+		} else {
+			var file *File
+			if file, err = c.GetFile(ctx, filename); err == nil {
+				if file.IsBinary() {
+					err = fmt.Errorf("binary file (%s), read it with get_file_content", file.MimeType)
+				} else {
+					result.Content = string(file.Data)
+				}
+			}
+		}
+		if err != nil {
+			result.Error = err.Error()
 		}
-		output, _ := json.MarshalIndent(result, "", "  ")
-		return string(output), nil
+		results = append(results, result)
+
+		ReportProgress(ctx, float64(i+1), float64(len(filenames)), "Read "+filename)
+	}
+
+	return results, nil
+}
+
+// GetFileContent gets the markdown content of a specific file
+func (c *Client) GetFileContent(ctx context.Context, filename string) (string, error) {
+	apiPath := "/vault/" + strings.TrimPrefix(filename, "/")
+
+	data, err := c.makeRequest(ctx, "GET", apiPath, nil, nil)
+	if err != nil {
+		return "", err
 	}
 
 	return string(data), nil
 }
 
-// CreateOrUpdateFile creates or updates a file
-func (c *Client) CreateOrUpdateFile(filename, content, contentType string) (string, error) {
+// GetNote gets a note with its metadata in the NoteJson representation
+func (c *Client) GetNote(ctx context.Context, filename string) (*Note, error) {
+	return c.getNote(ctx, "/vault/"+strings.TrimPrefix(filename, "/"))
+}
+
+// getNote fetches the NoteJson representation of the note at an API path
+func (c *Client) getNote(ctx context.Context, apiPath string) (*Note, error) {
+	headers := map[string]string{
+		"Accept": "application/vnd.olrapi.note+json",
+	}
+
+	data, err := c.makeRequest(ctx, "GET", apiPath, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var note Note
+	if err := json.Unmarshal(data, &note); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	note.Hash = ContentHash(note.Content)
+
+	return &note, nil
+}
+
+// CreateOrUpdateFile creates or updates a file. Like the other methods
+// changing a file, it snapshots the prior content first when the client has
+// a Snapshotter.
+func (c *Client) CreateOrUpdateFile(ctx context.Context, filename, content, contentType string) (string, error) {
 	apiPath := "/vault/" + strings.TrimPrefix(filename, "/")
+	if err := c.checkPrecondition(ctx, apiPath, func(string) (string, bool, error) {
+		return content, true, nil
+	}); err != nil {
+		return "", err
+	}
+	if err := c.snapshot(ctx, filename, "update"); err != nil {
+		return "", err
+	}
 	headers := map[string]string{
 		"Content-Type": contentType,
 	}
 
 	body := strings.NewReader(content)
-	_, err := c.makeRequest("PUT", apiPath, headers, body)
+	_, err := c.makeRequest(ctx, "PUT", apiPath, headers, body)
 	if err != nil {
 		return "", err
 	}
+	recordChange(ctx, filename, content)
 
 	return fmt.Sprintf("Successfully created/updated file: %s", filename), nil
 }
 
 // AppendToFile appends content to a file
-func (c *Client) AppendToFile(filename, content string) (string, error) {
+func (c *Client) AppendToFile(ctx context.Context, filename, content string) (string, error) {
 	apiPath := "/vault/" + strings.TrimPrefix(filename, "/")
+	if err := c.checkPrecondition(ctx, apiPath, func(current string) (string, bool, error) {
+		return current + content, true, nil
+	}); err != nil {
+		return "", err
+	}
+	if err := c.snapshot(ctx, filename, "append"); err != nil {
+		return "", err
+	}
 	headers := map[string]string{
 		"Content-Type": "text/markdown",
 	}
 
 	body := strings.NewReader(content)
-	_, err := c.makeRequest("POST", apiPath, headers, body)
+	_, err := c.makeRequest(ctx, "POST", apiPath, headers, body)
 	if err != nil {
 		return "", err
 	}
@@ -177,19 +320,33 @@ func (c *Client) AppendToFile(filename, content string) (string, error) {
 	return fmt.Sprintf("Successfully appended to file: %s", filename), nil
 }
 
-// PatchFileContent patches content in a file
-func (c *Client) PatchFileContent(filename, operation, targetType, target, content, contentType, delimiter string) (string, error) {
-	apiPath := "/vault/" + strings.TrimPrefix(filename, "/")
-	headers := map[string]string{
+// patchHeaders builds the headers used by the PATCH endpoints
+func patchHeaders(operation, targetType, target, contentType, delimiter string) map[string]string {
+	return map[string]string{
 		"Content-Type":     contentType,
 		"Operation":        operation,
 		"Target-Type":      targetType,
 		"Target":           url.QueryEscape(target),
 		"Target-Delimiter": delimiter,
 	}
+}
+
+// PatchFileContent patches content in a file
+func (c *Client) PatchFileContent(ctx context.Context, filename, operation, targetType, target, content, contentType, delimiter string) (string, error) {
+	apiPath := "/vault/" + strings.TrimPrefix(filename, "/")
+	if err := c.checkPrecondition(ctx, apiPath, func(current string) (string, bool, error) {
+		after, err := markdown.ApplyPatch(current, operation, targetType, target, content, contentType, delimiter)
+		return after, true, err
+	}); err != nil {
+		return "", err
+	}
+	if err := c.snapshot(ctx, filename, "patch"); err != nil {
+		return "", err
+	}
+	headers := patchHeaders(operation, targetType, target, contentType, delimiter)
 
 	body := strings.NewReader(content)
-	_, err := c.makeRequest("PATCH", apiPath, headers, body)
+	_, err := c.makeRequest(ctx, "PATCH", apiPath, headers, body)
 	if err != nil {
 		return "", err
 	}
@@ -198,10 +355,18 @@ func (c *Client) PatchFileContent(filename, operation, targetType, target, conte
 }
 
 // DeleteFile deletes a file
-func (c *Client) DeleteFile(filename string) (string, error) {
+func (c *Client) DeleteFile(ctx context.Context, filename string) (string, error) {
 	apiPath := "/vault/" + strings.TrimPrefix(filename, "/")
+	if err := c.checkPrecondition(ctx, apiPath, func(string) (string, bool, error) {
+		return "", false, nil
+	}); err != nil {
+		return "", err
+	}
+	if err := c.snapshot(ctx, filename, "delete"); err != nil {
+		return "", err
+	}
 
-	_, err := c.makeRequest("DELETE", apiPath, nil, nil)
+	_, err := c.makeRequest(ctx, "DELETE", apiPath, nil, nil)
 	if err != nil {
 		return "", err
 	}
@@ -210,28 +375,29 @@ func (c *Client) DeleteFile(filename string) (string, error) {
 }
 
 // SearchVaultSimple performs a simple text search
-func (c *Client) SearchVaultSimple(query string, contextLength int) (string, error) {
+func (c *Client) SearchVaultSimple(ctx context.Context, query string, contextLength int) ([]SearchResult, error) {
 	apiPath := "/search/simple/?query=" + url.QueryEscape(query)
 	if contextLength > 0 {
 		apiPath += "&contextLength=" + strconv.Itoa(contextLength)
 	}
 
-	data, err := c.makeRequest("POST", apiPath, nil, nil)
+	data, err := c.makeRequest(ctx, "POST", apiPath, nil, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	var results []interface{}
+	results := []SearchResult{}
 	if err := json.Unmarshal(data, &results); err != nil {
-		return "", fmt.Errorf("failed to parse search results: %w", err)
+		return nil, fmt.Errorf("failed to parse search results: %w", err)
 	}
 
-	output, _ := json.MarshalIndent(results, "", "  ")
-	return string(output), nil
+	return slices.DeleteFunc(results, func(result SearchResult) bool {
+		return !c.paths.Allowed(result.Filename)
+	}), nil
 }
 
 // SearchVaultAdvanced performs an advanced search
-func (c *Client) SearchVaultAdvanced(query, queryType string) (string, error) {
+func (c *Client) SearchVaultAdvanced(ctx context.Context, query, queryType string) ([]AdvancedSearchResult, error) {
 	apiPath := "/search/"
 	var contentType string
 	var body io.Reader
@@ -245,52 +411,55 @@ func (c *Client) SearchVaultAdvanced(query, queryType string) (string, error) {
 		// Validate JSON
 		var jsonQuery interface{}
 		if err := json.Unmarshal([]byte(query), &jsonQuery); err != nil {
-			return "", fmt.Errorf("invalid JSON query: %w", err)
+			return nil, fmt.Errorf("invalid JSON query: %w", err)
 		}
 		body = strings.NewReader(query)
 	default:
-		return "", fmt.Errorf("unsupported query type: %s", queryType)
+		return nil, fmt.Errorf("unsupported query type: %s", queryType)
 	}
 
 	headers := map[string]string{
 		"Content-Type": contentType,
 	}
 
-	data, err := c.makeRequest("POST", apiPath, headers, body)
+	data, err := c.makeRequest(ctx, "POST", apiPath, headers, body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	var results []interface{}
+	results := []AdvancedSearchResult{}
 	if err := json.Unmarshal(data, &results); err != nil {
-		return "", fmt.Errorf("failed to parse search results: %w", err)
+		return nil, fmt.Errorf("failed to parse search results: %w", err)
 	}
 
-	output, _ := json.MarshalIndent(results, "", "  ")
-	return string(output), nil
+	return slices.DeleteFunc(results, func(result AdvancedSearchResult) bool {
+		return !c.paths.Allowed(result.Filename)
+	}), nil
 }
 
 // ListCommands gets available Obsidian commands
-func (c *Client) ListCommands() (string, error) {
-	data, err := c.makeRequest("GET", "/commands/", nil, nil)
+func (c *Client) ListCommands(ctx context.Context) (*CommandList, error) {
+	data, err := c.makeRequest(ctx, "GET", "/commands/", nil, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	var result map[string]interface{}
+	var result CommandList
 	if err := json.Unmarshal(data, &result); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if result.Commands == nil {
+		result.Commands = []Command{}
 	}
 
-	output, _ := json.MarshalIndent(result, "", "  ")
-	return string(output), nil
+	return &result, nil
 }
 
 // ExecuteCommand executes a specific command
-func (c *Client) ExecuteCommand(commandId string) (string, error) {
+func (c *Client) ExecuteCommand(ctx context.Context, commandId string) (string, error) {
 	apiPath := "/commands/" + url.PathEscape(commandId) + "/"
 
-	_, err := c.makeRequest("POST", apiPath, nil, nil)
+	_, err := c.makeRequest(ctx, "POST", apiPath, nil, nil)
 	if err != nil {
 		return "", err
 	}
@@ -299,16 +468,286 @@ func (c *Client) ExecuteCommand(commandId string) (string, error) {
 }
 
 // OpenFile opens a file in Obsidian
-func (c *Client) OpenFile(filename string, newLeaf bool) (string, error) {
+func (c *Client) OpenFile(ctx context.Context, filename string, newLeaf bool) (string, error) {
 	apiPath := "/open/" + strings.TrimPrefix(filename, "/")
 	if newLeaf {
 		apiPath += "?newLeaf=true"
 	}
 
-	_, err := c.makeRequest("POST", apiPath, nil, nil)
+	_, err := c.makeRequest(ctx, "POST", apiPath, nil, nil)
 	if err != nil {
 		return "", err
 	}
 
 	return fmt.Sprintf("Successfully opened file: %s", filename), nil
 }
+
+// periodicPeriods lists the periods supported by the periodic notes endpoints
+var periodicPeriods = []string{"daily", "weekly", "monthly", "quarterly", "yearly"}
+
+// periodicPath builds the API path for a periodic note. An empty date targets
+// the current period, otherwise date must be formatted as YYYY-MM-DD.
+func periodicPath(period, date string) (string, error) {
+	period = periodOrDefault(period)
+	if !slices.Contains(periodicPeriods, period) {
+		return "", fmt.Errorf("unsupported period: %s", period)
+	}
+
+	if date == "" {
+		return "/periodic/" + period + "/", nil
+	}
+
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q, expected YYYY-MM-DD: %w", date, err)
+	}
+
+	return fmt.Sprintf("/periodic/%s/%d/%d/%d/", period, t.Year(), int(t.Month()), t.Day()), nil
+}
+
+// periodOrDefault returns the period, or daily when none is given
+func periodOrDefault(period string) string {
+	if period == "" {
+		return "daily"
+	}
+	return period
+}
+
+// periodicLabel describes a periodic note for result messages
+func periodicLabel(period, date string) string {
+	period = periodOrDefault(period)
+	if date == "" {
+		return "current " + period + " note"
+	}
+	return period + " note for " + date
+}
+
+// GetPeriodicNote gets the markdown content of a periodic note
+func (c *Client) GetPeriodicNote(ctx context.Context, period, date string) (string, error) {
+	apiPath, err := periodicPath(period, date)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := c.makeRequest(ctx, "GET", apiPath, nil, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// GetPeriodicNoteJSON gets a periodic note in the NoteJson representation
+func (c *Client) GetPeriodicNoteJSON(ctx context.Context, period, date string) (*Note, error) {
+	apiPath, err := periodicPath(period, date)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.getNote(ctx, apiPath)
+}
+
+// UpdatePeriodicNote replaces the content of a periodic note
+func (c *Client) UpdatePeriodicNote(ctx context.Context, period, date, content, contentType string) (string, error) {
+	apiPath, err := periodicPath(period, date)
+	if err != nil {
+		return "", err
+	}
+	if err := c.checkPrecondition(ctx, apiPath, func(string) (string, bool, error) {
+		return content, true, nil
+	}); err != nil {
+		return "", err
+	}
+	if err := c.snapshotResolved(ctx, apiPath, "update"); err != nil {
+		return "", err
+	}
+	headers := map[string]string{
+		"Content-Type": contentType,
+	}
+
+	body := strings.NewReader(content)
+	_, err = c.makeRequest(ctx, "PUT", apiPath, headers, body)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Successfully updated %s", periodicLabel(period, date)), nil
+}
+
+// AppendToPeriodicNote appends content to a periodic note, creating it if necessary
+func (c *Client) AppendToPeriodicNote(ctx context.Context, period, date, content string) (string, error) {
+	apiPath, err := periodicPath(period, date)
+	if err != nil {
+		return "", err
+	}
+	if err := c.checkPrecondition(ctx, apiPath, func(current string) (string, bool, error) {
+		return current + content, true, nil
+	}); err != nil {
+		return "", err
+	}
+	if err := c.snapshotResolved(ctx, apiPath, "append"); err != nil {
+		return "", err
+	}
+	headers := map[string]string{
+		"Content-Type": "text/markdown",
+	}
+
+	body := strings.NewReader(content)
+	_, err = c.makeRequest(ctx, "POST", apiPath, headers, body)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Successfully appended to %s", periodicLabel(period, date)), nil
+}
+
+// PatchPeriodicNote patches content in a periodic note
+func (c *Client) PatchPeriodicNote(ctx context.Context, period, date, operation, targetType, target, content, contentType, delimiter string) (string, error) {
+	apiPath, err := periodicPath(period, date)
+	if err != nil {
+		return "", err
+	}
+	if err := c.checkPrecondition(ctx, apiPath, func(current string) (string, bool, error) {
+		after, err := markdown.ApplyPatch(current, operation, targetType, target, content, contentType, delimiter)
+		return after, true, err
+	}); err != nil {
+		return "", err
+	}
+	if err := c.snapshotResolved(ctx, apiPath, "patch"); err != nil {
+		return "", err
+	}
+	headers := patchHeaders(operation, targetType, target, contentType, delimiter)
+
+	body := strings.NewReader(content)
+	_, err = c.makeRequest(ctx, "PATCH", apiPath, headers, body)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Successfully patched %s (operation: %s, target: %s)", periodicLabel(period, date), operation, target), nil
+}
+
+// DeletePeriodicNote deletes a periodic note
+func (c *Client) DeletePeriodicNote(ctx context.Context, period, date string) (string, error) {
+	apiPath, err := periodicPath(period, date)
+	if err != nil {
+		return "", err
+	}
+	if err := c.checkPrecondition(ctx, apiPath, func(string) (string, bool, error) {
+		return "", false, nil
+	}); err != nil {
+		return "", err
+	}
+	if err := c.snapshotResolved(ctx, apiPath, "delete"); err != nil {
+		return "", err
+	}
+
+	_, err = c.makeRequest(ctx, "DELETE", apiPath, nil, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Successfully deleted %s", periodicLabel(period, date)), nil
+}
+
+// GetActiveFile gets the markdown content of the file currently open in Obsidian
+func (c *Client) GetActiveFile(ctx context.Context) (string, error) {
+	data, err := c.makeRequest(ctx, "GET", "/active/", nil, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// GetActiveFileJSON gets the file currently open in Obsidian in the NoteJson
+// representation
+func (c *Client) GetActiveFileJSON(ctx context.Context) (*Note, error) {
+	return c.getNote(ctx, "/active/")
+}
+
+// UpdateActiveFile replaces the content of the file currently open in Obsidian
+func (c *Client) UpdateActiveFile(ctx context.Context, content, contentType string) (string, error) {
+	if err := c.checkPrecondition(ctx, "/active/", func(string) (string, bool, error) {
+		return content, true, nil
+	}); err != nil {
+		return "", err
+	}
+	if err := c.snapshotResolved(ctx, "/active/", "update"); err != nil {
+		return "", err
+	}
+	headers := map[string]string{
+		"Content-Type": contentType,
+	}
+
+	body := strings.NewReader(content)
+	_, err := c.makeRequest(ctx, "PUT", "/active/", headers, body)
+	if err != nil {
+		return "", err
+	}
+
+	return "Successfully updated active file", nil
+}
+
+// AppendToActiveFile appends content to the file currently open in Obsidian
+func (c *Client) AppendToActiveFile(ctx context.Context, content string) (string, error) {
+	if err := c.checkPrecondition(ctx, "/active/", func(current string) (string, bool, error) {
+		return current + content, true, nil
+	}); err != nil {
+		return "", err
+	}
+	if err := c.snapshotResolved(ctx, "/active/", "append"); err != nil {
+		return "", err
+	}
+	headers := map[string]string{
+		"Content-Type": "text/markdown",
+	}
+
+	body := strings.NewReader(content)
+	_, err := c.makeRequest(ctx, "POST", "/active/", headers, body)
+	if err != nil {
+		return "", err
+	}
+
+	return "Successfully appended to active file", nil
+}
+
+// PatchActiveFile patches content in the file currently open in Obsidian
+func (c *Client) PatchActiveFile(ctx context.Context, operation, targetType, target, content, contentType, delimiter string) (string, error) {
+	if err := c.checkPrecondition(ctx, "/active/", func(current string) (string, bool, error) {
+		after, err := markdown.ApplyPatch(current, operation, targetType, target, content, contentType, delimiter)
+		return after, true, err
+	}); err != nil {
+		return "", err
+	}
+	if err := c.snapshotResolved(ctx, "/active/", "patch"); err != nil {
+		return "", err
+	}
+	headers := patchHeaders(operation, targetType, target, contentType, delimiter)
+
+	body := strings.NewReader(content)
+	_, err := c.makeRequest(ctx, "PATCH", "/active/", headers, body)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Successfully patched active file (operation: %s, target: %s)", operation, target), nil
+}
+
+// DeleteActiveFile deletes the file currently open in Obsidian
+func (c *Client) DeleteActiveFile(ctx context.Context) (string, error) {
+	if err := c.checkPrecondition(ctx, "/active/", func(string) (string, bool, error) {
+		return "", false, nil
+	}); err != nil {
+		return "", err
+	}
+	if err := c.snapshotResolved(ctx, "/active/", "delete"); err != nil {
+		return "", err
+	}
+	_, err := c.makeRequest(ctx, "DELETE", "/active/", nil, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return "Successfully deleted active file", nil
+}
@@ -1,6 +1,7 @@
 package obsidian
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -51,7 +52,7 @@ func TestMakeRequest(t *testing.T) {
 		"Custom-Header": "test-value",
 	}
 
-	data, err := client.makeRequest("GET", "/test", headers, nil)
+	data, err := client.makeRequest(context.Background(), "GET", "/test", headers, nil)
 	require.NoError(t, err)
 	assert.Equal(t, `{"status": "ok"}`, string(data))
 }
@@ -68,7 +69,7 @@ func TestMakeRequestErrorHandling(t *testing.T) {
 
 	client := NewClient("test-token", server.URL)
 
-	_, err := client.makeRequest("GET", "/nonexistent", nil, nil)
+	_, err := client.makeRequest(context.Background(), "GET", "/nonexistent", nil, nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "API error (status 404)")
 }
@@ -95,10 +96,11 @@ func TestGetServerInfo(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.GetServerInfo()
+	result, err := client.GetServerInfo(context.Background())
 	require.NoError(t, err)
-	assert.Contains(t, result, "Obsidian Local REST API")
-	assert.Contains(t, result, "authenticated")
+	assert.Equal(t, "Obsidian Local REST API", result.Service)
+	assert.True(t, result.Authenticated)
+	assert.Equal(t, "3.0.0", result.Versions.Self)
 }
 
 // TestListVaultFiles tests the ListVaultFiles method
@@ -122,10 +124,9 @@ func TestListVaultFiles(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.ListVaultFiles("")
+	result, err := client.ListVaultFiles(context.Background(), "")
 	require.NoError(t, err)
-	assert.Contains(t, result, "note1.md")
-	assert.Contains(t, result, "note2.md")
+	assert.Equal(t, []string{"note1.md", "note2.md", "subfolder/"}, result.Files)
 }
 
 // TestListVaultFilesWithPath tests listing files in a subdirectory
@@ -147,9 +148,9 @@ func TestListVaultFilesWithPath(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.ListVaultFiles("subfolder")
+	result, err := client.ListVaultFiles(context.Background(), "subfolder")
 	require.NoError(t, err)
-	assert.Contains(t, result, "subnote1.md")
+	assert.Contains(t, result.Files, "subnote1.md")
 }
 
 // TestGetFileContent tests getting file content in markdown format
@@ -167,13 +168,13 @@ func TestGetFileContent(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.GetFileContent("test.md", "markdown")
+	result, err := client.GetFileContent(context.Background(), "test.md")
 	require.NoError(t, err)
 	assert.Equal(t, "# Test Note\n\nThis is test content.", result)
 }
 
-// TestGetFileContentJSON tests getting file content in JSON format
-func TestGetFileContentJSON(t *testing.T) {
+// TestGetNoteFull tests getting a note with all NoteJson fields
+func TestGetNoteFull(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/vault/test.md", r.URL.Path)
 		assert.Equal(t, "GET", r.Method)
@@ -197,11 +198,12 @@ func TestGetFileContentJSON(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.GetFileContent("test.md", "json")
+	result, err := client.GetNote(context.Background(), "test.md")
 	require.NoError(t, err)
-	assert.Contains(t, result, "Test Note")
-	assert.Contains(t, result, "tags")
-	assert.Contains(t, result, "frontmatter")
+	assert.Equal(t, "# Test Note\n\nThis is test content.", result.Content)
+	assert.Equal(t, []string{"test"}, result.Tags)
+	assert.Empty(t, result.Frontmatter)
+	assert.Equal(t, int64(1024), result.Stat.Size)
 }
 
 // TestCreateOrUpdateFile tests creating/updating a file
@@ -216,7 +218,7 @@ func TestCreateOrUpdateFile(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.CreateOrUpdateFile("test.md", "# New Note", "text/markdown")
+	result, err := client.CreateOrUpdateFile(context.Background(), "test.md", "# New Note", "text/markdown")
 	require.NoError(t, err)
 	assert.Contains(t, result, "Successfully created/updated file: test.md")
 }
@@ -233,7 +235,7 @@ func TestAppendToFile(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.AppendToFile("test.md", "\n\nAppended content")
+	result, err := client.AppendToFile(context.Background(), "test.md", "\n\nAppended content")
 	require.NoError(t, err)
 	assert.Contains(t, result, "Successfully appended to file: test.md")
 }
@@ -255,7 +257,7 @@ func TestPatchFileContent(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.PatchFileContent("test.md", "append", "heading", "Test Heading", "New content", "text/markdown", "::")
+	result, err := client.PatchFileContent(context.Background(), "test.md", "append", "heading", "Test Heading", "New content", "text/markdown", "::")
 	require.NoError(t, err)
 	assert.Contains(t, result, "Successfully patched file: test.md")
 }
@@ -271,7 +273,7 @@ func TestDeleteFile(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.DeleteFile("test.md")
+	result, err := client.DeleteFile(context.Background(), "test.md")
 	require.NoError(t, err)
 	assert.Contains(t, result, "Successfully deleted file: test.md")
 }
@@ -303,10 +305,14 @@ func TestSearchVaultSimple(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.SearchVaultSimple("test", 50)
+	result, err := client.SearchVaultSimple(context.Background(), "test", 50)
 	require.NoError(t, err)
-	assert.Contains(t, result, "note1.md")
-	assert.Contains(t, result, "test content")
+	require.Len(t, result, 1)
+	assert.Equal(t, "note1.md", result[0].Filename)
+	assert.Equal(t, 0.95, result[0].Score)
+	require.Len(t, result[0].Matches, 1)
+	assert.Contains(t, result[0].Matches[0].Context, "test content")
+	assert.Equal(t, SearchSpan{Start: 10, End: 14}, result[0].Matches[0].Match)
 }
 
 // TestSearchVaultAdvanced tests advanced vault search with Dataview
@@ -329,10 +335,11 @@ func TestSearchVaultAdvanced(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.SearchVaultAdvanced("TABLE field FROM #tag", "dataview")
+	result, err := client.SearchVaultAdvanced(context.Background(), "TABLE field FROM #tag", "dataview")
 	require.NoError(t, err)
-	assert.Contains(t, result, "note1.md")
-	assert.Contains(t, result, "value1")
+	require.Len(t, result, 1)
+	assert.Equal(t, "note1.md", result[0].Filename)
+	assert.Equal(t, []any{"value1", "value2"}, result[0].Result)
 }
 
 // TestListCommands tests listing available commands
@@ -360,10 +367,11 @@ func TestListCommands(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.ListCommands()
+	result, err := client.ListCommands(context.Background())
 	require.NoError(t, err)
-	assert.Contains(t, result, "global-search:open")
-	assert.Contains(t, result, "Graph view")
+	require.Len(t, result.Commands, 2)
+	assert.Equal(t, "global-search:open", result.Commands[0].ID)
+	assert.Equal(t, "Graph view: Open graph view", result.Commands[1].Name)
 }
 
 // TestExecuteCommand tests executing a command
@@ -379,7 +387,7 @@ func TestExecuteCommand(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.ExecuteCommand("global-search:open")
+	result, err := client.ExecuteCommand(context.Background(), "global-search:open")
 	require.NoError(t, err)
 	assert.Contains(t, result, "Successfully executed command: global-search:open")
 }
@@ -396,7 +404,358 @@ func TestOpenFile(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient("test-token", server.URL)
-	result, err := client.OpenFile("test.md", true)
+	result, err := client.OpenFile(context.Background(), "test.md", true)
 	require.NoError(t, err)
 	assert.Contains(t, result, "Successfully opened file: test.md")
 }
+
+// TestGetPeriodicNote tests getting the current periodic note
+func TestGetPeriodicNote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/periodic/daily/", r.URL.Path)
+		assert.Equal(t, "GET", r.Method)
+
+		w.Header().Set("Content-Type", "text/markdown")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte("# Today\n\n- Standup"))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	result, err := client.GetPeriodicNote(context.Background(), "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "# Today\n\n- Standup", result)
+}
+
+// TestGetPeriodicNoteWithDate tests getting a periodic note for a specific date
+func TestGetPeriodicNoteWithDate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/periodic/weekly/2024/3/7/", r.URL.Path)
+		assert.Equal(t, "application/vnd.olrapi.note+json", r.Header.Get("Accept"))
+
+		w.Header().Set("Content-Type", "application/vnd.olrapi.note+json")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"content": "# Week 10", "path": "2024-W10.md", "tags": [], "frontmatter": {}}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	result, err := client.GetPeriodicNoteJSON(context.Background(), "weekly", "2024-03-07")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-W10.md", result.Path)
+}
+
+// TestPeriodicNoteInvalidArguments tests validation of period and date
+func TestPeriodicNoteInvalidArguments(t *testing.T) {
+	client := NewClient("test-token", "http://localhost:27123")
+
+	_, err := client.GetPeriodicNote(context.Background(), "hourly", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported period: hourly")
+
+	_, err = client.DeletePeriodicNote(context.Background(), "daily", "03/07/2024")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected YYYY-MM-DD")
+}
+
+// TestAppendToPeriodicNote tests appending to a periodic note
+func TestAppendToPeriodicNote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/periodic/daily/", r.URL.Path)
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "text/markdown", r.Header.Get("Content-Type"))
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	result, err := client.AppendToPeriodicNote(context.Background(), "daily", "", "- Lunch")
+	require.NoError(t, err)
+	assert.Contains(t, result, "Successfully appended to current daily note")
+
+	// The period defaults to daily in messages too
+	result, err = client.AppendToPeriodicNote(context.Background(), "", "", "- Dinner")
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully appended to current daily note", result)
+}
+
+// TestPatchPeriodicNote tests patching a periodic note
+func TestPatchPeriodicNote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/periodic/daily/2024/1/15/", r.URL.Path)
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "append", r.Header.Get("Operation"))
+		assert.Equal(t, "heading", r.Header.Get("Target-Type"))
+		assert.Equal(t, "Log", r.Header.Get("Target"))
+		assert.Equal(t, "::", r.Header.Get("Target-Delimiter"))
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	result, err := client.PatchPeriodicNote(context.Background(), "daily", "2024-01-15", "append", "heading", "Log", "- Done", "text/markdown", "::")
+	require.NoError(t, err)
+	assert.Contains(t, result, "Successfully patched daily note for 2024-01-15")
+}
+
+// TestDeletePeriodicNote tests deleting a periodic note
+func TestDeletePeriodicNote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/periodic/monthly/", r.URL.Path)
+		assert.Equal(t, "DELETE", r.Method)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	result, err := client.DeletePeriodicNote(context.Background(), "monthly", "")
+	require.NoError(t, err)
+	assert.Contains(t, result, "Successfully deleted current monthly note")
+}
+
+// TestGetActiveFileJSON tests getting the active file in JSON format
+func TestGetActiveFileJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/active/", r.URL.Path)
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "application/vnd.olrapi.note+json", r.Header.Get("Accept"))
+
+		w.Header().Set("Content-Type", "application/vnd.olrapi.note+json")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"content": "# Focused", "path": "focused.md", "tags": [], "frontmatter": {}}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	result, err := client.GetActiveFileJSON(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "focused.md", result.Path)
+}
+
+// TestUpdateActiveFile tests replacing the active file
+func TestUpdateActiveFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/active/", r.URL.Path)
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "text/markdown", r.Header.Get("Content-Type"))
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	result, err := client.UpdateActiveFile(context.Background(), "# Replaced", "text/markdown")
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully updated active file", result)
+}
+
+// TestPatchActiveFile tests patching the active file
+func TestPatchActiveFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/active/", r.URL.Path)
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "replace", r.Header.Get("Operation"))
+		assert.Equal(t, "frontmatter", r.Header.Get("Target-Type"))
+		assert.Equal(t, "status", r.Header.Get("Target"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	result, err := client.PatchActiveFile(context.Background(), "replace", "frontmatter", "status", `"done"`, "application/json", "::")
+	require.NoError(t, err)
+	assert.Contains(t, result, "Successfully patched active file")
+}
+
+// TestDeleteActiveFile tests deleting the active file
+func TestDeleteActiveFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/active/", r.URL.Path)
+		assert.Equal(t, "DELETE", r.Method)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	result, err := client.DeleteActiveFile(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully deleted active file", result)
+}
+
+// TestListAllVaultFiles tests recursively listing the vault
+func TestListAllVaultFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vault/":
+			_, _ = w.Write([]byte(`{"files": ["b.md", "folder/"]}`))
+		case "/vault/folder/":
+			_, _ = w.Write([]byte(`{"files": ["a.md", "nested/"]}`))
+		case "/vault/folder/nested/":
+			_, _ = w.Write([]byte(`{"files": ["c.png"]}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	files, err := client.ListAllVaultFiles(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.md", "folder/a.md", "folder/nested/c.png"}, files)
+}
+
+// TestGetNote tests getting a typed note with metadata
+func TestGetNote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/vault/test.md", r.URL.Path)
+		assert.Equal(t, "application/vnd.olrapi.note+json", r.Header.Get("Accept"))
+
+		w.Header().Set("Content-Type", "application/vnd.olrapi.note+json")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{
+			"content": "# Test Note",
+			"path": "test.md",
+			"tags": ["test"],
+			"frontmatter": {"status": "draft"},
+			"stat": {"ctime": 1700000000123, "mtime": 1700000000456, "size": 11}
+		}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	note, err := client.GetNote(context.Background(), "test.md")
+	require.NoError(t, err)
+	assert.Equal(t, "test.md", note.Path)
+	assert.Equal(t, []string{"test"}, note.Tags)
+	assert.Equal(t, "draft", note.Frontmatter["status"])
+	assert.Equal(t, int64(1700000000456), note.Stat.Mtime)
+}
+
+// TestMakeRequestCancelled tests that a cancelled context aborts the request
+func TestMakeRequestCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go cancel()
+
+	client := NewClient("test-token", server.URL)
+	_, err := client.GetServerInfo(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestGetMultipleFiles tests reading several files with per-file errors and progress
+func TestGetMultipleFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/vault/missing.md" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errorCode": 40400, "message": "File not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte("# " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	var reports []float64
+	ctx := WithProgress(context.Background(), func(progress, total float64, message string) {
+		assert.Equal(t, float64(2), total)
+		reports = append(reports, progress)
+	})
+
+	client := NewClient("test-token", server.URL)
+	files, err := client.GetMultipleFiles(ctx, []string{"a.md", "missing.md"}, "markdown")
+	require.NoError(t, err)
+
+	require.Len(t, files, 2)
+	assert.Equal(t, FileResult{Filename: "a.md", Content: "# /vault/a.md"}, files[0])
+	assert.Equal(t, "missing.md", files[1].Filename)
+	assert.Contains(t, files[1].Error, "404")
+	assert.Equal(t, []float64{1, 2}, reports)
+}
+
+// TestListVaultFilesRecursive tests listing a directory tree as JSON
+func TestListVaultFilesRecursive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vault/":
+			_, _ = w.Write([]byte(`{"files": ["a.md", "folder/"]}`))
+		case "/vault/folder/":
+			_, _ = w.Write([]byte(`{"files": ["b.md"]}`))
+		}
+	}))
+	defer server.Close()
+
+	var reports int
+	ctx := WithProgress(context.Background(), func(progress, total float64, message string) {
+		reports++
+	})
+
+	client := NewClient("test-token", server.URL)
+	result, err := client.ListVaultFilesRecursive(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.md", "folder/b.md"}, result.Files)
+	assert.Equal(t, 2, reports)
+}
+
+// TestAPIError tests decoding error responses into APIError
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected APIError
+	}{
+		{
+			name:     "error schema",
+			status:   http.StatusNotFound,
+			body:     `{"errorCode": 40400, "message": "File not found"}`,
+			expected: APIError{StatusCode: 404, ErrorCode: 40400, Message: "File not found"},
+		},
+		{
+			name:     "plain text",
+			status:   http.StatusBadRequest,
+			body:     "bad target",
+			expected: APIError{StatusCode: 400, Message: "bad target"},
+		},
+		{
+			name:     "empty body",
+			status:   http.StatusInternalServerError,
+			expected: APIError{StatusCode: 500, Message: "Internal Server Error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient("test-token", server.URL)
+			_, err := client.GetFileContent(context.Background(), "note.md")
+			require.Error(t, err)
+
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.expected, *apiErr)
+			assert.Equal(t, tt.status == http.StatusNotFound, apiErr.NotFound())
+		})
+	}
+}
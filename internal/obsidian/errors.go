@@ -0,0 +1,56 @@
+package obsidian
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/pkg/obsidian"
+)
+
+// APIError is an error response from the Local REST API
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// ErrorCode is the 5-digit code identifying the type of error, or zero
+	// when the response did not carry one
+	ErrorCode int
+	// Message describes the error
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.ErrorCode != 0 {
+		return fmt.Sprintf("API error (status %d): %s (error code %d)", e.StatusCode, e.Message, e.ErrorCode)
+	}
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// NotFound reports whether the requested file or resource does not exist
+func (e *APIError) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// newAPIError builds an APIError from an error response. Bodies in the
+// Error schema are decoded, anything else is used verbatim as the message.
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Message:    string(body),
+	}
+
+	var payload obsidian.Error
+	if err := json.Unmarshal(body, &payload); err == nil {
+		if payload.Message != nil {
+			apiErr.Message = *payload.Message
+		}
+		if payload.ErrorCode != nil {
+			apiErr.ErrorCode = int(*payload.ErrorCode)
+		}
+	}
+
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(statusCode)
+	}
+	return apiErr
+}
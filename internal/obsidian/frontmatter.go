@@ -0,0 +1,128 @@
+package obsidian
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/markdown"
+)
+
+// GetFrontmatter gets the frontmatter properties of a note as parsed by
+// Obsidian
+func (c *Client) GetFrontmatter(ctx context.Context, filename string) (map[string]any, error) {
+	note, err := c.GetNote(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	if note.Frontmatter == nil {
+		return map[string]any{}, nil
+	}
+	return note.Frontmatter, nil
+}
+
+// SetFrontmatterField sets a frontmatter property of a note to a JSON-typed
+// value. Unlike a frontmatter PATCH, which has Obsidian rewrite the whole
+// block, the order of the other properties and YAML comments are preserved.
+// The note is only written if it has not changed since it was read.
+func (c *Client) SetFrontmatterField(ctx context.Context, filename, field string, value any) (string, error) {
+	if field == "" {
+		return "", fmt.Errorf("field cannot be empty")
+	}
+
+	note, err := c.GetNote(ctx, filename)
+	if err != nil {
+		return "", err
+	}
+	content := note.Content
+	updated, err := markdown.SetFrontmatterField(content, field, value)
+	if err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", filename, err)
+	}
+	if updated == content {
+		return fmt.Sprintf("Property %s already has this value in %s", field, filename), nil
+	}
+
+	if _, err := c.CreateOrUpdateFile(guardRead(ctx, note, filename), filename, updated, "text/markdown"); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Successfully set %s in %s", field, filename), nil
+}
+
+// DeleteFrontmatterField removes a frontmatter property from a note, unless
+// the note changes while it is being updated
+func (c *Client) DeleteFrontmatterField(ctx context.Context, filename, field string) (string, error) {
+	note, err := c.GetNote(ctx, filename)
+	if err != nil {
+		return "", err
+	}
+	content := note.Content
+	updated, deleted, err := markdown.DeleteFrontmatterField(content, field)
+	if err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", filename, err)
+	}
+	if !deleted {
+		return fmt.Sprintf("Property %s is not set in %s", field, filename), nil
+	}
+
+	if _, err := c.CreateOrUpdateFile(guardRead(ctx, note, filename), filename, updated, "text/markdown"); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Successfully deleted %s from %s", field, filename), nil
+}
+
+// BulkUpdateFrontmatter sets a frontmatter property on every note matched by
+// a JsonLogic query. Notes that cannot be updated, including those changed
+// after they were read, are reported in the result instead of failing the
+// call. With dryRun set nothing is written.
+func (c *Client) BulkUpdateFrontmatter(ctx context.Context, query, field string, value any, dryRun bool) (*FrontmatterUpdate, error) {
+	if field == "" {
+		return nil, fmt.Errorf("field cannot be empty")
+	}
+
+	matches, err := c.SearchVaultAdvanced(ctx, query, "jsonlogic")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FrontmatterUpdate{
+		Field:          field,
+		DryRun:         dryRun,
+		UpdatedFiles:   []string{},
+		UnchangedFiles: []string{},
+	}
+	fail := func(filename string, err error) {
+		if result.Errors == nil {
+			result.Errors = make(map[string]string)
+		}
+		result.Errors[filename] = err.Error()
+	}
+
+	for i, match := range matches {
+		ReportProgress(ctx, float64(i+1), float64(len(matches)), "Updating "+match.Filename)
+
+		note, err := c.GetNote(ctx, match.Filename)
+		if err != nil {
+			fail(match.Filename, err)
+			continue
+		}
+		content := note.Content
+		updated, err := markdown.SetFrontmatterField(content, field, value)
+		if err != nil {
+			fail(match.Filename, err)
+			continue
+		}
+		if updated == content {
+			result.UnchangedFiles = append(result.UnchangedFiles, match.Filename)
+			continue
+		}
+		if !dryRun {
+			if _, err := c.CreateOrUpdateFile(guardRead(ctx, note, match.Filename), match.Filename, updated, "text/markdown"); err != nil {
+				fail(match.Filename, err)
+				continue
+			}
+		}
+		result.UpdatedFiles = append(result.UpdatedFiles, match.Filename)
+	}
+
+	return result, nil
+}
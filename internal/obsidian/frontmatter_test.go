@@ -0,0 +1,88 @@
+package obsidian
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSetFrontmatterField tests setting and deleting a property of a note
+func TestSetFrontmatterField(t *testing.T) {
+	vault, client := newFakeVault(t, map[string]string{
+		"Note.md": "---\ntitle: Note # shown in lists\nstatus: draft\n---\nBody",
+	})
+
+	message, err := client.SetFrontmatterField(context.Background(), "Note.md", "status", []any{"done", "reviewed"})
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully set status in Note.md", message)
+	assert.Equal(t, "---\ntitle: Note # shown in lists\nstatus:\n  - done\n  - reviewed\n---\nBody", vault.files["Note.md"])
+
+	message, err = client.DeleteFrontmatterField(context.Background(), "Note.md", "status")
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully deleted status from Note.md", message)
+	assert.Equal(t, "---\ntitle: Note # shown in lists\n---\nBody", vault.files["Note.md"])
+
+	message, err = client.DeleteFrontmatterField(context.Background(), "Note.md", "status")
+	require.NoError(t, err)
+	assert.Equal(t, "Property status is not set in Note.md", message)
+
+	_, err = client.SetFrontmatterField(context.Background(), "Missing.md", "status", "x")
+	assert.Error(t, err)
+}
+
+// TestBulkUpdateFrontmatter tests setting a property on the notes matched by
+// a query
+func TestBulkUpdateFrontmatter(t *testing.T) {
+	vault, client := newFakeVault(t, map[string]string{
+		"A.md":     "---\nproject: alpha\n---\nA",
+		"B.md":     "project: alpha in the body",
+		"C.md":     "---\nproject: alpha\nstatus: done\n---\nC",
+		"D.md":     "---\nproject: beta\n---\nD",
+		"Bad.md":   "---\n- project: alpha\n---\n",
+		"file.txt": "project: alpha",
+	})
+	query := `{"in": ["project: alpha", {"var": "content"}]}`
+
+	result, err := client.BulkUpdateFrontmatter(context.Background(), query, "status", "done", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A.md", "B.md"}, result.UpdatedFiles)
+	assert.Equal(t, []string{"C.md"}, result.UnchangedFiles)
+	assert.Contains(t, result.Errors, "Bad.md")
+	assert.Equal(t, "---\nproject: alpha\n---\nA", vault.files["A.md"])
+
+	result, err = client.BulkUpdateFrontmatter(context.Background(), query, "status", "done", false)
+	require.NoError(t, err)
+	assert.False(t, result.DryRun)
+	assert.Equal(t, "---\nproject: alpha\nstatus: done\n---\nA", vault.files["A.md"])
+	assert.Equal(t, "---\nstatus: done\n---\nproject: alpha in the body", vault.files["B.md"])
+	assert.Equal(t, "---\nproject: beta\n---\nD", vault.files["D.md"])
+}
+
+// TestFrontmatterConcurrentEdit tests that properties are not written over a
+// change made to a note after it was read
+func TestFrontmatterConcurrentEdit(t *testing.T) {
+	vault, client := newFakeVault(t, map[string]string{
+		"A.md": "---\nstatus: draft\n---\nA",
+		"B.md": "---\nstatus: draft\n---\nB",
+	})
+	vault.edited = func(name string) {
+		if name == "A.md" {
+			vault.write(name, vault.files[name]+" edited")
+		}
+	}
+
+	_, err := client.SetFrontmatterField(context.Background(), "A.md", "status", "done")
+	var conflict *ConflictError
+	require.ErrorAs(t, err, &conflict)
+	_, err = client.DeleteFrontmatterField(context.Background(), "A.md", "status")
+	require.ErrorAs(t, err, &conflict)
+
+	result, err := client.BulkUpdateFrontmatter(context.Background(), `{"in": ["status: draft", {"var": "content"}]}`, "status", "done", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"B.md"}, result.UpdatedFiles)
+	assert.Contains(t, result.Errors["A.md"], "conflict: A.md has changed since it was read")
+	assert.Contains(t, vault.files["A.md"], "status: draft")
+	assert.Equal(t, "---\nstatus: done\n---\nB", vault.files["B.md"])
+}
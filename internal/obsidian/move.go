@@ -0,0 +1,205 @@
+package obsidian
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/markdown"
+)
+
+// MoveFile moves or renames a vault file and rewrites the wikilinks, embeds
+// and markdown links pointing at it. The Local REST API has no move endpoint,
+// so the file is copied to the destination, referencing notes are updated and
+// the original is deleted. With dryRun set nothing is written and the result
+// lists the changes that would be made.
+func (c *Client) MoveFile(ctx context.Context, source, destination string, dryRun bool) (*MoveResult, error) {
+	source = strings.TrimPrefix(source, "/")
+	destination = strings.TrimPrefix(destination, "/")
+	if source == destination {
+		return nil, fmt.Errorf("source and destination are the same: %s", source)
+	}
+
+	files, err := c.ListAllVaultFiles(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(files, source) {
+		return nil, fmt.Errorf("file not found: %s", source)
+	}
+	if slices.Contains(files, destination) {
+		return nil, fmt.Errorf("destination already exists: %s", destination)
+	}
+
+	movedFiles := slices.Clone(files)
+	movedFiles[slices.Index(movedFiles, source)] = destination
+	before := markdown.NewLinkResolver(files)
+	after := markdown.NewLinkResolver(movedFiles)
+
+	content, err := c.GetFileContent(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MoveResult{
+		Source:       source,
+		Destination:  destination,
+		DryRun:       dryRun,
+		UpdatedFiles: []LinkUpdate{},
+	}
+
+	// Relative links in the moved note itself may break at its new location
+	if path.Ext(source) == ".md" {
+		var changes []LinkChange
+		content, changes = relinkNote(content, source, destination, source, destination, before, after)
+		if len(changes) > 0 {
+			result.UpdatedFiles = append(result.UpdatedFiles, LinkUpdate{Path: destination, Changes: changes})
+		}
+	}
+
+	candidates, err := c.findReferencingNotes(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := make(map[string]string)
+	for i, file := range candidates {
+		text, err := c.GetFileContent(ctx, file)
+		if err != nil {
+			return nil, err
+		}
+		text, changes := relinkNote(text, file, file, source, destination, before, after)
+		if len(changes) > 0 {
+			updated[file] = text
+			result.UpdatedFiles = append(result.UpdatedFiles, LinkUpdate{Path: file, Changes: changes})
+		}
+		ReportProgress(ctx, float64(i+1), float64(len(candidates)), "Scanned "+file)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if _, err := c.CreateOrUpdateFile(ctx, destination, content, MimeTypeForPath(destination)); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", destination, err)
+	}
+	for _, update := range result.UpdatedFiles {
+		text, ok := updated[update.Path]
+		if !ok {
+			continue
+		}
+		if _, err := c.CreateOrUpdateFile(ctx, update.Path, text, "text/markdown"); err != nil {
+			return nil, fmt.Errorf("moved to %s but failed to update links in %s: %w", destination, update.Path, err)
+		}
+	}
+	if _, err := c.DeleteFile(ctx, source); err != nil {
+		return nil, fmt.Errorf("moved to %s but failed to delete %s: %w", destination, source, err)
+	}
+
+	return result, nil
+}
+
+// findReferencingNotes returns the markdown notes other than the file itself
+// that may reference it. Candidates come from a simple search for the file's
+// name, which every form of link to it contains.
+func (c *Client) findReferencingNotes(ctx context.Context, file string) ([]string, error) {
+	name := strings.TrimSuffix(path.Base(file), path.Ext(file))
+	queries := []string{name}
+	if escaped := strings.ReplaceAll(name, " ", "%20"); escaped != name {
+		queries = append(queries, escaped)
+	}
+
+	var candidates []string
+	for _, query := range queries {
+		results, err := c.SearchVaultSimple(ctx, query, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			if result.Filename != file && path.Ext(result.Filename) == ".md" && !slices.Contains(candidates, result.Filename) {
+				candidates = append(candidates, result.Filename)
+			}
+		}
+	}
+
+	slices.Sort(candidates)
+	return candidates, nil
+}
+
+// relinkNote rewrites the links of a note that point at source so they point
+// at destination, and the relative links of a note that moves from oldPath to
+// newPath so they keep pointing at the same files
+func relinkNote(content, oldPath, newPath, source, destination string, before, after *markdown.LinkResolver) (string, []LinkChange) {
+	var changes []LinkChange
+	updated := markdown.ReplaceLinks(content, func(link markdown.Link) (string, bool) {
+		if link.Path == "" {
+			return "", false
+		}
+		target, ok := before.Resolve(link.Path, oldPath)
+		if !ok {
+			return "", false
+		}
+		if target == source {
+			target = destination
+		}
+		if resolved, ok := after.Resolve(link.Path, newPath); ok && resolved == target {
+			return "", false
+		}
+
+		raw := link.WithPath(linkPathFor(link, newPath, target, after))
+		changes = append(changes, LinkChange{Line: link.Line, Old: link.Raw, New: raw})
+		return raw, true
+	})
+	return updated, changes
+}
+
+// linkPathFor returns the path to write in a link from source to target in
+// the style of the original link. Wikilinks use the bare note name when it is
+// unambiguous, markdown links keep relative paths relative.
+func linkPathFor(link markdown.Link, source, target string, resolver *markdown.LinkResolver) string {
+	if !link.Wikilink {
+		if strings.HasPrefix(link.Path, "./") || strings.HasPrefix(link.Path, "../") {
+			return relativePath(path.Dir(source), target)
+		}
+		return target
+	}
+
+	full := target
+	if path.Ext(target) == ".md" && path.Ext(link.Path) != ".md" {
+		full = strings.TrimSuffix(target, ".md")
+	}
+	if !strings.Contains(link.Path, "/") {
+		if resolved, ok := resolver.Resolve(path.Base(full), source); ok && resolved == target {
+			return path.Base(full)
+		}
+	}
+	return full
+}
+
+// relativePath returns the slash-separated path of target relative to dir
+func relativePath(dir, target string) string {
+	var from []string
+	if dir != "." && dir != "" {
+		from = strings.Split(dir, "/")
+	}
+	to := strings.Split(target, "/")
+
+	common := 0
+	for common < len(from) && common < len(to)-1 && from[common] == to[common] {
+		common++
+	}
+
+	parts := make([]string, 0, len(from)-common+len(to)-common)
+	for range from[common:] {
+		parts = append(parts, "..")
+	}
+	parts = append(parts, to[common:]...)
+
+	rel := strings.Join(parts, "/")
+	if !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel
+}
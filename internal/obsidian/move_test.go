@@ -0,0 +1,236 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/markdown"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault is an in-memory vault served over the Local REST API endpoints
+// used by MoveFile and the frontmatter helpers. JsonLogic searches only
+// support {"in": [text, {"var": "content"}]}.
+type fakeVault struct {
+	mu    sync.Mutex
+	files map[string]string
+	// mtimes holds the modification time of the files written so far
+	mtimes map[string]int64
+	clock  int64
+	// edited, when set, is called after each read of a file to make a
+	// concurrent change
+	edited func(name string)
+}
+
+// write stores a file and advances its modification time
+func (v *fakeVault) write(name, content string) {
+	if v.mtimes == nil {
+		v.mtimes = make(map[string]int64)
+	}
+	v.clock++
+	v.files[name], v.mtimes[name] = content, v.clock
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if r.URL.Path == "/search/simple/" {
+		query := r.URL.Query().Get("query")
+		results := []SearchResult{}
+		for name, content := range v.files {
+			if strings.Contains(content, query) {
+				results = append(results, SearchResult{Filename: name})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(results)
+		return
+	}
+
+	if r.URL.Path == "/search/" {
+		var query struct {
+			In []any `json:"in"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&query)
+		text, _ := query.In[0].(string)
+		results := []AdvancedSearchResult{}
+		for name, content := range v.files {
+			if strings.HasSuffix(name, ".md") && strings.Contains(content, text) {
+				results = append(results, AdvancedSearchResult{Filename: name, Result: true})
+			}
+		}
+		slices.SortFunc(results, func(a, b AdvancedSearchResult) int { return strings.Compare(a.Filename, b.Filename) })
+		_ = json.NewEncoder(w).Encode(results)
+		return
+	}
+
+	name, ok := strings.CutPrefix(r.URL.Path, "/vault/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if name == "" || strings.HasSuffix(name, "/") {
+		entries := []string{}
+		for file := range v.files {
+			rest, ok := strings.CutPrefix(file, name)
+			if !ok {
+				continue
+			}
+			if dir, _, nested := strings.Cut(rest, "/"); nested {
+				rest = dir + "/"
+			}
+			if !slices.Contains(entries, rest) {
+				entries = append(entries, rest)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(FileList{Files: entries})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		content, ok := v.files[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if strings.Contains(r.Header.Get("Accept"), "note+json") {
+			// Files not written since the vault was created have mtime 1
+			_ = json.NewEncoder(w).Encode(Note{Content: content, Path: name, Stat: NoteStat{Mtime: v.mtimes[name] + 1}})
+		} else {
+			_, _ = io.WriteString(w, content)
+		}
+		if v.edited != nil {
+			v.edited(name)
+		}
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		v.write(name, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPost:
+		body, _ := io.ReadAll(r.Body)
+		v.write(name, v.files[name]+string(body))
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPatch:
+		body, _ := io.ReadAll(r.Body)
+		target, _ := url.QueryUnescape(r.Header.Get("Target"))
+		updated, err := markdown.ApplyPatch(v.files[name], r.Header.Get("Operation"), r.Header.Get("Target-Type"),
+			target, string(body), r.Header.Get("Content-Type"), r.Header.Get("Target-Delimiter"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		v.write(name, updated)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		delete(v.files, name)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newFakeVault(t *testing.T, files map[string]string) (*fakeVault, *Client) {
+	t.Helper()
+	vault := &fakeVault{files: files}
+	server := httptest.NewServer(vault)
+	t.Cleanup(server.Close)
+	return vault, NewClient("test-token", server.URL)
+}
+
+// TestMoveFile tests moving a note and rewriting the links pointing at it
+func TestMoveFile(t *testing.T) {
+	vault, client := newFakeVault(t, map[string]string{
+		"Inbox/Idea.md":   "# Idea\nSee [Guide](../Guide.md) and [[Idea#Idea|itself]].",
+		"Guide.md":        "# Guide",
+		"Index.md":        "- [[Idea]]\n- ![[Inbox/Idea#^summary]]\n- [idea](Inbox/Idea.md)\n- `[[Idea]]`",
+		"Daily/Today.md":  "Worked on [[Idea|the idea]].",
+		"Unrelated.md":    "Nothing about ideas here.",
+		"assets/logo.png": "PNG",
+	})
+
+	result, err := client.MoveFile(context.Background(), "Inbox/Idea.md", "Projects/Big Idea.md", false)
+	require.NoError(t, err)
+	assert.Equal(t, "Projects/Big Idea.md", result.Destination)
+	assert.False(t, result.DryRun)
+
+	updated := make([]string, 0, len(result.UpdatedFiles))
+	for _, update := range result.UpdatedFiles {
+		updated = append(updated, update.Path)
+	}
+	assert.Equal(t, []string{"Projects/Big Idea.md", "Daily/Today.md", "Index.md"}, updated)
+
+	assert.NotContains(t, vault.files, "Inbox/Idea.md")
+	assert.Equal(t, "# Idea\nSee [Guide](../Guide.md) and [[Big Idea#Idea|itself]].", vault.files["Projects/Big Idea.md"])
+	assert.Equal(t, "- [[Big Idea]]\n- ![[Projects/Big Idea#^summary]]\n- [idea](Projects/Big%20Idea.md)\n- `[[Idea]]`", vault.files["Index.md"])
+	assert.Equal(t, "Worked on [[Big Idea|the idea]].", vault.files["Daily/Today.md"])
+	assert.Equal(t, "Nothing about ideas here.", vault.files["Unrelated.md"])
+}
+
+// TestMoveFileRelativeLinks tests that relative links in the moved note are kept working
+func TestMoveFileRelativeLinks(t *testing.T) {
+	vault, client := newFakeVault(t, map[string]string{
+		"a/Note.md":    "[Sibling](./Sibling.md)",
+		"a/Sibling.md": "# Sibling",
+	})
+
+	result, err := client.MoveFile(context.Background(), "a/Note.md", "b/c/Note.md", false)
+	require.NoError(t, err)
+	require.Len(t, result.UpdatedFiles, 1)
+	assert.Equal(t, "[Sibling](../../a/Sibling.md)", vault.files["b/c/Note.md"])
+}
+
+// TestMoveFileDryRun tests that a dry run reports changes without writing
+func TestMoveFileDryRun(t *testing.T) {
+	files := map[string]string{
+		"Old.md":   "# Old",
+		"Links.md": "[[Old]] and [[Old]]",
+	}
+	vault, client := newFakeVault(t, files)
+
+	result, err := client.MoveFile(context.Background(), "Old.md", "New.md", true)
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	require.Len(t, result.UpdatedFiles, 1)
+	assert.Equal(t, []LinkChange{
+		{Line: 1, Old: "[[Old]]", New: "[[New]]"},
+		{Line: 1, Old: "[[Old]]", New: "[[New]]"},
+	}, result.UpdatedFiles[0].Changes)
+
+	assert.Equal(t, "[[Old]] and [[Old]]", vault.files["Links.md"])
+	assert.Contains(t, vault.files, "Old.md")
+	assert.NotContains(t, vault.files, "New.md")
+}
+
+// TestMoveFileErrors tests validation of the source and destination
+func TestMoveFileErrors(t *testing.T) {
+	_, client := newFakeVault(t, map[string]string{
+		"A.md": "# A",
+		"B.md": "# B",
+	})
+
+	_, err := client.MoveFile(context.Background(), "Missing.md", "C.md", false)
+	assert.ErrorContains(t, err, "file not found")
+
+	_, err = client.MoveFile(context.Background(), "A.md", "B.md", false)
+	assert.ErrorContains(t, err, "destination already exists")
+
+	_, err = client.MoveFile(context.Background(), "A.md", "/A.md", false)
+	assert.ErrorContains(t, err, "the same")
+}
+
+// TestRelativePath tests computing relative link paths
+func TestRelativePath(t *testing.T) {
+	assert.Equal(t, "./b.md", relativePath("a", "a/b.md"))
+	assert.Equal(t, "../x/y.md", relativePath("a", "x/y.md"))
+	assert.Equal(t, "./a/b.md", relativePath(".", "a/b.md"))
+	assert.Equal(t, "../../c.md", relativePath("a/b", "c.md"))
+}
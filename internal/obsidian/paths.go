@@ -0,0 +1,241 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+)
+
+// PathRules restrict the vault paths the client may read or write. Patterns
+// are slash-separated globs relative to the vault root: "*", "?" and "[...]"
+// match within a path segment and "**" matches any number of segments. A
+// pattern that matches a folder, such as "Private" or "Private/", covers
+// everything inside it. Matching ignores case, as vaults often live on
+// case-insensitive file systems.
+type PathRules struct {
+	// Include lists the only paths accessible when it is not empty
+	Include []string
+	// Exclude lists paths that are never accessible, even when included
+	Exclude []string
+}
+
+// PathDeniedError reports an operation on a path excluded by the PathRules
+type PathDeniedError struct {
+	Path string
+}
+
+func (e *PathDeniedError) Error() string {
+	return fmt.Sprintf("access to %s is denied by the path rules", e.Path)
+}
+
+// ClientOption configures optional Client behavior
+type ClientOption func(*Client)
+
+// WithPathRules restricts the vault paths the client may access
+func WithPathRules(rules PathRules) ClientOption {
+	return func(c *Client) {
+		c.paths = rules
+	}
+}
+
+// Validate checks that every pattern is well formed
+func (r PathRules) Validate() error {
+	for _, pattern := range slices.Concat(r.Include, r.Exclude) {
+		for _, segment := range patternSegments(pattern) {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("bad path pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// empty reports whether the rules allow every path
+func (r PathRules) empty() bool {
+	return len(r.Include) == 0 && len(r.Exclude) == 0
+}
+
+// Allowed reports whether the file at a vault path is accessible
+func (r PathRules) Allowed(name string) bool {
+	segments := pathSegments(name)
+	if matchesAnyPattern(r.Exclude, segments) {
+		return false
+	}
+	return len(r.Include) == 0 || matchesAnyPattern(r.Include, segments)
+}
+
+// dirVisible reports whether a folder may be listed: it is not excluded and
+// may contain included files. The vault root is always visible.
+func (r PathRules) dirVisible(dir string) bool {
+	segments := pathSegments(dir)
+	if len(segments) == 0 {
+		return true
+	}
+	if matchesAnyPattern(r.Exclude, segments) {
+		return false
+	}
+	if len(r.Include) == 0 || matchesAnyPattern(r.Include, segments) {
+		return true
+	}
+	return slices.ContainsFunc(r.Include, func(pattern string) bool {
+		return mayContain(patternSegments(pattern), segments)
+	})
+}
+
+// pathSegments cleans a vault path and splits it into segments, so that
+// paths such as "Public/../Private/a.md" cannot sidestep the rules
+func pathSegments(name string) []string {
+	name = strings.Trim(path.Clean("/"+name), "/")
+	if name == "" {
+		return nil
+	}
+	return strings.Split(name, "/")
+}
+
+// patternSegments splits a pattern into path segments
+func patternSegments(pattern string) []string {
+	return strings.Split(strings.Trim(pattern, "/"), "/")
+}
+
+// matchesAnyPattern reports whether a pattern matches the path or one of the
+// folders containing it
+func matchesAnyPattern(patterns []string, segments []string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		parts := patternSegments(pattern)
+		for i := 1; i <= len(segments); i++ {
+			if matchSegments(parts, segments[:i]) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// matchSegments reports whether pattern segments match path segments
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	return matchSegment(pattern[0], segments[0]) && matchSegments(pattern[1:], segments[1:])
+}
+
+// mayContain reports whether a pattern can match a path below a folder
+func mayContain(pattern, dir []string) bool {
+	for i, segment := range dir {
+		if i >= len(pattern) {
+			return false
+		}
+		if pattern[i] == "**" {
+			return true
+		}
+		if !matchSegment(pattern[i], segment) {
+			return false
+		}
+	}
+	return len(pattern) > len(dir)
+}
+
+// matchSegment reports whether a pattern segment matches a path segment,
+// ignoring case
+func matchSegment(pattern, segment string) bool {
+	matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(segment))
+	return matched
+}
+
+// checkPath refuses requests for API paths excluded by the path rules. The
+// path is decoded the way the HTTP client sends it, so escapes such as %2F
+// or a trailing #fragment cannot sidestep the rules. The active file and
+// periodic notes are resolved to their vault path first.
+func (c *Client) checkPath(ctx context.Context, apiPath string) error {
+	if c.paths.empty() {
+		return nil
+	}
+	u, err := url.Parse(apiPath)
+	if err != nil {
+		return fmt.Errorf("invalid path %q: %w", apiPath, err)
+	}
+	apiPath = u.Path
+
+	var name string
+	switch {
+	case strings.HasPrefix(apiPath, "/vault/"):
+		name = strings.TrimPrefix(apiPath, "/vault/")
+		if strings.HasSuffix(name, "/") || name == "" {
+			if !c.paths.dirVisible(name) {
+				return &PathDeniedError{Path: name}
+			}
+			return nil
+		}
+	case strings.HasPrefix(apiPath, "/open/"):
+		name = strings.TrimPrefix(apiPath, "/open/")
+	case apiPath == "/active/" || strings.HasPrefix(apiPath, "/periodic/"):
+		var err error
+		if name, err = c.resolvePath(ctx, apiPath); err != nil {
+			return err
+		}
+		if name == "" {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	if !c.paths.Allowed(name) {
+		return &PathDeniedError{Path: name}
+	}
+	return nil
+}
+
+// resolvePath returns the vault path of the note at an API path such as
+// /active/, or an empty path when there is no such note yet
+func (c *Client) resolvePath(ctx context.Context, apiPath string) (string, error) {
+	headers := map[string]string{
+		"Accept": "application/vnd.olrapi.note+json",
+	}
+	data, _, err := c.send(ctx, "GET", apiPath, headers, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.NotFound() {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var note Note
+	if err := json.Unmarshal(data, &note); err != nil {
+		return "", fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	return note.Path, nil
+}
+
+// filterFiles drops the entries of a folder listing excluded by the path
+// rules
+func (r PathRules) filterFiles(dir string, entries []string) []string {
+	dir = strings.Trim(dir, "/")
+	return slices.DeleteFunc(entries, func(entry string) bool {
+		full := entry
+		if dir != "" {
+			full = dir + "/" + entry
+		}
+		if strings.HasSuffix(full, "/") {
+			return !r.dirVisible(full)
+		}
+		return !r.Allowed(full)
+	})
+}
@@ -0,0 +1,108 @@
+package obsidian
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPathRulesAllowed tests include and exclude globs
+func TestPathRulesAllowed(t *testing.T) {
+	assert.True(t, PathRules{}.Allowed("Private/a.md"))
+
+	rules := PathRules{Exclude: []string{"Private/", "HR", "**/*.secret.md"}}
+	assert.True(t, rules.Allowed("Notes/a.md"))
+	assert.True(t, rules.Allowed("Notes/Private/a.md"))
+	assert.False(t, rules.Allowed("Private/a.md"))
+	assert.False(t, rules.Allowed("/HR/2024/review.md"))
+	assert.False(t, rules.Allowed("Notes/../Private/a.md"))
+	assert.False(t, rules.Allowed("keys.secret.md"))
+	assert.False(t, rules.Allowed("Notes/deep/keys.secret.md"))
+	assert.False(t, rules.Allowed("private/a.md"))
+	assert.False(t, rules.Allowed("Notes/KEYS.SECRET.MD"))
+
+	rules = PathRules{Include: []string{"Projects/*/notes", "Daily/*.md"}, Exclude: []string{"Projects/Secret"}}
+	assert.True(t, rules.Allowed("Projects/Alpha/notes/a.md"))
+	assert.True(t, rules.Allowed("Daily/2024-01-01.md"))
+	assert.False(t, rules.Allowed("Daily/archive/2023-01-01.md"))
+	assert.False(t, rules.Allowed("Projects/Alpha/plan.md"))
+	assert.False(t, rules.Allowed("Projects/Secret/notes/a.md"))
+
+	assert.True(t, rules.dirVisible(""))
+	assert.True(t, rules.dirVisible("Projects/"))
+	assert.True(t, rules.dirVisible("Projects/Alpha/"))
+	assert.True(t, rules.dirVisible("Projects/Alpha/notes/sub/"))
+	assert.False(t, rules.dirVisible("Projects/Secret/"))
+	assert.False(t, rules.dirVisible("Other/"))
+
+	assert.EqualError(t, PathRules{Exclude: []string{"Private/["}}.Validate(), `bad path pattern "Private/[": syntax error in pattern`)
+}
+
+// TestClientPathRules tests that excluded paths are neither requested nor
+// listed nor returned from searches
+func TestClientPathRules(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vault/":
+			_, _ = w.Write([]byte(`{"files": ["Private/", "HR/", "Notes/", "todo.md"]}`))
+		case "/search/simple/":
+			_, _ = w.Write([]byte(`[{"filename": "Private/diary.md", "score": 1, "matches": []}, {"filename": "todo.md", "score": 1, "matches": []}]`))
+		case "/search/":
+			_, _ = w.Write([]byte(`[{"filename": "HR/salaries.md", "result": true}, {"filename": "Notes/a.md", "result": true}]`))
+		case "/active/":
+			_, _ = w.Write([]byte(`{"path": "Private/diary.md", "content": "secret"}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL, WithPathRules(PathRules{Exclude: []string{"Private/", "HR/", "**/*.secret.md"}}))
+	ctx := context.Background()
+
+	list, err := client.ListVaultFiles(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Notes/", "todo.md"}, list.Files)
+
+	simple, err := client.SearchVaultSimple(ctx, "salary", 0)
+	require.NoError(t, err)
+	require.Len(t, simple, 1)
+	assert.Equal(t, "todo.md", simple[0].Filename)
+
+	advanced, err := client.SearchVaultAdvanced(ctx, `{"glob": ["*", {"var": "path"}]}`, "jsonlogic")
+	require.NoError(t, err)
+	require.Len(t, advanced, 1)
+	assert.Equal(t, "Notes/a.md", advanced[0].Filename)
+
+	requested = nil
+	_, err = client.GetFileContent(ctx, "Private/diary.md")
+	var denied *PathDeniedError
+	require.True(t, errors.As(err, &denied))
+	assert.Equal(t, "access to Private/diary.md is denied by the path rules", err.Error())
+	_, err = client.ListVaultFiles(ctx, "HR")
+	assert.ErrorAs(t, err, &denied)
+	_, err = client.CreateOrUpdateFile(ctx, "Notes/../HR/new.md", "x", "text/markdown")
+	assert.ErrorAs(t, err, &denied)
+
+	// Escapes and fragments are checked as the server receives them
+	for _, name := range []string{"Priv%61te/diary.md", "Private%2Fdiary.md", "private/diary.md", "Private/diary.md#", "x.secret.md#", "HR/a.md?x=1"} {
+		_, err = client.GetFileContent(ctx, name)
+		assert.ErrorAs(t, err, &denied, name)
+	}
+	_, err = client.GetFileContent(ctx, "Notes/100%.md")
+	assert.ErrorContains(t, err, "invalid path")
+	assert.Empty(t, requested)
+
+	// The active file is resolved to its path before it is touched
+	_, err = client.DeleteActiveFile(ctx)
+	assert.ErrorAs(t, err, &denied)
+	assert.Equal(t, []string{"GET /active/"}, requested)
+}
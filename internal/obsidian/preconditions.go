@@ -0,0 +1,224 @@
+package obsidian
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/diff"
+)
+
+// Precondition is the version of a note a change was prepared against. Zero
+// fields are not checked.
+type Precondition struct {
+	// Path is the vault file the precondition applies to. An empty path
+	// applies it to the file being changed, such as a periodic note or the
+	// active file.
+	Path string
+	// Mtime is the expected modification time in milliseconds
+	Mtime int64
+	// Hash is the expected ContentHash of the note
+	Hash string
+}
+
+// ConflictError is returned instead of making a change when the note no
+// longer matches the precondition of the change
+type ConflictError struct {
+	Path     string
+	Expected Precondition
+	// Exists, Mtime and Hash describe the note as it was found
+	Exists bool
+	Mtime  int64
+	Hash   string
+	// Diff is the unified diff from the current content of the note to the
+	// content the change would have written, when it could be computed
+	Diff string
+}
+
+func (e *ConflictError) Error() string {
+	var found string
+	switch {
+	case !e.Exists:
+		found = "it no longer exists"
+	case e.Expected.Mtime != 0 && e.Expected.Mtime != e.Mtime:
+		found = fmt.Sprintf("expected mtime %d, found %d", e.Expected.Mtime, e.Mtime)
+	default:
+		found = fmt.Sprintf("expected hash %s, found %s", e.Expected.Hash, e.Hash)
+	}
+	message := fmt.Sprintf("conflict: %s has changed since it was read (%s), nothing was written", e.Path, found)
+	if e.Diff != "" {
+		message += "\nDiff from its current content to the content that would have been written:\n" + e.Diff
+	}
+	return message
+}
+
+// ContentHash returns the hash of note content used by preconditions, the
+// hex-encoded SHA-256 digest prefixed with "sha256:"
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// preconditionContextKey is the context key under which the precondition
+// state is stored
+type preconditionContextKey struct{}
+
+// preconditionState tracks the check of a precondition, which is done once
+// before the first change made with its context
+type preconditionState struct {
+	Precondition
+	once sync.Once
+	err  error
+	// hash is the ContentHash of the note when the precondition held
+	hash string
+}
+
+// WithPrecondition returns a context whose changes are only made when the
+// note still matches the precondition. It is checked against a fresh
+// NoteJson fetch right before the first change made with the context.
+func WithPrecondition(ctx context.Context, precondition Precondition) context.Context {
+	return context.WithValue(ctx, preconditionContextKey{}, &preconditionState{Precondition: precondition})
+}
+
+// changeResult computes the content a change writes from the current
+// content of a file. keep is false when the change deletes the file.
+type changeResult func(current string) (after string, keep bool, err error)
+
+// checkPrecondition verifies the precondition of the context, if any, before
+// a change to apiPath. result is used for the diff of a conflict.
+func (c *Client) checkPrecondition(ctx context.Context, apiPath string, result changeResult) error {
+	state, ok := ctx.Value(preconditionContextKey{}).(*preconditionState)
+	if !ok {
+		return nil
+	}
+	state.once.Do(func() {
+		state.hash, state.err = c.verifyPrecondition(ctx, state.Precondition, apiPath, result)
+	})
+	return state.err
+}
+
+// verifyPrecondition compares a note with a precondition, returning its
+// current hash when they match and a ConflictError when they differ
+func (c *Client) verifyPrecondition(ctx context.Context, precondition Precondition, apiPath string, result changeResult) (string, error) {
+	target := apiPath
+	if precondition.Path != "" {
+		target = "/vault/" + strings.TrimPrefix(precondition.Path, "/")
+	}
+
+	note, err := c.getNote(ctx, target)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.NotFound() {
+		note, err = nil, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check %s for changes: %w", strings.TrimPrefix(target, "/vault/"), err)
+	}
+
+	conflict := &ConflictError{Path: strings.TrimPrefix(target, "/vault/"), Expected: precondition}
+	if note != nil {
+		conflict.Exists, conflict.Mtime, conflict.Hash = true, note.Stat.Mtime, note.Hash
+		if note.Path != "" {
+			conflict.Path = note.Path
+		}
+		mtimeMatches := precondition.Mtime == 0 || precondition.Mtime == note.Stat.Mtime
+		hashMatches := precondition.Hash == "" || normalizeHash(precondition.Hash) == normalizeHash(note.Hash)
+		if mtimeMatches && hashMatches {
+			return note.Hash, nil
+		}
+	}
+
+	if target == apiPath {
+		conflict.Diff = conflictDiff(conflict.Path, note, result)
+	}
+	return "", conflict
+}
+
+// conflictDiff returns the diff a change would make to the current content
+// of a note, or an empty string when it cannot be computed
+func conflictDiff(path string, note *Note, result changeResult) string {
+	var current string
+	if note != nil {
+		current = note.Content
+	}
+	after, keep, err := result(current)
+	if err != nil || (&File{Path: path, MimeType: "application/octet-stream", Data: []byte(after)}).IsBinary() {
+		return ""
+	}
+
+	oldName, newName := path, path
+	if note == nil {
+		oldName = "/dev/null"
+	}
+	if !keep {
+		newName = "/dev/null"
+	}
+	return diff.Unified(oldName, newName, current, after)
+}
+
+// normalizeHash strips the optional algorithm prefix of a content hash
+func normalizeHash(hash string) string {
+	return strings.ToLower(strings.TrimPrefix(hash, "sha256:"))
+}
+
+// guardRead returns a context whose changes to a note are only made while it
+// still has the modification time it was read with. A precondition already
+// carried by the context is kept instead, as it covers the same change.
+func guardRead(ctx context.Context, note *Note, filename string) context.Context {
+	if _, ok := ctx.Value(preconditionContextKey{}).(*preconditionState); ok || note.Stat.Mtime == 0 {
+		return ctx
+	}
+	return WithPrecondition(ctx, Precondition{Path: filename, Mtime: note.Stat.Mtime})
+}
+
+// FileChange holds the content hashes of a file before and after a write
+type FileChange struct {
+	Path       string
+	HashBefore string
+	HashAfter  string
+}
+
+// ChangeLog collects the files rewritten with a context. Only writes checked
+// against a precondition of the same file are recorded, as the check reads
+// the content they replace.
+type ChangeLog struct {
+	mu      sync.Mutex
+	changes []FileChange
+}
+
+// changeLogContextKey is the context key under which the change log is stored
+type changeLogContextKey struct{}
+
+// WithChangeLog returns a context whose file writes are recorded in log
+func WithChangeLog(ctx context.Context, log *ChangeLog) context.Context {
+	return context.WithValue(ctx, changeLogContextKey{}, log)
+}
+
+// Changes returns the writes recorded so far
+func (l *ChangeLog) Changes() []FileChange {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.changes)
+}
+
+// recordChange adds a write of content to a file to the change log of the
+// context, if any
+func recordChange(ctx context.Context, filename, content string) {
+	log, ok := ctx.Value(changeLogContextKey{}).(*ChangeLog)
+	if !ok {
+		return
+	}
+	state, ok := ctx.Value(preconditionContextKey{}).(*preconditionState)
+	filename = strings.TrimPrefix(filename, "/")
+	if !ok || state.err != nil || state.hash == "" || strings.TrimPrefix(state.Path, "/") != filename {
+		return
+	}
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	log.changes = append(log.changes, FileChange{Path: filename, HashBefore: state.hash, HashAfter: ContentHash(content)})
+}
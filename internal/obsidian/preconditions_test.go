@@ -0,0 +1,102 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noteServer serves notes in the NoteJson representation and records the
+// changes made to them
+func noteServer(t *testing.T, notes map[string]*Note, changes *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			body, _ := io.ReadAll(r.Body)
+			*changes = append(*changes, r.Method+" "+r.URL.Path+" "+string(body))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		note, ok := notes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(note)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestClientPreconditions tests that changes are only made while the note
+// matches the expected modification time and hash
+func TestClientPreconditions(t *testing.T) {
+	notes := map[string]*Note{
+		"/vault/a.md": {Path: "a.md", Content: "one\ntwo\n", Stat: NoteStat{Mtime: 100}},
+		"/active/":    {Path: "daily.md", Content: "today\n", Stat: NoteStat{Mtime: 300}},
+	}
+	var changes []string
+	client := NewClient("test-token", noteServer(t, notes, &changes).URL)
+	ctx := context.Background()
+
+	_, err := client.AppendToFile(WithPrecondition(ctx, Precondition{Path: "a.md", Mtime: 100}), "a.md", "three\n")
+	require.NoError(t, err)
+	_, err = client.CreateOrUpdateFile(WithPrecondition(ctx, Precondition{Path: "/a.md", Hash: ContentHash("one\ntwo\n")}), "a.md", "new\n", "text/markdown")
+	require.NoError(t, err)
+	_, err = client.AppendToActiveFile(WithPrecondition(ctx, Precondition{Mtime: 300}), "x")
+	require.NoError(t, err)
+	assert.Len(t, changes, 3)
+
+	_, err = client.AppendToFile(WithPrecondition(ctx, Precondition{Path: "a.md", Mtime: 99}), "a.md", "three\n")
+	var conflict *ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, "a.md", conflict.Path)
+	assert.Equal(t, int64(100), conflict.Mtime)
+	assert.Equal(t, "--- a.md\n+++ a.md\n@@ -1,2 +1,3 @@\n one\n two\n+three\n", conflict.Diff)
+	assert.Contains(t, err.Error(), "conflict: a.md has changed since it was read (expected mtime 99, found 100)")
+
+	_, err = client.DeleteActiveFile(WithPrecondition(ctx, Precondition{Hash: "sha256:0000"}))
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, "daily.md", conflict.Path)
+	assert.Equal(t, "--- daily.md\n+++ /dev/null\n@@ -1 +0,0 @@\n-today\n", conflict.Diff)
+
+	_, err = client.DeleteFile(WithPrecondition(ctx, Precondition{Path: "gone.md", Mtime: 100}), "gone.md")
+	require.ErrorAs(t, err, &conflict)
+	assert.False(t, conflict.Exists)
+	assert.Contains(t, err.Error(), "it no longer exists")
+
+	assert.Len(t, changes, 3)
+}
+
+// TestClientPreconditionCheckedOnce tests that the precondition of a tool
+// making several changes is checked against its note before the first one
+func TestClientPreconditionCheckedOnce(t *testing.T) {
+	notes := map[string]*Note{
+		"/vault/a.md": {Path: "a.md", Content: "a", Stat: NoteStat{Mtime: 100}},
+	}
+	var changes []string
+	client := NewClient("test-token", noteServer(t, notes, &changes).URL)
+
+	ctx := WithPrecondition(context.Background(), Precondition{Path: "a.md", Mtime: 100})
+	_, err := client.CreateOrUpdateFile(ctx, "b.md", "a", "text/markdown")
+	require.NoError(t, err)
+	notes["/vault/a.md"].Stat.Mtime = 200
+	_, err = client.DeleteFile(ctx, "a.md")
+	require.NoError(t, err)
+	assert.Len(t, changes, 2)
+
+	ctx = WithPrecondition(context.Background(), Precondition{Path: "a.md", Mtime: 100})
+	_, err = client.CreateOrUpdateFile(ctx, "b.md", "a", "text/markdown")
+	var conflict *ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Empty(t, conflict.Diff)
+	_, err = client.DeleteFile(ctx, "a.md")
+	require.ErrorAs(t, err, &conflict)
+	assert.Len(t, changes, 2)
+}
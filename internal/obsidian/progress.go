@@ -0,0 +1,23 @@
+package obsidian
+
+import "context"
+
+// ProgressFunc receives progress updates from long-running client operations.
+// Progress increases with every call; total is zero when it is not known yet.
+type ProgressFunc func(progress, total float64, message string)
+
+// progressContextKey is the context key under which the progress hook is stored
+type progressContextKey struct{}
+
+// WithProgress returns a context that reports the progress of client
+// operations to fn
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, fn)
+}
+
+// ReportProgress sends a progress update to the hook stored in ctx, if any
+func ReportProgress(ctx context.Context, progress, total float64, message string) {
+	if fn, ok := ctx.Value(progressContextKey{}).(ProgressFunc); ok {
+		fn(progress, total, message)
+	}
+}
@@ -0,0 +1,85 @@
+package obsidian
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Snapshotter stores the content of files before the client changes them.
+// exists is false when the change is about to create the file.
+type Snapshotter interface {
+	Save(path, operation, operationID string, content []byte, exists bool) error
+}
+
+// WithSnapshotter makes the client snapshot files before it updates,
+// appends to, patches or deletes them, including periodic notes and the
+// active file
+func WithSnapshotter(snapshots Snapshotter) ClientOption {
+	return func(c *Client) {
+		c.snapshots = snapshots
+	}
+}
+
+// snapshotContextKey is the context key under which the snapshot operation
+// is stored
+type snapshotContextKey struct{}
+
+// WithSnapshotOperation returns a context whose changes are snapshotted
+// under the given operation name instead of the name of the client method
+func WithSnapshotOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, snapshotContextKey{}, operation)
+}
+
+// snapshotOperationIDContextKey is the context key under which the
+// snapshot operation ID is stored
+type snapshotOperationIDContextKey struct{}
+
+// WithSnapshotOperationID returns a context whose changes are snapshotted
+// under the given operation ID, so that they can be undone together
+func WithSnapshotOperationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, snapshotOperationIDContextKey{}, id)
+}
+
+// snapshot saves the current content of a file before an operation changes
+// it. The change must not go ahead when the snapshot fails.
+func (c *Client) snapshot(ctx context.Context, filename, operation string) error {
+	if c.snapshots == nil {
+		return nil
+	}
+	if op, ok := ctx.Value(snapshotContextKey{}).(string); ok {
+		operation = op
+	}
+	operationID, _ := ctx.Value(snapshotOperationIDContextKey{}).(string)
+	filename = strings.TrimPrefix(filename, "/")
+
+	file, err := c.GetFile(ctx, filename)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.NotFound() {
+		err = c.snapshots.Save(filename, operation, operationID, nil, false)
+	} else if err == nil {
+		err = c.snapshots.Save(filename, operation, operationID, file.Data, true)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to snapshot %s: %w", filename, err)
+	}
+	return nil
+}
+
+// snapshotResolved snapshots the note at an API path such as /active/ before
+// an operation changes it. Notes that do not exist yet have nothing to lose
+// and are not snapshotted.
+func (c *Client) snapshotResolved(ctx context.Context, apiPath, operation string) error {
+	if c.snapshots == nil {
+		return nil
+	}
+	filename, err := c.resolvePath(ctx, apiPath)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot %s: %w", apiPath, err)
+	}
+	if filename == "" {
+		return nil
+	}
+	return c.snapshot(ctx, filename, operation)
+}
@@ -0,0 +1,111 @@
+package obsidian
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSnapshotter records the snapshots it is asked to save
+type recordingSnapshotter struct {
+	saved []string
+}
+
+func (r *recordingSnapshotter) Save(path, operation, operationID string, content []byte, exists bool) error {
+	if operationID != "" {
+		operation += "@" + operationID
+	}
+	if !exists {
+		content = []byte("<missing>")
+	}
+	r.saved = append(r.saved, operation+" "+path+" "+string(content))
+	return nil
+}
+
+// TestClientSnapshots tests that files are snapshotted before every change
+func TestClientSnapshots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && r.URL.Path == "/vault/new.md" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "GET" {
+			_, _ = w.Write([]byte("before"))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	snapshots := &recordingSnapshotter{}
+	client := NewClient("test-token", server.URL, WithSnapshotter(snapshots))
+	ctx := context.Background()
+
+	_, err := client.CreateOrUpdateFile(ctx, "/a.md", "x", "text/markdown")
+	require.NoError(t, err)
+	_, err = client.AppendToFile(ctx, "new.md", "x")
+	require.NoError(t, err)
+	_, err = client.PatchFileContent(ctx, "a.md", "append", "heading", "H", "x", "text/markdown", "::")
+	require.NoError(t, err)
+	_, err = client.DeleteFile(WithSnapshotOperation(ctx, "restore"), "a.md")
+	require.NoError(t, err)
+	_, err = client.DeleteFile(WithSnapshotOperationID(ctx, "op1"), "a.md")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"update a.md before",
+		"append new.md <missing>",
+		"patch a.md before",
+		"restore a.md before",
+		"delete@op1 a.md before",
+	}, snapshots.saved)
+}
+
+// TestClientSnapshotsPeriodicAndActive tests that periodic notes and the
+// active file are snapshotted under their vault path
+func TestClientSnapshotsPeriodicAndActive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method != "GET":
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/active/":
+			_, _ = w.Write([]byte(`{"path": "Inbox.md", "content": "inbox"}`))
+		case r.URL.Path == "/periodic/daily/":
+			_, _ = w.Write([]byte(`{"path": "Daily/2024-01-01.md", "content": "daily"}`))
+		case r.URL.Path == "/vault/Inbox.md":
+			_, _ = w.Write([]byte("inbox"))
+		case r.URL.Path == "/vault/Daily/2024-01-01.md":
+			_, _ = w.Write([]byte("daily"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	snapshots := &recordingSnapshotter{}
+	client := NewClient("test-token", server.URL, WithSnapshotter(snapshots))
+	ctx := context.Background()
+
+	_, err := client.UpdateActiveFile(ctx, "x", "text/markdown")
+	require.NoError(t, err)
+	_, err = client.DeleteActiveFile(ctx)
+	require.NoError(t, err)
+	_, err = client.PatchPeriodicNote(ctx, "daily", "", "append", "heading", "H", "x", "text/markdown", "::")
+	require.NoError(t, err)
+	_, err = client.DeletePeriodicNote(ctx, "daily", "")
+	require.NoError(t, err)
+	// Periodic notes that do not exist yet have nothing to snapshot
+	_, err = client.AppendToPeriodicNote(ctx, "weekly", "", "x")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"update Inbox.md inbox",
+		"delete Inbox.md inbox",
+		"patch Daily/2024-01-01.md daily",
+		"delete Daily/2024-01-01.md daily",
+	}, snapshots.saved)
+}
@@ -0,0 +1,108 @@
+package obsidian
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/obsidian-mcp-server/obsidian-mcp-server/internal/markdown"
+)
+
+// CompleteTask marks a task of a note as done, or open again when done is
+// false. The task is found by its 1-based line, its block ID or both, and only
+// its checkbox is changed: tasks with a block ID are patched in place, others
+// are written back. Either way the change is only made if the note has not
+// changed since it was read.
+func (c *Client) CompleteTask(ctx context.Context, filename string, line int, blockID string, done bool) (string, error) {
+	blockID = strings.TrimPrefix(blockID, "^")
+	if line <= 0 && blockID == "" {
+		return "", fmt.Errorf("line or blockId is required")
+	}
+
+	note, err := c.GetNote(ctx, filename)
+	if err != nil {
+		return "", err
+	}
+	content := note.Content
+
+	var task *markdown.Task
+	for _, t := range markdown.ParseTasks(content) {
+		if (line <= 0 || t.Line == line) && (blockID == "" || t.BlockID == blockID) {
+			task = &t
+			break
+		}
+	}
+	if task == nil {
+		if blockID != "" {
+			return "", fmt.Errorf("no task with block ID ^%s in %s", blockID, filename)
+		}
+		return "", fmt.Errorf("no task on line %d of %s", line, filename)
+	}
+
+	symbol, status := " ", markdown.TaskOpen
+	if done {
+		symbol, status = "x", markdown.TaskDone
+	}
+	if task.Status == status {
+		return fmt.Sprintf("Task on line %d of %s is already %s", task.Line, filename, status), nil
+	}
+
+	updated, err := markdown.SetTaskStatus(content, task.Line, symbol)
+	if err != nil {
+		return "", err
+	}
+	ctx = guardRead(ctx, note, filename)
+	if task.BlockID != "" {
+		line := markdown.TrimBlockID(markdown.Lines(updated, task.Line, task.Line))
+		_, err = c.PatchFileContent(ctx, filename, "replace", "block", task.BlockID, line, "text/markdown", "")
+	} else {
+		_, err = c.CreateOrUpdateFile(ctx, filename, updated, "text/markdown")
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Successfully marked task on line %d of %s as %s", task.Line, filename, status), nil
+}
+
+// AddTask adds an open task to a note. It is appended below a heading or
+// after a block when one is given, otherwise at the end of the note, which is
+// created if needed. Due dates use the Tasks plugin emoji format.
+func (c *Client) AddTask(ctx context.Context, filename, text, due, heading, blockID string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("text cannot be empty")
+	}
+	if due != "" {
+		if _, err := time.Parse(time.DateOnly, due); err != nil {
+			return "", fmt.Errorf("invalid due date %q, expected YYYY-MM-DD", due)
+		}
+	}
+	task := markdown.FormatTask(text, due) + "\n"
+
+	switch {
+	case heading != "":
+		if _, err := c.PatchFileContent(ctx, filename, "append", "heading", heading, task, "text/markdown", "::"); err != nil {
+			return "", err
+		}
+	case blockID != "":
+		if _, err := c.PatchFileContent(ctx, filename, "append", "block", strings.TrimPrefix(blockID, "^"), task, "text/markdown", "::"); err != nil {
+			return "", err
+		}
+	default:
+		// Keep the task on its own line when the note lacks a final newline
+		content, err := c.GetFileContent(ctx, filename)
+		var apiErr *APIError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.NotFound()) {
+			return "", err
+		}
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			task = "\n" + task
+		}
+		if _, err := c.AppendToFile(ctx, filename, task); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("Successfully added task to %s", filename), nil
+}
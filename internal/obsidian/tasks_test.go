@@ -0,0 +1,109 @@
+package obsidian
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompleteTask tests toggling a task by line and by block ID
+func TestCompleteTask(t *testing.T) {
+	vault, client := newFakeVault(t, map[string]string{
+		"Todo.md": "# Today\n- [ ] First\n- [ ] Second ^second\n",
+	})
+
+	message, err := client.CompleteTask(context.Background(), "Todo.md", 2, "", true)
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully marked task on line 2 of Todo.md as done", message)
+
+	message, err = client.CompleteTask(context.Background(), "Todo.md", 0, "^second", true)
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully marked task on line 3 of Todo.md as done", message)
+	assert.Equal(t, "# Today\n- [x] First\n- [x] Second ^second\n", vault.files["Todo.md"])
+
+	message, err = client.CompleteTask(context.Background(), "Todo.md", 2, "", true)
+	require.NoError(t, err)
+	assert.Equal(t, "Task on line 2 of Todo.md is already done", message)
+
+	_, err = client.CompleteTask(context.Background(), "Todo.md", 2, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, "# Today\n- [ ] First\n- [x] Second ^second\n", vault.files["Todo.md"])
+
+	_, err = client.CompleteTask(context.Background(), "Todo.md", 1, "", true)
+	assert.EqualError(t, err, "no task on line 1 of Todo.md")
+	_, err = client.CompleteTask(context.Background(), "Todo.md", 2, "second", true)
+	assert.EqualError(t, err, "no task with block ID ^second in Todo.md")
+	_, err = client.CompleteTask(context.Background(), "Todo.md", 0, "", true)
+	assert.EqualError(t, err, "line or blockId is required")
+}
+
+// TestCompleteTaskConcurrentEdit tests that a task is not written over a
+// change made to the note after it was read, with or without a block ID
+func TestCompleteTaskConcurrentEdit(t *testing.T) {
+	vault, client := newFakeVault(t, map[string]string{
+		"Todo.md": "- [ ] First\n- [ ] Second ^second\n",
+	})
+	edit := func(name string) {
+		vault.edited = nil
+		vault.write(name, vault.files[name]+"- [ ] Third\n")
+	}
+
+	vault.edited = edit
+	_, err := client.CompleteTask(context.Background(), "Todo.md", 1, "", true)
+	var conflict *ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, "- [ ] First\n- [ ] Second ^second\n- [ ] Third\n", vault.files["Todo.md"])
+
+	vault.edited = edit
+	_, err = client.CompleteTask(context.Background(), "Todo.md", 0, "second", true)
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, "- [ ] First\n- [ ] Second ^second\n- [ ] Third\n- [ ] Third\n", vault.files["Todo.md"])
+
+	_, err = client.CompleteTask(context.Background(), "Todo.md", 0, "second", true)
+	require.NoError(t, err)
+	assert.Equal(t, "- [ ] First\n- [x] Second ^second\n- [ ] Third\n- [ ] Third\n", vault.files["Todo.md"])
+}
+
+// TestAddTask tests appending a task to the end of a note
+func TestAddTask(t *testing.T) {
+	vault, client := newFakeVault(t, map[string]string{
+		"Todo.md": "# Today\n- [ ] First",
+	})
+
+	_, err := client.AddTask(context.Background(), "Todo.md", "Second", "2024-05-01", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "# Today\n- [ ] First\n- [ ] Second 📅 2024-05-01\n", vault.files["Todo.md"])
+
+	_, err = client.AddTask(context.Background(), "New.md", "Created", "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "- [ ] Created\n", vault.files["New.md"])
+
+	_, err = client.AddTask(context.Background(), "Todo.md", "Late", "May 1st", "", "")
+	assert.EqualError(t, err, `invalid due date "May 1st", expected YYYY-MM-DD`)
+}
+
+// TestAddTaskUnderHeading tests adding a task below a heading with a PATCH
+func TestAddTaskUnderHeading(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/vault/Todo.md", r.URL.Path)
+		assert.Equal(t, "append", r.Header.Get("Operation"))
+		assert.Equal(t, "heading", r.Header.Get("Target-Type"))
+		assert.Equal(t, "Today%3A%3AWork", r.Header.Get("Target"))
+		assert.Equal(t, "::", r.Header.Get("Target-Delimiter"))
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "- [ ] Review PR\n", string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", server.URL)
+	message, err := client.AddTask(context.Background(), "Todo.md", "Review PR", "", "Today::Work", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully added task to Todo.md", message)
+}
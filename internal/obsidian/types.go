@@ -0,0 +1,119 @@
+package obsidian
+
+// Note is a vault note in the NoteJson representation. It mirrors
+// obsidian.NoteJson but keeps the stat timestamps as integer milliseconds,
+// which the generated float32 fields cannot represent exactly.
+type Note struct {
+	Content     string         `json:"content"`
+	Frontmatter map[string]any `json:"frontmatter"`
+	Path        string         `json:"path"`
+	Stat        NoteStat       `json:"stat"`
+	Tags        []string       `json:"tags"`
+	// Hash is the ContentHash of Content, computed by the client
+	Hash string `json:"hash,omitempty"`
+}
+
+// NoteStat holds the file metadata of a note
+type NoteStat struct {
+	Ctime int64 `json:"ctime"`
+	Mtime int64 `json:"mtime"`
+	Size  int64 `json:"size"`
+}
+
+// ServerStatus holds basic details about the Local REST API server
+type ServerStatus struct {
+	Status        string         `json:"status"`
+	Service       string         `json:"service"`
+	Authenticated bool           `json:"authenticated"`
+	Versions      ServerVersions `json:"versions"`
+}
+
+// ServerVersions holds the versions of Obsidian and the Local REST API plugin
+type ServerVersions struct {
+	Obsidian string `json:"obsidian"`
+	Self     string `json:"self"`
+}
+
+// FileList lists vault files. Directory listings mark sub-directories with a
+// trailing slash.
+type FileList struct {
+	Files []string `json:"files"`
+}
+
+// FileResult is the outcome of reading one file of a multi-file read. Exactly
+// one of Content, Note and Error is set.
+type FileResult struct {
+	Filename string `json:"filename"`
+	Content  string `json:"content,omitempty"`
+	Note     *Note  `json:"note,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// SearchResult is a note matched by a simple search
+type SearchResult struct {
+	Filename string        `json:"filename"`
+	Score    float64       `json:"score"`
+	Matches  []SearchMatch `json:"matches"`
+}
+
+// SearchMatch is a single match of a simple search within a note
+type SearchMatch struct {
+	Match   SearchSpan `json:"match"`
+	Context string     `json:"context"`
+}
+
+// SearchSpan locates a match within its context
+type SearchSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// AdvancedSearchResult is a note matched by a Dataview or JsonLogic query
+// together with the query's result for it
+type AdvancedSearchResult struct {
+	Filename string `json:"filename"`
+	Result   any    `json:"result"`
+}
+
+// Command is an Obsidian command that can be executed
+type Command struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// CommandList lists the available Obsidian commands
+type CommandList struct {
+	Commands []Command `json:"commands"`
+}
+
+// MoveResult describes a moved file and the links rewritten because of it
+type MoveResult struct {
+	Source       string       `json:"source"`
+	Destination  string       `json:"destination"`
+	DryRun       bool         `json:"dryRun"`
+	UpdatedFiles []LinkUpdate `json:"updatedFiles"`
+}
+
+// LinkUpdate lists the links rewritten in one file
+type LinkUpdate struct {
+	Path    string       `json:"path"`
+	Changes []LinkChange `json:"changes"`
+}
+
+// LinkChange is a single rewritten link
+type LinkChange struct {
+	Line int    `json:"line"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// FrontmatterUpdate describes a frontmatter property set on the notes matched
+// by a query
+type FrontmatterUpdate struct {
+	Field          string   `json:"field"`
+	DryRun         bool     `json:"dryRun"`
+	UpdatedFiles   []string `json:"updatedFiles"`
+	UnchangedFiles []string `json:"unchangedFiles"`
+	// Errors maps the notes that could not be updated to the reason
+	Errors map[string]string `json:"errors,omitempty"`
+}
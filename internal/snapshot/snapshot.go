@@ -0,0 +1,267 @@
+// Package snapshot keeps copies of vault files taken before they are changed
+// so that edits can be reviewed and rolled back
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxPerFile is the number of snapshots kept for each file
+	DefaultMaxPerFile = 20
+	// DefaultMaxAge is how long snapshots are kept
+	DefaultMaxAge = 30 * 24 * time.Hour
+)
+
+// OperationRestore marks snapshots taken while restoring another snapshot.
+// They are never undone by Latest.
+const OperationRestore = "restore"
+
+// ErrNotFound is returned for unknown snapshot IDs
+var ErrNotFound = errors.New("snapshot not found")
+
+// Snapshot describes the content of a file before a change
+type Snapshot struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	// Operation is the change the snapshot was taken for, such as "update",
+	// "append", "patch", "delete" or "restore"
+	Operation string `json:"operation"`
+	// OperationID groups the snapshots taken for one tool call, which are
+	// undone together
+	OperationID string    `json:"operationId,omitempty"`
+	Time        time.Time `json:"time"`
+	// Exists is false when the change created the file
+	Exists bool `json:"exists"`
+	Size   int  `json:"size"`
+	// Undone is set once the change has been rolled back with the snapshot
+	Undone bool `json:"undone,omitempty"`
+}
+
+// Retention limits the snapshots kept on disk. Zero values disable a limit.
+type Retention struct {
+	// MaxPerFile is the number of snapshots kept for each file
+	MaxPerFile int
+	// MaxAge is how long snapshots are kept
+	MaxAge time.Duration
+}
+
+// Store keeps snapshots in a folder, each as a metadata file and a content
+// file named after its ID. It is safe for concurrent use.
+type Store struct {
+	dir       string
+	retention Retention
+
+	mu sync.Mutex
+	// seq disambiguates snapshots taken in the same instant
+	seq int
+}
+
+// Open opens a snapshot store, creating its folder if needed
+func Open(dir string, retention Retention) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot folder: %w", err)
+	}
+	return &Store{dir: dir, retention: retention}, nil
+}
+
+// Save stores the content of a file before an operation changes it. Old
+// snapshots are pruned according to the retention limits.
+func (s *Store) Save(path, operation, operationID string, content []byte, exists bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	s.seq++
+	snap := Snapshot{
+		ID:          fmt.Sprintf("%s-%04d", now.Format("20060102T150405.000000000Z"), s.seq%10000),
+		Path:        strings.TrimPrefix(path, "/"),
+		Operation:   operation,
+		OperationID: operationID,
+		Time:        now,
+		Exists:      exists,
+		Size:        len(content),
+	}
+
+	if err := os.WriteFile(s.dataFile(snap.ID), content, 0o600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := s.writeMeta(snap); err != nil {
+		return err
+	}
+	return s.prune(now)
+}
+
+// List returns the snapshots of a file, or of every file when path is
+// empty, newest first
+func (s *Store) List(path string) ([]Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list(strings.TrimPrefix(path, "/"))
+}
+
+// Get returns a snapshot and the content it holds
+func (s *Store) Get(id string) (*Snapshot, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap, err := s.readMeta(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := os.ReadFile(s.dataFile(id))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read snapshot %s: %w", id, err)
+	}
+	return snap, content, nil
+}
+
+// Latest returns the snapshots of the newest change that can still be
+// undone, for one file or for any file when path is empty. A change spans
+// every snapshot sharing its operation ID, including those of other files,
+// newest first.
+func (s *Store) Latest(path string) ([]Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snaps, err := s.list(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(snaps, func(snap Snapshot) bool {
+		return !snap.Undone && snap.Operation != OperationRestore
+	})
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	latest := snaps[i]
+	if latest.OperationID == "" {
+		return []Snapshot{latest}, nil
+	}
+
+	if path != "" {
+		if snaps, err = s.list(""); err != nil {
+			return nil, err
+		}
+	}
+	var change []Snapshot
+	for _, snap := range snaps {
+		if snap.OperationID == latest.OperationID && !snap.Undone && snap.Operation != OperationRestore {
+			change = append(change, snap)
+		}
+	}
+	return change, nil
+}
+
+// MarkUndone records that the change a snapshot was taken for has been
+// rolled back
+func (s *Store) MarkUndone(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap, err := s.readMeta(id)
+	if err != nil {
+		return err
+	}
+	snap.Undone = true
+	return s.writeMeta(*snap)
+}
+
+// list reads every snapshot of a file, newest first
+func (s *Store) list(path string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot folder: %w", err)
+	}
+
+	snaps := []Snapshot{}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		snap, err := s.readMeta(id)
+		if err != nil {
+			return nil, err
+		}
+		if path == "" || snap.Path == path {
+			snaps = append(snaps, *snap)
+		}
+	}
+	slices.SortFunc(snaps, func(a, b Snapshot) int { return strings.Compare(b.ID, a.ID) })
+	return snaps, nil
+}
+
+// prune deletes snapshots beyond the retention limits
+func (s *Store) prune(now time.Time) error {
+	snaps, err := s.list("")
+	if err != nil {
+		return err
+	}
+
+	kept := map[string]int{}
+	for _, snap := range snaps {
+		kept[snap.Path]++
+		tooMany := s.retention.MaxPerFile > 0 && kept[snap.Path] > s.retention.MaxPerFile
+		tooOld := s.retention.MaxAge > 0 && now.Sub(snap.Time) > s.retention.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		for _, file := range []string{s.metaFile(snap.ID), s.dataFile(snap.ID)} {
+			if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to prune snapshot %s: %w", snap.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// readMeta reads the metadata of a snapshot
+func (s *Store) readMeta(id string) (*Snapshot, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	data, err := os.ReadFile(s.metaFile(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", id, err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", id, err)
+	}
+	return &snap, nil
+}
+
+// writeMeta writes the metadata of a snapshot
+func (s *Store) writeMeta(snap Snapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := os.WriteFile(s.metaFile(snap.ID), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// metaFile is the file holding the metadata of a snapshot
+func (s *Store) metaFile(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// dataFile is the file holding the content of a snapshot
+func (s *Store) dataFile(id string) string {
+	return filepath.Join(s.dir, id+".data")
+}
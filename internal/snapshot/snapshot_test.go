@@ -0,0 +1,105 @@
+package snapshot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStore tests saving, listing and reading snapshots
+func TestStore(t *testing.T) {
+	store, err := Open(t.TempDir(), Retention{})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Save("/a.md", "update", "", []byte("one"), true))
+	require.NoError(t, store.Save("b.md", "append", "", nil, false))
+	require.NoError(t, store.Save("a.md", OperationRestore, "", []byte("two"), true))
+
+	all, err := store.List("")
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, []string{"a.md", "b.md", "a.md"}, []string{all[0].Path, all[1].Path, all[2].Path})
+
+	snaps, err := store.List("a.md")
+	require.NoError(t, err)
+	require.Len(t, snaps, 2)
+	snap, content, err := store.Get(snaps[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "one", string(content))
+	assert.Equal(t, "update", snap.Operation)
+	assert.True(t, snap.Exists)
+	assert.Equal(t, 3, snap.Size)
+
+	// Restores are skipped and undone changes are not undone twice
+	latest, err := store.Latest("a.md")
+	require.NoError(t, err)
+	require.Len(t, latest, 1)
+	assert.Equal(t, snaps[1].ID, latest[0].ID)
+	require.NoError(t, store.MarkUndone(latest[0].ID))
+	_, err = store.Latest("a.md")
+	assert.ErrorIs(t, err, ErrNotFound)
+	latest, err = store.Latest("")
+	require.NoError(t, err)
+	require.Len(t, latest, 1)
+	assert.Equal(t, "b.md", latest[0].Path)
+
+	_, _, err = store.Get("../../etc/passwd")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+// TestStoreLatestOperation tests that the snapshots of one operation are
+// returned together
+func TestStoreLatestOperation(t *testing.T) {
+	store, err := Open(t.TempDir(), Retention{})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Save("c.md", "update", "op1", []byte("c"), true))
+	require.NoError(t, store.Save("a.md", "delete", "op2", []byte("a"), true))
+	require.NoError(t, store.Save("b.md", "update", "op2", nil, false))
+	require.NoError(t, store.Save("a.md", OperationRestore, "op2", []byte("a"), true))
+
+	// Asking for one file still returns every file of its operation
+	latest, err := store.Latest("a.md")
+	require.NoError(t, err)
+	require.Len(t, latest, 2)
+	assert.Equal(t, []string{"b.md", "a.md"}, []string{latest[0].Path, latest[1].Path})
+
+	for _, snap := range latest {
+		require.NoError(t, store.MarkUndone(snap.ID))
+	}
+	latest, err = store.Latest("")
+	require.NoError(t, err)
+	require.Len(t, latest, 1)
+	assert.Equal(t, "c.md", latest[0].Path)
+}
+
+// TestStoreRetention tests pruning by count and by age
+func TestStoreRetention(t *testing.T) {
+	store, err := Open(t.TempDir(), Retention{MaxPerFile: 2})
+	require.NoError(t, err)
+	for _, content := range []string{"1", "2", "3"} {
+		require.NoError(t, store.Save("a.md", "update", "", []byte(content), true))
+	}
+	require.NoError(t, store.Save("b.md", "update", "", []byte("b"), true))
+
+	snaps, err := store.List("a.md")
+	require.NoError(t, err)
+	require.Len(t, snaps, 2)
+	_, content, err := store.Get(snaps[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(content))
+
+	store, err = Open(t.TempDir(), Retention{MaxAge: time.Millisecond})
+	require.NoError(t, err)
+	require.NoError(t, store.Save("a.md", "update", "", []byte("old"), true))
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, store.Save("b.md", "update", "", []byte("new"), true))
+
+	snaps, err = store.List("")
+	require.NoError(t, err)
+	require.Len(t, snaps, 1)
+	assert.Equal(t, "b.md", snaps[0].Path)
+}
@@ -0,0 +1,171 @@
+// Package tags counts, finds and renames the tags of an Obsidian vault
+package tags
+
+import (
+	"slices"
+	"strings"
+)
+
+// Node is a tag in the nested tag hierarchy, such as alpha in project/alpha
+type Node struct {
+	// Tag is the full tag without its leading #
+	Tag string `json:"tag"`
+	// Name is the last segment of the tag
+	Name string `json:"name"`
+	// Count is the number of notes tagged with exactly this tag
+	Count int `json:"count"`
+	// Total is the number of notes tagged with this tag or a nested one
+	Total    int     `json:"total"`
+	Children []*Node `json:"children,omitempty"`
+}
+
+// RenameResult describes the tags renamed across the vault
+type RenameResult struct {
+	Tags         []string     `json:"tags"`
+	Target       string       `json:"target"`
+	DryRun       bool         `json:"dryRun"`
+	UpdatedFiles []FileChange `json:"updatedFiles"`
+	// Errors maps the notes that could not be updated, such as those changed
+	// since the index read them, to the reason
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// FileChange lists the lines rewritten in one file
+type FileChange struct {
+	Path    string       `json:"path"`
+	Changes []LineChange `json:"changes"`
+}
+
+// LineChange is a single rewritten line
+type LineChange struct {
+	Line int    `json:"line"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// Normalize returns a tag without surrounding whitespace or its leading #
+func Normalize(tag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(tag), "#")
+}
+
+// Matches reports whether tag is query or, with nested set, one of the tags
+// nested below it. Tags are compared case-insensitively like in Obsidian.
+func Matches(tag, query string, nested bool) bool {
+	tag, query = Normalize(tag), Normalize(query)
+	if strings.EqualFold(tag, query) {
+		return true
+	}
+	return nested && len(tag) > len(query) && tag[len(query)] == '/' && strings.EqualFold(tag[:len(query)], query)
+}
+
+// Tree builds the tag hierarchy from the tags of each note, keyed by path.
+// Nodes are sorted by name and a note counts once per tag however often it
+// uses it.
+func Tree(noteTags map[string][]string) []*Node {
+	nodes := make(map[string]*Node)
+	var roots []*Node
+
+	node := func(tag string) *Node {
+		key := strings.ToLower(tag)
+		if n, ok := nodes[key]; ok {
+			return n
+		}
+		slash := strings.LastIndex(tag, "/")
+		n := &Node{Tag: tag, Name: tag[slash+1:]}
+		nodes[key] = n
+		if slash >= 0 {
+			parent := nodes[strings.ToLower(tag[:slash])]
+			parent.Children = append(parent.Children, n)
+		} else {
+			roots = append(roots, n)
+		}
+		return n
+	}
+
+	files := make([]string, 0, len(noteTags))
+	for file := range noteTags {
+		files = append(files, file)
+	}
+	slices.Sort(files)
+
+	for _, file := range files {
+		counted := make(map[*Node]bool)
+		totaled := make(map[*Node]bool)
+		for _, tag := range noteTags[file] {
+			tag = strings.Trim(Normalize(tag), "/")
+			if tag == "" {
+				continue
+			}
+
+			// Parents are created before their children
+			var n *Node
+			segments := strings.Split(tag, "/")
+			for i := range segments {
+				n = node(strings.Join(segments[:i+1], "/"))
+				if !totaled[n] {
+					totaled[n] = true
+					n.Total++
+				}
+			}
+			if !counted[n] {
+				counted[n] = true
+				n.Count++
+			}
+		}
+	}
+
+	sortNodes(roots)
+	return roots
+}
+
+// sortNodes sorts a level of the hierarchy and everything below it by name
+func sortNodes(nodes []*Node) {
+	slices.SortFunc(nodes, func(a, b *Node) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	for _, n := range nodes {
+		sortNodes(n.Children)
+	}
+}
+
+// Notes returns the sorted paths of the notes tagged with tag or, with nested
+// set, a tag nested below it
+func Notes(noteTags map[string][]string, tag string, nested bool) []string {
+	notes := []string{}
+	for file, fileTags := range noteTags {
+		if slices.ContainsFunc(fileTags, func(t string) bool { return Matches(t, tag, nested) }) {
+			notes = append(notes, file)
+		}
+	}
+	slices.Sort(notes)
+	return notes
+}
+
+// Renamer returns a replace function for markdown.ReplaceTags that renames
+// each source tag, and the tags nested below it, to target
+func Renamer(sources []string, target string) func(name string) (string, bool) {
+	target = Normalize(target)
+	return func(name string) (string, bool) {
+		for _, source := range sources {
+			source = Normalize(source)
+			if Matches(name, source, true) {
+				return target + name[len(source):], true
+			}
+		}
+		return "", false
+	}
+}
+
+// LineChanges lists the lines that differ between two versions of a note
+// with the same number of lines
+func LineChanges(before, after string) []LineChange {
+	var changes []LineChange
+	oldLines := strings.Split(before, "\n")
+	newLines := strings.Split(after, "\n")
+	for i := range min(len(oldLines), len(newLines)) {
+		if oldLines[i] != newLines[i] {
+			changes = append(changes, LineChange{Line: i + 1, Old: oldLines[i], New: newLines[i]})
+		}
+	}
+	return changes
+}
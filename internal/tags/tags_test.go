@@ -0,0 +1,68 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTree tests counting tags into the nested hierarchy
+func TestTree(t *testing.T) {
+	tree := Tree(map[string][]string{
+		"A.md": {"#project/alpha", "#todo", "#todo"},
+		"B.md": {"#project/beta", "#Project/alpha"},
+		"C.md": {"#project"},
+	})
+
+	require.Len(t, tree, 2)
+	project := tree[0]
+	assert.Equal(t, "project", project.Tag)
+	assert.Equal(t, 1, project.Count)
+	assert.Equal(t, 3, project.Total)
+
+	require.Len(t, project.Children, 2)
+	assert.Equal(t, &Node{Tag: "project/alpha", Name: "alpha", Count: 2, Total: 2}, project.Children[0])
+	assert.Equal(t, "beta", project.Children[1].Name)
+
+	assert.Equal(t, &Node{Tag: "todo", Name: "todo", Count: 1, Total: 1}, tree[1])
+}
+
+// TestNotes tests finding the notes with a tag
+func TestNotes(t *testing.T) {
+	noteTags := map[string][]string{
+		"A.md": {"#project/alpha"},
+		"B.md": {"#Project"},
+		"C.md": {"#projects"},
+	}
+
+	assert.Equal(t, []string{"A.md", "B.md"}, Notes(noteTags, "#project", true))
+	assert.Equal(t, []string{"B.md"}, Notes(noteTags, "project", false))
+	assert.Equal(t, []string{}, Notes(noteTags, "missing", true))
+}
+
+// TestRenamer tests mapping tags to their new names
+func TestRenamer(t *testing.T) {
+	rename := Renamer([]string{"#old", "legacy"}, "#new")
+
+	for name, expected := range map[string]string{
+		"old":          "new",
+		"Old/child":    "new/child",
+		"legacy/a/b":   "new/a/b",
+		"older":        "",
+		"other/legacy": "",
+	} {
+		renamed, ok := rename(name)
+		assert.Equal(t, expected != "", ok, name)
+		assert.Equal(t, expected, renamed, name)
+	}
+}
+
+// TestLineChanges tests summarizing the lines changed in a note
+func TestLineChanges(t *testing.T) {
+	changes := LineChanges("a\n#old\nc\n#old", "a\n#new\nc\n#new")
+	assert.Equal(t, []LineChange{
+		{Line: 2, Old: "#old", New: "#new"},
+		{Line: 4, Old: "#old", New: "#new"},
+	}, changes)
+}
@@ -527,10 +527,17 @@ func TestE2ESearchVault(t *testing.T) {
 	contentItem := content[0].(map[string]interface{})
 
 	text := contentItem["text"].(string)
-	// Verify it's valid JSON (even if empty array)
-	var searchResults []interface{}
+	// Verify it's valid JSON (even if there are no results)
+	var searchResults struct {
+		Results []interface{} `json:"results"`
+	}
 	err := json.Unmarshal([]byte(text), &searchResults)
 	require.NoError(t, err, "Search results should be valid JSON")
+	assert.NotNil(t, searchResults.Results, "Search results should be a list")
+
+	structured, ok := result["structuredContent"].(map[string]interface{})
+	require.True(t, ok, "Result should carry structured content")
+	assert.Contains(t, structured, "results")
 }
 
 // TestE2EListCommands tests listing Obsidian commands